@@ -0,0 +1,35 @@
+package v1alpha1
+
+// ReferencesCA returns true when the reference points to the FabricCA, the reference defaults to the namespace of
+// the resource holding it
+func (r *CARef) ReferencesCA(resourceNamespace string, caNamespace string, caName string) bool {
+	if r == nil || r.Name != caName {
+		return false
+	}
+	ns := r.Namespace
+	if ns == "" {
+		ns = resourceNamespace
+	}
+	return ns == caNamespace
+}
+
+func (s *Secret) referencesCA(resourceNamespace string, caNamespace string, caName string) bool {
+	return s.Enrollment.Component.CARef.ReferencesCA(resourceNamespace, caNamespace, caName) ||
+		s.Enrollment.TLS.CARef.ReferencesCA(resourceNamespace, caNamespace, caName)
+}
+
+// ReferencesCA returns true when the peer enrolls against the FabricCA through caRef
+func (in *FabricPeer) ReferencesCA(caNamespace string, caName string) bool {
+	return in.Spec.Secret.referencesCA(in.Namespace, caNamespace, caName)
+}
+
+// ReferencesCA returns true when the orderer node enrolls against the FabricCA through caRef
+func (in *FabricOrdererNode) ReferencesCA(caNamespace string, caName string) bool {
+	return in.Spec.Secret != nil && in.Spec.Secret.referencesCA(in.Namespace, caNamespace, caName)
+}
+
+// ReferencesCA returns true when the ordering service enrolls against the FabricCA through caRef
+func (in *FabricOrderingService) ReferencesCA(caNamespace string, caName string) bool {
+	return in.Spec.Enrollment.Component.CARef.ReferencesCA(in.Namespace, caNamespace, caName) ||
+		in.Spec.Enrollment.TLS.CARef.ReferencesCA(in.Namespace, caNamespace, caName)
+}
@@ -0,0 +1,40 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReferencesCA(t *testing.T) {
+	g := NewWithT(t)
+	peer := &FabricPeer{
+		ObjectMeta: metav1.ObjectMeta{Name: "org1-peer0", Namespace: "org1"},
+	}
+	g.Expect(peer.ReferencesCA("org1", "org1-ca")).To(BeFalse())
+
+	peer.Spec.Secret.Enrollment.TLS.CARef = &CARef{Name: "org1-ca"}
+	g.Expect(peer.ReferencesCA("org1", "org1-ca")).To(BeTrue())
+	g.Expect(peer.ReferencesCA("default", "org1-ca")).To(BeFalse())
+
+	peer.Spec.Secret.Enrollment.TLS.CARef = nil
+	peer.Spec.Secret.Enrollment.Component.CARef = &CARef{Name: "org1-ca", Namespace: "cas"}
+	g.Expect(peer.ReferencesCA("cas", "org1-ca")).To(BeTrue())
+	g.Expect(peer.ReferencesCA("org1", "org1-ca")).To(BeFalse())
+	g.Expect(peer.ReferencesCA("cas", "org2-ca")).To(BeFalse())
+
+	ordererNode := &FabricOrdererNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "ord-node1", Namespace: "orderers"},
+	}
+	g.Expect(ordererNode.ReferencesCA("orderers", "ord-ca")).To(BeFalse())
+	ordererNode.Spec.Secret = &Secret{}
+	ordererNode.Spec.Secret.Enrollment.Component.CARef = &CARef{Name: "ord-ca"}
+	g.Expect(ordererNode.ReferencesCA("orderers", "ord-ca")).To(BeTrue())
+
+	ordService := &FabricOrderingService{
+		ObjectMeta: metav1.ObjectMeta{Name: "ordservice", Namespace: "orderers"},
+	}
+	ordService.Spec.Enrollment.TLS.CARef = &CARef{Name: "ord-ca"}
+	g.Expect(ordService.ReferencesCA("orderers", "ord-ca")).To(BeTrue())
+}
@@ -0,0 +1,204 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DeprecatedField describes a field holding an inline credential that should be replaced by a Secret reference
+// +kubebuilder:object:generate=false
+type DeprecatedField struct {
+	Path        string
+	Replacement string
+}
+
+func (d DeprecatedField) String() string {
+	return fmt.Sprintf("%s is deprecated, use %s instead", d.Path, d.Replacement)
+}
+
+func (c *Component) deprecatedFields(path string) []DeprecatedField {
+	var fields []DeprecatedField
+	if c.Enrollsecret != "" {
+		fields = append(fields, DeprecatedField{
+			Path:        fmt.Sprintf("%s.enrollsecret", path),
+			Replacement: fmt.Sprintf("%s.enrollsecretRef", path),
+		})
+	}
+	return fields
+}
+
+func (t *TLS) deprecatedFields(path string) []DeprecatedField {
+	var fields []DeprecatedField
+	if t.Enrollsecret != "" {
+		fields = append(fields, DeprecatedField{
+			Path:        fmt.Sprintf("%s.enrollsecret", path),
+			Replacement: fmt.Sprintf("%s.enrollsecretRef", path),
+		})
+	}
+	return fields
+}
+
+func (c *FabricCAItemConf) deprecatedFields(path string) []DeprecatedField {
+	var fields []DeprecatedField
+	for idx, identity := range c.Registry.Identities {
+		if identity.Pass != "" {
+			fields = append(fields, DeprecatedField{
+				Path:        fmt.Sprintf("%s.registry.identities[%d].pass", path, idx),
+				Replacement: fmt.Sprintf("%s.registry.identities[%d].passRef", path, idx),
+			})
+		}
+	}
+	return fields
+}
+
+// DeprecatedFields returns the inline credentials set in the peer spec
+func (in *FabricPeer) DeprecatedFields() []DeprecatedField {
+	var fields []DeprecatedField
+	fields = append(fields, in.Spec.Secret.Enrollment.Component.deprecatedFields("spec.secret.enrollment.component")...)
+	fields = append(fields, in.Spec.Secret.Enrollment.TLS.deprecatedFields("spec.secret.enrollment.tls")...)
+	if in.Spec.CouchDB.Password != "" {
+		fields = append(fields, DeprecatedField{
+			Path:        "spec.couchdb.password",
+			Replacement: "spec.couchdb.passwordRef",
+		})
+	}
+	return fields
+}
+
+// DeprecatedFields returns the inline credentials set in the orderer node spec
+func (in *FabricOrdererNode) DeprecatedFields() []DeprecatedField {
+	var fields []DeprecatedField
+	if in.Spec.Secret != nil {
+		fields = append(fields, in.Spec.Secret.Enrollment.Component.deprecatedFields("spec.secret.enrollment.component")...)
+		fields = append(fields, in.Spec.Secret.Enrollment.TLS.deprecatedFields("spec.secret.enrollment.tls")...)
+	}
+	return fields
+}
+
+// DeprecatedFields returns the inline credentials set in the ordering service spec
+func (in *FabricOrderingService) DeprecatedFields() []DeprecatedField {
+	var fields []DeprecatedField
+	fields = append(fields, in.Spec.Enrollment.Component.deprecatedFields("spec.enrollment.component")...)
+	fields = append(fields, in.Spec.Enrollment.TLS.deprecatedFields("spec.enrollment.tls")...)
+	return fields
+}
+
+// DeprecatedFields returns the inline credentials set in the CA spec
+func (in *FabricCA) DeprecatedFields() []DeprecatedField {
+	var fields []DeprecatedField
+	if in.Spec.Database.Datasource != "" {
+		fields = append(fields, DeprecatedField{
+			Path:        "spec.db.datasource",
+			Replacement: "spec.db.datasourceRef",
+		})
+	}
+	fields = append(fields, in.Spec.CA.deprecatedFields("spec.ca")...)
+	fields = append(fields, in.Spec.TLSCA.deprecatedFields("spec.tlsCA")...)
+	return fields
+}
+
+func appendSecretName(names []string, ref *corev1.SecretKeySelector) []string {
+	if ref == nil {
+		return names
+	}
+	return appendName(names, ref.Name)
+}
+
+func appendName(names []string, secretName string) []string {
+	if secretName == "" {
+		return names
+	}
+	for _, name := range names {
+		if name == secretName {
+			return names
+		}
+	}
+	return append(names, secretName)
+}
+
+func (s *Secret) secretNames(names []string, name string) []string {
+	names = appendSecretName(names, s.Enrollment.Component.EnrollsecretRef)
+	names = appendSecretName(names, s.Enrollment.TLS.EnrollsecretRef)
+	if s.Enrollment.Component.Vault != nil {
+		names = appendSecretName(names, s.Enrollment.Component.Vault.Auth.TokenRef)
+	}
+	if s.Enrollment.TLS.CertManager != nil {
+		names = appendName(names, CertManagerSecretName(name))
+	}
+	if s.Crypto != nil {
+		names = appendName(names, s.Crypto.Sign.SecretName)
+		names = appendName(names, s.Crypto.TLS.SecretName)
+	}
+	return names
+}
+
+func (c *BCCSPPKCS11) secretNames(names []string) []string {
+	if c == nil {
+		return names
+	}
+	return appendName(names, c.PinRef.Name)
+}
+
+// SecretNames returns the names of the Secrets referenced by the peer spec
+func (in *FabricPeer) SecretNames() []string {
+	var names []string
+	names = in.Spec.Secret.secretNames(names, in.Name)
+	names = appendSecretName(names, in.Spec.CouchDB.PasswordRef)
+	names = in.Spec.BCCSP.PKCS11Config().secretNames(names)
+	return names
+}
+
+// SecretNames returns the names of the Secrets referenced by the orderer node spec
+func (in *FabricOrdererNode) SecretNames() []string {
+	var names []string
+	if in.Spec.Secret != nil {
+		names = in.Spec.Secret.secretNames(names, in.Name)
+	}
+	names = in.Spec.BCCSP.PKCS11Config().secretNames(names)
+	return names
+}
+
+// SecretNames returns the names of the Secrets referenced by the ordering service spec
+func (in *FabricOrderingService) SecretNames() []string {
+	var names []string
+	names = appendSecretName(names, in.Spec.Enrollment.Component.EnrollsecretRef)
+	names = appendSecretName(names, in.Spec.Enrollment.TLS.EnrollsecretRef)
+	if in.Spec.Enrollment.TLS.CertManager != nil {
+		for _, node := range in.Spec.Nodes {
+			names = appendName(names, CertManagerSecretName(in.NodeTLSCertificateName(node)))
+		}
+	}
+	if in.Spec.SystemChannel.ConfigUpdate != nil {
+		names = appendSecretName(names, &in.Spec.SystemChannel.ConfigUpdate.NetworkConfigRef)
+	}
+	return names
+}
+
+// SecretNames returns the names of the Secrets referenced by the CA spec
+func (in *FabricCA) SecretNames() []string {
+	var names []string
+	names = appendSecretName(names, in.Spec.Database.DatasourceRef)
+	if in.Spec.TLS.CertManager != nil {
+		names = appendName(names, CertManagerSecretName(in.Name))
+	}
+	for _, conf := range []FabricCAItemConf{in.Spec.CA, in.Spec.TLSCA} {
+		for _, identity := range conf.Registry.Identities {
+			names = appendSecretName(names, identity.PassRef)
+		}
+		names = conf.BCCSP.PKCS11Config().secretNames(names)
+		if conf.Intermediate.ParentRef != nil {
+			names = appendSecretName(names, conf.Intermediate.ParentRef.EnrollsecretRef)
+		}
+		if conf.LDAP != nil {
+			names = appendSecretName(names, conf.LDAP.BindPasswordRef)
+		}
+	}
+	if in.Spec.Idemix != nil {
+		names = appendName(names, in.IdemixSecretName())
+	}
+	if in.Spec.CRLPropagation != nil {
+		names = appendSecretName(names, &in.Spec.CRLPropagation.NetworkConfigRef)
+	}
+	return names
+}
@@ -0,0 +1,22 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFabricOrderingServiceSecretNames(t *testing.T) {
+	g := NewWithT(t)
+	ordService := &FabricOrderingService{
+		ObjectMeta: metav1.ObjectMeta{Name: "ord", Namespace: "default"},
+		Spec: FabricOrderingServiceSpec{
+			Nodes: []OrdererNode{{ID: "Orderer0"}, {ID: "orderer1"}},
+		},
+	}
+	g.Expect(ordService.SecretNames()).To(BeEmpty())
+
+	ordService.Spec.Enrollment.TLS.CertManager = &CertManagerTLS{IssuerRef: CertManagerIssuerRef{Name: "issuer"}}
+	g.Expect(ordService.SecretNames()).To(ConsistOf("ord-orderer0-tls-certmanager", "ord-orderer1-tls-certmanager"))
+}
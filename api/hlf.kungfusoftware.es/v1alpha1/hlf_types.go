@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/operator-framework/operator-lib/status"
 	corev1 "k8s.io/api/core/v1"
@@ -116,6 +117,10 @@ type FabricPeerSpec struct {
 	Logging   FabricPeerLogging   `json:"logging"`
 	Resources FabricPeerResources `json:"resources"`
 	Hosts     []string            `json:"hosts"`
+	// Crypto provider holding the signing key of the peer
+	// +optional
+	// +nullable
+	BCCSP *BCCSP `json:"bccsp"`
 }
 type FabricPeerResources struct {
 	Peer      corev1.ResourceRequirements `json:"peer"`
@@ -142,8 +147,13 @@ type FabricPeerStorage struct {
 	Chaincode Storage `json:"chaincode"`
 }
 type FabricPeerCouchDB struct {
-	User     string `json:"user"`
+	User string `json:"user"`
+	// +optional
 	Password string `json:"password"`
+	// Key of a Secret holding the CouchDB password, takes precedence over password
+	// +optional
+	// +nullable
+	PasswordRef *corev1.SecretKeySelector `json:"passwordRef"`
 }
 type FabricIstio struct {
 	// +optional
@@ -168,23 +178,108 @@ type FabricPeerSpecGossip struct {
 type Catls struct {
 	Cacert string `json:"cacert"`
 }
-type Component struct {
+
+// CARef references a FabricCA, the CA URL, name and TLS root certificate are taken from its status
+type CARef struct {
 	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the FabricCA, defaults to the namespace of the resource
+	// +optional
+	Namespace string `json:"namespace"`
+}
+
+type Component struct {
+	// +optional
 	Cahost string `json:"cahost"`
-	// +kubebuilder:validation:MinLength=1
+	// +optional
 	Caname string `json:"caname"`
-	Caport int    `json:"caport"`
-	Catls  Catls  `json:"catls"`
+	// +optional
+	Caport int `json:"caport"`
+	// +optional
+	Catls Catls `json:"catls"`
+	// FabricCA to enroll against, takes precedence over cahost, caname, caport and catls
+	// +optional
+	// +nullable
+	CARef *CARef `json:"caRef"`
 	// +kubebuilder:validation:MinLength=1
 	Enrollid string `json:"enrollid"`
-	// +kubebuilder:validation:MinLength=1
+	// +optional
 	Enrollsecret string `json:"enrollsecret"`
+	// Key of a Secret holding the enrollment secret, takes precedence over enrollsecret
+	// +optional
+	// +nullable
+	EnrollsecretRef *corev1.SecretKeySelector `json:"enrollsecretRef"`
+	// Keep the signing key in HashiCorp Vault instead of a Secret
+	// +optional
+	// +nullable
+	Vault *VaultKeyStore `json:"vault"`
+	// Signing profile of the CA used to issue the certificate, the default profile when empty
+	// +optional
+	Profile string `json:"profile"`
+	// +optional
+	KeyAlgorithm KeyAlgorithm `json:"keyAlgorithm,omitempty"`
 }
 
+// KeyAlgorithm is the algorithm of the private key generated to enroll a certificate
+// +kubebuilder:validation:Enum=ECDSA_P256;ECDSA_P384
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA_P256"
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSA_P384"
+)
+
 func (c *Component) CAUrl() string {
 	return fmt.Sprintf("https://%s:%d", c.Cahost, c.Caport)
 }
 
+// VaultKeyStore issues the signing identity of a node from HashiCorp Vault PKI, the node receives its certificate
+// and key at startup through the Vault agent injector so that the key is never stored in a Secret
+type VaultKeyStore struct {
+	// Address of the Vault server, e.g. http://vault.vault:8200
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+	// +optional
+	Catls Catls `json:"catls"`
+	// +optional
+	Auth VaultAuth `json:"auth"`
+	// Role the Vault agent injected in the node pod logs in with
+	// +kubebuilder:validation:MinLength=1
+	AgentRole string `json:"agentRole"`
+	// PKI role issuing the signing certificate, it replaces the enrollment against the CA
+	PKI VaultPKI `json:"pki"`
+}
+
+// VaultAuth is how the operator logs in to Vault
+type VaultAuth struct {
+	// Role of the Kubernetes auth method the operator logs in with its service account
+	// +optional
+	Role string `json:"role"`
+	// Mount path of the Kubernetes auth method
+	// +kubebuilder:default:="kubernetes"
+	// +optional
+	Path string `json:"path"`
+	// Key of a Secret holding a Vault token, takes precedence over the Kubernetes auth
+	// +optional
+	// +nullable
+	TokenRef *corev1.SecretKeySelector `json:"tokenRef"`
+}
+
+type VaultPKI struct {
+	// +kubebuilder:default:="pki"
+	// +optional
+	Mount string `json:"mount"`
+	// PKI role issuing the certificate, it must set ou to the node type, peer or orderer, for the NodeOUs of the MSP,
+	// the operator reads the role to check it
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+	// Common name of the certificate, defaults to the enrollment ID
+	// +optional
+	CommonName string `json:"commonName"`
+	// +optional
+	TTL string `json:"ttl"`
+}
+
 type Csr struct {
 	// +optional
 	Hosts []string `json:"hosts"`
@@ -192,15 +287,75 @@ type Csr struct {
 	CN string `json:"cn"`
 }
 type TLS struct {
+	// +optional
 	Cahost string `json:"cahost"`
+	// +optional
 	Caname string `json:"caname"`
-	Caport int    `json:"caport"`
-	Catls  Catls  `json:"catls"`
 	// +optional
-	Csr          Csr    `json:"csr"`
-	Enrollid     string `json:"enrollid"`
+	Caport int `json:"caport"`
+	// +optional
+	Catls Catls `json:"catls"`
+	// FabricCA to enroll against, takes precedence over cahost, caname, caport and catls
+	// +optional
+	// +nullable
+	CARef *CARef `json:"caRef"`
+	// +optional
+	Csr Csr `json:"csr"`
+	// Signing profile of the TLS CA used to issue the certificate, defaults to "tls"
+	// +optional
+	Profile string `json:"profile"`
+	// +optional
+	KeyAlgorithm KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+	// Request the TLS certificate through cert-manager instead of enrolling against the TLS CA
+	// +optional
+	// +nullable
+	CertManager *CertManagerTLS `json:"certManager"`
+	// +optional
+	Enrollid string `json:"enrollid"`
+	// +optional
 	Enrollsecret string `json:"enrollsecret"`
+	// Key of a Secret holding the enrollment secret, takes precedence over enrollsecret
+	// +optional
+	// +nullable
+	EnrollsecretRef *corev1.SecretKeySelector `json:"enrollsecretRef"`
 }
+
+// EnrollProfile returns the signing profile of the TLS CA the certificate is enrolled with
+func (t *TLS) EnrollProfile() string {
+	if t.Profile == "" {
+		return "tls"
+	}
+	return t.Profile
+}
+
+// CertManagerTLS issues a TLS certificate through a cert-manager Issuer or ClusterIssuer
+type CertManagerTLS struct {
+	IssuerRef CertManagerIssuerRef `json:"issuerRef"`
+}
+
+// CertManagerIssuerRef references the cert-manager issuer signing the certificate
+type CertManagerIssuerRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +kubebuilder:default:=Issuer
+	// +optional
+	Kind string `json:"kind"`
+	// +kubebuilder:default:="cert-manager.io"
+	// +optional
+	Group string `json:"group"`
+}
+
+// CertManagerSecretName returns the name of the Secret cert-manager stores the TLS certificate of a resource in
+func CertManagerSecretName(name string) string {
+	return fmt.Sprintf("%s-tls-certmanager", name)
+}
+
+// NodeTLSCertificateName returns the name cert-manager issues the TLS certificate of a node of the ordering service under
+func (in *FabricOrderingService) NodeTLSCertificateName(node OrdererNode) string {
+	return fmt.Sprintf("%s-%s", in.Name, strings.ToLower(node.ID))
+}
+
 type Enrollment struct {
 	Component Component `json:"component"`
 	TLS       TLS       `json:"tls"`
@@ -210,7 +365,99 @@ type OrdererEnrollment struct {
 	TLS       TLS       `json:"tls"`
 }
 type Secret struct {
+	// +optional
 	Enrollment Enrollment `json:"enrollment"`
+	// Existing crypto material to deploy with, when set the node doesn't enroll against any CA
+	// +optional
+	// +nullable
+	Crypto *Crypto `json:"crypto"`
+	// Generate the crypto material in the operator instead of enrolling against a CA, meant for development and test networks
+	// +optional
+	// +nullable
+	Generated *GeneratedCrypto `json:"generated"`
+}
+
+// GeneratedCrypto issues the node certificates from a root CA and a TLS CA generated for the MSP
+type GeneratedCrypto struct {
+	// Secret holding the CAs generated for the MSP, shared by all the nodes of the organization.
+	// Defaults to the MSP ID in lowercase followed by -generated-ca
+	// +optional
+	CASecretName string `json:"caSecretName"`
+}
+
+// EnrollsAgainstCA returns false when the crypto material is provided in Secrets or generated by the operator
+func (s *Secret) EnrollsAgainstCA() bool {
+	return s.Crypto == nil && s.Generated == nil
+}
+
+// BCCSP selects the crypto provider of a peer or an orderer node
+type BCCSP struct {
+	// +kubebuilder:validation:Enum=SW;PKCS11
+	// +kubebuilder:default:="SW"
+	Default string `json:"default"`
+	// +optional
+	// +nullable
+	PKCS11 *BCCSPPKCS11 `json:"pkcs11"`
+}
+
+// PKCS11Config returns the HSM settings, nil when the keys are kept in software
+func (b *BCCSP) PKCS11Config() *BCCSPPKCS11 {
+	if b == nil || b.Default != "PKCS11" {
+		return nil
+	}
+	return b.PKCS11
+}
+
+// BCCSPPKCS11 configures a PKCS#11 HSM, the signing keys are generated in the HSM and never leave it.
+// The operator needs access to the same library and token to enroll the identities
+type BCCSPPKCS11 struct {
+	// Path of the PKCS#11 library in the container
+	// +kubebuilder:validation:MinLength=1
+	Library string `json:"library"`
+	// Label of the token
+	// +kubebuilder:validation:MinLength=1
+	Label string `json:"label"`
+	// Secret holding the user PIN of the token
+	PinRef corev1.SecretKeySelector `json:"pinRef"`
+	// +kubebuilder:default:="SHA2"
+	// +optional
+	Hash string `json:"hash"`
+	// +kubebuilder:default:=256
+	// +optional
+	Security int `json:"security"`
+	// Image holding the library at the same path, the directory of the library is copied
+	// into the container by an init container
+	// +optional
+	LibraryImage string `json:"libraryImage"`
+	// Environment variables of the container, e.g. SOFTHSM2_CONF
+	// +optional
+	// +nullable
+	Env []corev1.EnvVar `json:"env"`
+	// Volume with the HSM configuration or tokens, e.g. the SoftHSM token directory
+	// +optional
+	// +nullable
+	Volume *HSMVolume `json:"volume"`
+}
+
+// HSMVolume mounts a PersistentVolumeClaim in the container
+type HSMVolume struct {
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+	// +kubebuilder:validation:MinLength=1
+	MountPath string `json:"mountPath"`
+}
+
+// Crypto references Secrets holding crypto material issued outside of a Fabric CA
+type Crypto struct {
+	Sign CryptoSecret `json:"sign"`
+	TLS  CryptoSecret `json:"tls"`
+}
+
+// CryptoSecret references a Secret with the cert.pem, key.pem and cacert.pem keys
+// and optionally intermediatecerts.pem
+type CryptoSecret struct {
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
 }
 type OrdererNode struct {
 	// +kubebuilder:validation:MinLength=1
@@ -336,12 +583,29 @@ type FabricOrdererNodeSpec struct {
 	// +kubebuilder:validation:Optional
 	// +nullable
 	AdminIstio *FabricIstio `json:"adminIstio"`
+	// Crypto provider holding the signing key of the orderer node
+	// +optional
+	// +nullable
+	BCCSP *BCCSP `json:"bccsp"`
 }
 
 type OrdererSystemChannel struct {
 	// +kubebuilder:validation:MinLength=3
 	Name   string        `json:"name"`
 	Config ChannelConfig `json:"config"`
+	// Update the system channel when the config changes, the config is only used in the genesis block otherwise
+	// +optional
+	// +nullable
+	ConfigUpdate *OrdererSystemChannelConfigUpdate `json:"configUpdate"`
+}
+
+// OrdererSystemChannelConfigUpdate is the identity submitting the config updates of the system channel
+type OrdererSystemChannelConfigUpdate struct {
+	// Network config of the Fabric SDK with the orderers of the ordering service and the identity of its admin
+	NetworkConfigRef corev1.SecretKeySelector `json:"networkConfigRef"`
+	// User of the network config that signs the config updates, an admin of the orderer organization
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
 }
 type OrdererCapabilities struct {
 	V2_0 bool `json:"V2_0"`
@@ -371,6 +635,28 @@ type ChannelConfig struct {
 type FabricOrderingServiceStatus struct {
 	Conditions status.Conditions `json:"conditions"`
 	Status     DeploymentStatus  `json:"status"`
+	// State of the config updates of spec.systemChannel.configUpdate
+	// +optional
+	// +nullable
+	SystemChannel *FabricOrderingServiceChannelStatus `json:"systemChannel"`
+}
+
+// ChannelConfigStatus is the state of the config updates the operator submits to a channel
+type ChannelConfigStatus string
+
+const (
+	ChannelConfigSynced ChannelConfigStatus = "Synced"
+	ChannelConfigFailed ChannelConfigStatus = "Failed"
+)
+
+// FabricOrderingServiceChannelStatus is the state of the config of a channel of the ordering service
+type FabricOrderingServiceChannelStatus struct {
+	Status ChannelConfigStatus `json:"status"`
+	// +optional
+	Message string `json:"message"`
+	// Transaction of the last config update submitted to the channel
+	// +optional
+	TxID string `json:"txID"`
 }
 
 // FabricOrdererNodeStatus defines the observed state of FabricOrdererNode
@@ -380,6 +666,8 @@ type FabricOrdererNodeStatus struct {
 	// +optional
 	TlsCert string `json:"tlsCert"`
 	// +optional
+	TlsCACert string `json:"tlsCaCert"`
+	// +optional
 	TlsAdminCert string `json:"tlsAdminCert"`
 	// +optional
 	OperationsPort int `json:"operationsPort"`
@@ -397,8 +685,13 @@ type Cors struct {
 	Origins []string `json:"origins"`
 }
 type FabricCADatabase struct {
-	Type       string `json:"type"`
+	Type string `json:"type"`
+	// +optional
 	Datasource string `json:"datasource"`
+	// Key of a Secret holding the datasource, takes precedence over datasource
+	// +optional
+	// +nullable
+	DatasourceRef *corev1.SecretKeySelector `json:"datasourceRef"`
 }
 
 // FabricCASpec defines the desired state of FabricCA
@@ -411,6 +704,12 @@ type FabricCASpec struct {
 	// +nullable
 	Istio    *FabricIstio     `json:"istio"`
 	Database FabricCADatabase `json:"db"`
+	// Number of pods of the CA, more than one requires a postgres or mysql database given by db.datasourceRef
+	// and disables the local storage
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas int `json:"replicas"`
 	// +kubebuilder:validation:MinItems=1
 	// Hosts for the Fabric CA
 	Hosts   []string            `json:"hosts"`
@@ -431,10 +730,83 @@ type FabricCASpec struct {
 	Resources corev1.ResourceRequirements `json:"resources"`
 	Storage   Storage                     `json:"storage"`
 	Metrics   FabricCAMetrics             `json:"metrics"`
+	// Idemix issuer of the CA, the issuer keys are kept in a Secret so that they survive restarts and are shared
+	// by the replicas
+	// +optional
+	// +nullable
+	Idemix *FabricCAIdemix `json:"idemix"`
+	// Update the RevocationList of the organization in its channels when the CA generates a CRL
+	// +optional
+	// +nullable
+	CRLPropagation *FabricCACRLPropagation `json:"crlPropagation"`
+}
+
+// FabricCAIdemix configures the issuance of Idemix (anonymous) credentials
+type FabricCAIdemix struct {
+	// Secret with the issuer keys in the IssuerPublicKey, IssuerSecretKey, IssuerRevocationPublicKey and
+	// IssuerRevocationPrivateKey keys, the operator generates them in the <name>--idemix Secret when empty
+	// +optional
+	IssuerKeysSecret string `json:"issuerKeysSecret"`
+	// Number of revocation handles allocated in advance
+	// +kubebuilder:default:=1000
+	// +optional
+	RHPoolSize int `json:"rhPoolSize"`
+	// Duration of the nonces of the credential requests
+	// +kubebuilder:default:="15s"
+	// +optional
+	NonceExpiration string `json:"nonceExpiration"`
+	// Interval of the removal of expired nonces
+	// +kubebuilder:default:="15m"
+	// +optional
+	NonceSweepInterval string `json:"nonceSweepInterval"`
+}
+
+// IdemixSecretName returns the Secret with the Idemix issuer keys of the CA
+func (in *FabricCA) IdemixSecretName() string {
+	if in.Spec.Idemix != nil && in.Spec.Idemix.IssuerKeysSecret != "" {
+		return in.Spec.Idemix.IssuerKeysSecret
+	}
+	return fmt.Sprintf("%s--idemix", in.Name)
+}
+
+// FabricCACRLPropagation sets the CRL generated by the CA as the RevocationList of the organization MSP in every
+// channel the organization belongs to. The CRL is generated with an identity of the registry with hf.GenCRL
+type FabricCACRLPropagation struct {
+	// MSP ID of the organization whose certificates are issued by the CA
+	// +kubebuilder:validation:MinLength=1
+	MSPID string `json:"mspID"`
+	// Network config of the Fabric SDK with the peers and the orderers of the organization and the identity of its
+	// admin, the channels of the organization are the ones joined by its peers
+	NetworkConfigRef corev1.SecretKeySelector `json:"networkConfigRef"`
+	// User of the network config that signs the config updates, an admin of the organization
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
+}
+
+type CRLPropagationStatus string
+
+const (
+	CRLPropagationSynced CRLPropagationStatus = "Synced"
+	CRLPropagationFailed CRLPropagationStatus = "Failed"
+)
+
+// FabricCAChannelCRLStatus is the state of the CRL propagation to a channel
+type FabricCAChannelCRLStatus struct {
+	Channel string               `json:"channel"`
+	Status  CRLPropagationStatus `json:"status"`
+	// +optional
+	Message string `json:"message"`
+	// Transaction of the last config update submitted to the channel
+	// +optional
+	TxID string `json:"txID"`
 }
 
 type FabricCATLSConf struct {
 	Subject FabricCASubject `json:"subject"`
+	// Request the TLS certificate of the CA server through cert-manager instead of self-signing it
+	// +optional
+	// +nullable
+	CertManager *CertManagerTLS `json:"certManager"`
 }
 type FabricCACFG struct {
 	Identities   FabricCACFGIdentities  `json:"identities"`
@@ -490,6 +862,16 @@ type FabricCAItemConf struct {
 	Registry     FabricCARegistry     `json:"registry"`
 	Intermediate FabricCAIntermediate `json:"intermediate"`
 	BCCSP        FabricCABCCSP        `json:"bccsp"`
+	// LDAP server used as the registry of the CA, the identities of the registry are ignored when set
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +nullable
+	LDAP *FabricCALDAP `json:"ldap"`
+	// Signing profiles of the CA, the "ca" and "tls" profiles are kept unless overridden
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Signing *FabricCASigning `json:"signing"`
 	// +optional
 	// +kubebuilder:validation:Optional
 	// +nullable
@@ -529,10 +911,23 @@ type FabricCASubject struct {
 	OU string `json:"OU"`
 }
 type FabricCABCCSP struct {
+	// +kubebuilder:validation:Enum=SW;PKCS11
 	// +kubebuilder:default:="SW"
 	Default string          `json:"default"`
 	SW      FabricCABCCSPSW `json:"sw"`
+	// +optional
+	// +nullable
+	PKCS11 *BCCSPPKCS11 `json:"pkcs11"`
 }
+
+// PKCS11Config returns the HSM settings, nil when the keys are kept in software
+func (b FabricCABCCSP) PKCS11Config() *BCCSPPKCS11 {
+	if b.Default != "PKCS11" {
+		return nil
+	}
+	return b.PKCS11
+}
+
 type FabricCABCCSPSW struct {
 	// +kubebuilder:default:="SHA2"
 	Hash string `json:"hash"`
@@ -542,6 +937,32 @@ type FabricCABCCSPSW struct {
 
 type FabricCAIntermediate struct {
 	ParentServer FabricCAIntermediateParentServer `json:"parentServer"`
+	// Parent FabricCA the operator registers and enrolls this CA against as an intermediate CA
+	// +optional
+	// +nullable
+	ParentRef *FabricCAParentRef `json:"parentRef"`
+}
+
+// FabricCAParentRef references the FabricCA issuing the certificate of an intermediate CA, the CA is enrolled against
+// the CA of the parent for the signing certificates and against the TLS CA of the parent for the TLS certificates
+type FabricCAParentRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the parent FabricCA, defaults to the namespace of the FabricCA
+	// +optional
+	Namespace string `json:"namespace"`
+	// Registrar of the parent CA allowed to register identities with the hf.IntermediateCA attribute
+	// +kubebuilder:validation:MinLength=1
+	Enrollid string `json:"enrollid"`
+	// +optional
+	Enrollsecret string `json:"enrollsecret"`
+	// Key of a Secret holding the enrollment secret of the registrar, takes precedence over enrollsecret
+	// +optional
+	// +nullable
+	EnrollsecretRef *corev1.SecretKeySelector `json:"enrollsecretRef"`
+	// Identity of the intermediate CA in the parent CA, defaults to <namespace>-<name>-<ca name>
+	// +optional
+	Identity string `json:"identity"`
 }
 type FabricCAIntermediateParentServer struct {
 	URL    string `json:"url"`
@@ -566,8 +987,13 @@ type FabricCARegistry struct {
 }
 type FabricCAIdentity struct {
 	Name string `json:"name"`
+	// +optional
 	Pass string `json:"pass"`
-	Type string `json:"type"`
+	// Key of a Secret holding the identity password, takes precedence over pass
+	// +optional
+	// +nullable
+	PassRef *corev1.SecretKeySelector `json:"passRef"`
+	Type    string                    `json:"type"`
 	// +kubebuilder:default:=""
 	Affiliation string                `json:"affiliation"`
 	Attrs       FabricCAIdentityAttrs `json:"attrs"`
@@ -592,6 +1018,77 @@ type FabricCACRL struct {
 	// +kubebuilder:default:="24h"
 	Expiry string `json:"expiry"`
 }
+
+// FabricCALDAP authenticates enrollments against an LDAP server and computes the attributes of the identities
+// from their LDAP entries
+type FabricCALDAP struct {
+	// URL of the server with the base DN of the searches, e.g. ldaps://ldap.example.com:636/dc=example,dc=com
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// DN the CA binds with to search users and groups
+	// +optional
+	BindDN string `json:"bindDN"`
+	// Key of a Secret holding the password of the bind DN
+	// +optional
+	// +nullable
+	BindPasswordRef *corev1.SecretKeySelector `json:"bindPasswordRef"`
+	// +kubebuilder:default:="(uid=%s)"
+	// +optional
+	UserFilter string `json:"userFilter"`
+	// +kubebuilder:default:="(memberUid=%s)"
+	// +optional
+	GroupFilter string `json:"groupFilter"`
+	// Certificate of the CA of the LDAP server for ldaps URLs
+	// +optional
+	Catls Catls `json:"catls"`
+	// +optional
+	Attribute FabricCALDAPAttribute `json:"attribute"`
+}
+type FabricCALDAPAttribute struct {
+	// LDAP attributes requested for the entry of an identity
+	// +optional
+	Names []string `json:"names"`
+	// Fabric CA attributes computed from the LDAP entry, e.g. hf.Revoker with value attr("uid") =~ "revoker*"
+	// +optional
+	Converters []FabricCALDAPConverter `json:"converters"`
+	// Named maps referenced by the map function of the converters
+	// +optional
+	Maps map[string]FabricCALDAPMap `json:"maps"`
+}
+type FabricCALDAPConverter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+type FabricCALDAPMap []FabricCALDAPMapEntry
+type FabricCALDAPMapEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+type FabricCASigning struct {
+	// +optional
+	Default FabricCASigningProfile `json:"default"`
+	// +optional
+	Profiles map[string]FabricCASigningProfile `json:"profiles"`
+}
+type FabricCASigningProfile struct {
+	// +kubebuilder:default:="8760h"
+	// +optional
+	Expiry string `json:"expiry"`
+	// Key usages of the certificates, e.g. digital signature, cert sign or server auth
+	// +optional
+	Usage []string `json:"usage"`
+	// +optional
+	// +nullable
+	CAConstraint *FabricCASigningCAConstraint `json:"caConstraint"`
+}
+type FabricCASigningCAConstraint struct {
+	// +optional
+	IsCA bool `json:"isCA"`
+	// +optional
+	MaxPathLen int `json:"maxPathLen"`
+	// +optional
+	MaxPathLenZero bool `json:"maxPathLenZero"`
+}
 type FabricCACSR struct {
 	// +kubebuilder:default:="ca"
 	CN string `json:"cn"`
@@ -644,6 +1141,25 @@ type FabricCAStatus struct {
 	CACert string `json:"ca_cert"`
 	// Root certificate for TLS certificates generated by FabricCA
 	TLSCACert string `json:"tlsca_cert"`
+	// Intermediate certificates between the root and the CA for Sign certificates, empty for a root CA
+	// +optional
+	CAIntermediateCerts string `json:"ca_intermediate_certs"`
+	// Intermediate certificates between the root and the CA for TLS certificates, empty for a root CA
+	// +optional
+	TLSCAIntermediateCerts string `json:"tlsca_intermediate_certs"`
+	// Idemix issuer public key of the CA, base64 encoded, empty when spec.idemix isn't set
+	// +optional
+	IdemixIssuerPublicKey string `json:"idemix_issuer_public_key"`
+	// PEM encoded Idemix revocation public key of the CA
+	// +optional
+	IdemixRevocationPublicKey string `json:"idemix_revocation_public_key"`
+	// State of the CRL propagation to each channel of the organization of spec.crlPropagation
+	// +optional
+	CRLPropagation []FabricCAChannelCRLStatus `json:"crlPropagation"`
+	// Last time the CRL of the CA was checked for changes to propagate
+	// +optional
+	// +nullable
+	CRLCheckTime *metav1.Time `json:"crlCheckTime"`
 }
 
 // +kubebuilder:object:root=true
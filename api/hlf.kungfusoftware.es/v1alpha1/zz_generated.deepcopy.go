@@ -41,6 +41,54 @@ func (in *ApplicationCapabilities) DeepCopy() *ApplicationCapabilities {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BCCSP) DeepCopyInto(out *BCCSP) {
+	*out = *in
+	if in.PKCS11 != nil {
+		in, out := &in.PKCS11, &out.PKCS11
+		*out = new(BCCSPPKCS11)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BCCSP.
+func (in *BCCSP) DeepCopy() *BCCSP {
+	if in == nil {
+		return nil
+	}
+	out := new(BCCSP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BCCSPPKCS11) DeepCopyInto(out *BCCSPPKCS11) {
+	*out = *in
+	in.PinRef.DeepCopyInto(&out.PinRef)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(HSMVolume)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BCCSPPKCS11.
+func (in *BCCSPPKCS11) DeepCopy() *BCCSPPKCS11 {
+	if in == nil {
+		return nil
+	}
+	out := new(BCCSPPKCS11)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CA) DeepCopyInto(out *CA) {
 	*out = *in
@@ -56,6 +104,21 @@ func (in *CA) DeepCopy() *CA {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CARef) DeepCopyInto(out *CARef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CARef.
+func (in *CARef) DeepCopy() *CARef {
+	if in == nil {
+		return nil
+	}
+	out := new(CARef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Catls) DeepCopyInto(out *Catls) {
 	*out = *in
@@ -71,6 +134,37 @@ func (in *Catls) DeepCopy() *Catls {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerTLS) DeepCopyInto(out *CertManagerTLS) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerTLS.
+func (in *CertManagerTLS) DeepCopy() *CertManagerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChannelCapabilities) DeepCopyInto(out *ChannelCapabilities) {
 	*out = *in
@@ -108,6 +202,21 @@ func (in *ChannelConfig) DeepCopy() *ChannelConfig {
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
 	out.Catls = in.Catls
+	if in.CARef != nil {
+		in, out := &in.CARef, &out.CARef
+		*out = new(CARef)
+		**out = **in
+	}
+	if in.EnrollsecretRef != nil {
+		in, out := &in.EnrollsecretRef, &out.EnrollsecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultKeyStore)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Component.
@@ -140,6 +249,38 @@ func (in *Cors) DeepCopy() *Cors {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Crypto) DeepCopyInto(out *Crypto) {
+	*out = *in
+	out.Sign = in.Sign
+	out.TLS = in.TLS
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Crypto.
+func (in *Crypto) DeepCopy() *Crypto {
+	if in == nil {
+		return nil
+	}
+	out := new(Crypto)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CryptoSecret) DeepCopyInto(out *CryptoSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CryptoSecret.
+func (in *CryptoSecret) DeepCopy() *CryptoSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(CryptoSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Csr) DeepCopyInto(out *Csr) {
 	*out = *in
@@ -163,7 +304,7 @@ func (in *Csr) DeepCopy() *Csr {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Enrollment) DeepCopyInto(out *Enrollment) {
 	*out = *in
-	out.Component = in.Component
+	in.Component.DeepCopyInto(&out.Component)
 	in.TLS.DeepCopyInto(&out.TLS)
 }
 
@@ -228,6 +369,11 @@ func (in *FabricCA) DeepCopyObject() runtime.Object {
 func (in *FabricCABCCSP) DeepCopyInto(out *FabricCABCCSP) {
 	*out = *in
 	out.SW = in.SW
+	if in.PKCS11 != nil {
+		in, out := &in.PKCS11, &out.PKCS11
+		*out = new(BCCSPPKCS11)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCABCCSP.
@@ -317,6 +463,22 @@ func (in *FabricCACRL) DeepCopy() *FabricCACRL {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCACRLPropagation) DeepCopyInto(out *FabricCACRLPropagation) {
+	*out = *in
+	in.NetworkConfigRef.DeepCopyInto(&out.NetworkConfigRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCACRLPropagation.
+func (in *FabricCACRLPropagation) DeepCopy() *FabricCACRLPropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCACRLPropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCACSR) DeepCopyInto(out *FabricCACSR) {
 	*out = *in
@@ -358,6 +520,21 @@ func (in *FabricCACSRCA) DeepCopy() *FabricCACSRCA {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCAChannelCRLStatus) DeepCopyInto(out *FabricCAChannelCRLStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCAChannelCRLStatus.
+func (in *FabricCAChannelCRLStatus) DeepCopy() *FabricCAChannelCRLStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCAChannelCRLStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCAClientAuth) DeepCopyInto(out *FabricCAClientAuth) {
 	*out = *in
@@ -396,6 +573,11 @@ func (in *FabricCACrypto) DeepCopy() *FabricCACrypto {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCADatabase) DeepCopyInto(out *FabricCADatabase) {
 	*out = *in
+	if in.DatasourceRef != nil {
+		in, out := &in.DatasourceRef, &out.DatasourceRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCADatabase.
@@ -408,9 +590,29 @@ func (in *FabricCADatabase) DeepCopy() *FabricCADatabase {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCAIdemix) DeepCopyInto(out *FabricCAIdemix) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCAIdemix.
+func (in *FabricCAIdemix) DeepCopy() *FabricCAIdemix {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCAIdemix)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCAIdentity) DeepCopyInto(out *FabricCAIdentity) {
 	*out = *in
+	if in.PassRef != nil {
+		in, out := &in.PassRef, &out.PassRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Attrs = in.Attrs
 }
 
@@ -443,6 +645,11 @@ func (in *FabricCAIdentityAttrs) DeepCopy() *FabricCAIdentityAttrs {
 func (in *FabricCAIntermediate) DeepCopyInto(out *FabricCAIntermediate) {
 	*out = *in
 	out.ParentServer = in.ParentServer
+	if in.ParentRef != nil {
+		in, out := &in.ParentRef, &out.ParentRef
+		*out = new(FabricCAParentRef)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCAIntermediate.
@@ -529,8 +736,18 @@ func (in *FabricCAItemConf) DeepCopyInto(out *FabricCAItemConf) {
 	in.CSR.DeepCopyInto(&out.CSR)
 	out.CRL = in.CRL
 	in.Registry.DeepCopyInto(&out.Registry)
-	out.Intermediate = in.Intermediate
-	out.BCCSP = in.BCCSP
+	in.Intermediate.DeepCopyInto(&out.Intermediate)
+	in.BCCSP.DeepCopyInto(&out.BCCSP)
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(FabricCALDAP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Signing != nil {
+		in, out := &in.Signing, &out.Signing
+		*out = new(FabricCASigning)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CA != nil {
 		in, out := &in.CA, &out.CA
 		*out = new(FabricCACrypto)
@@ -553,6 +770,117 @@ func (in *FabricCAItemConf) DeepCopy() *FabricCAItemConf {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCALDAP) DeepCopyInto(out *FabricCALDAP) {
+	*out = *in
+	if in.BindPasswordRef != nil {
+		in, out := &in.BindPasswordRef, &out.BindPasswordRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Catls = in.Catls
+	in.Attribute.DeepCopyInto(&out.Attribute)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCALDAP.
+func (in *FabricCALDAP) DeepCopy() *FabricCALDAP {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCALDAP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCALDAPAttribute) DeepCopyInto(out *FabricCALDAPAttribute) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Converters != nil {
+		in, out := &in.Converters, &out.Converters
+		*out = make([]FabricCALDAPConverter, len(*in))
+		copy(*out, *in)
+	}
+	if in.Maps != nil {
+		in, out := &in.Maps, &out.Maps
+		*out = make(map[string]FabricCALDAPMap, len(*in))
+		for key, val := range *in {
+			var outVal []FabricCALDAPMapEntry
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(FabricCALDAPMap, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCALDAPAttribute.
+func (in *FabricCALDAPAttribute) DeepCopy() *FabricCALDAPAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCALDAPAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCALDAPConverter) DeepCopyInto(out *FabricCALDAPConverter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCALDAPConverter.
+func (in *FabricCALDAPConverter) DeepCopy() *FabricCALDAPConverter {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCALDAPConverter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in FabricCALDAPMap) DeepCopyInto(out *FabricCALDAPMap) {
+	{
+		in := &in
+		*out = make(FabricCALDAPMap, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCALDAPMap.
+func (in FabricCALDAPMap) DeepCopy() FabricCALDAPMap {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCALDAPMap)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCALDAPMapEntry) DeepCopyInto(out *FabricCALDAPMapEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCALDAPMapEntry.
+func (in *FabricCALDAPMapEntry) DeepCopy() *FabricCALDAPMapEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCALDAPMapEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCAList) DeepCopyInto(out *FabricCAList) {
 	*out = *in
@@ -635,13 +963,35 @@ func (in *FabricCANames) DeepCopy() *FabricCANames {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCAParentRef) DeepCopyInto(out *FabricCAParentRef) {
+	*out = *in
+	if in.EnrollsecretRef != nil {
+		in, out := &in.EnrollsecretRef, &out.EnrollsecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCAParentRef.
+func (in *FabricCAParentRef) DeepCopy() *FabricCAParentRef {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCAParentRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCARegistry) DeepCopyInto(out *FabricCARegistry) {
 	*out = *in
 	if in.Identities != nil {
 		in, out := &in.Identities, &out.Identities
 		*out = make([]FabricCAIdentity, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -655,6 +1005,69 @@ func (in *FabricCARegistry) DeepCopy() *FabricCARegistry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCASigning) DeepCopyInto(out *FabricCASigning) {
+	*out = *in
+	in.Default.DeepCopyInto(&out.Default)
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make(map[string]FabricCASigningProfile, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCASigning.
+func (in *FabricCASigning) DeepCopy() *FabricCASigning {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCASigning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCASigningCAConstraint) DeepCopyInto(out *FabricCASigningCAConstraint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCASigningCAConstraint.
+func (in *FabricCASigningCAConstraint) DeepCopy() *FabricCASigningCAConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCASigningCAConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricCASigningProfile) DeepCopyInto(out *FabricCASigningProfile) {
+	*out = *in
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CAConstraint != nil {
+		in, out := &in.CAConstraint, &out.CAConstraint
+		*out = new(FabricCASigningCAConstraint)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCASigningProfile.
+func (in *FabricCASigningProfile) DeepCopy() *FabricCASigningProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricCASigningProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricCASpec) DeepCopyInto(out *FabricCASpec) {
 	*out = *in
@@ -668,20 +1081,30 @@ func (in *FabricCASpec) DeepCopyInto(out *FabricCASpec) {
 		*out = new(FabricIstio)
 		(*in).DeepCopyInto(*out)
 	}
-	out.Database = in.Database
+	in.Database.DeepCopyInto(&out.Database)
 	if in.Hosts != nil {
 		in, out := &in.Hosts, &out.Hosts
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 	out.Service = in.Service
-	out.TLS = in.TLS
+	in.TLS.DeepCopyInto(&out.TLS)
 	in.CA.DeepCopyInto(&out.CA)
 	in.TLSCA.DeepCopyInto(&out.TLSCA)
 	in.Cors.DeepCopyInto(&out.Cors)
 	in.Resources.DeepCopyInto(&out.Resources)
 	out.Storage = in.Storage
 	in.Metrics.DeepCopyInto(&out.Metrics)
+	if in.Idemix != nil {
+		in, out := &in.Idemix, &out.Idemix
+		*out = new(FabricCAIdemix)
+		**out = **in
+	}
+	if in.CRLPropagation != nil {
+		in, out := &in.CRLPropagation, &out.CRLPropagation
+		*out = new(FabricCACRLPropagation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCASpec.
@@ -719,6 +1142,15 @@ func (in *FabricCAStatus) DeepCopyInto(out *FabricCAStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CRLPropagation != nil {
+		in, out := &in.CRLPropagation, &out.CRLPropagation
+		*out = make([]FabricCAChannelCRLStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.CRLCheckTime != nil {
+		in, out := &in.CRLCheckTime, &out.CRLCheckTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCAStatus.
@@ -750,6 +1182,11 @@ func (in *FabricCASubject) DeepCopy() *FabricCASubject {
 func (in *FabricCATLSConf) DeepCopyInto(out *FabricCATLSConf) {
 	*out = *in
 	out.Subject = in.Subject
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerTLS)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricCATLSConf.
@@ -874,6 +1311,11 @@ func (in *FabricOrdererNodeSpec) DeepCopyInto(out *FabricOrdererNodeSpec) {
 		*out = new(FabricIstio)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BCCSP != nil {
+		in, out := &in.BCCSP, &out.BCCSP
+		*out = new(BCCSP)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricOrdererNodeSpec.
@@ -935,6 +1377,21 @@ func (in *FabricOrderingService) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricOrderingServiceChannelStatus) DeepCopyInto(out *FabricOrderingServiceChannelStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricOrderingServiceChannelStatus.
+func (in *FabricOrderingServiceChannelStatus) DeepCopy() *FabricOrderingServiceChannelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricOrderingServiceChannelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricOrderingServiceList) DeepCopyInto(out *FabricOrderingServiceList) {
 	*out = *in
@@ -980,7 +1437,7 @@ func (in *FabricOrderingServiceSpec) DeepCopyInto(out *FabricOrderingServiceSpec
 	}
 	out.Service = in.Service
 	out.Storage = in.Storage
-	out.SystemChannel = in.SystemChannel
+	in.SystemChannel.DeepCopyInto(&out.SystemChannel)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricOrderingServiceSpec.
@@ -1003,6 +1460,11 @@ func (in *FabricOrderingServiceStatus) DeepCopyInto(out *FabricOrderingServiceSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SystemChannel != nil {
+		in, out := &in.SystemChannel, &out.SystemChannel
+		*out = new(FabricOrderingServiceChannelStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricOrderingServiceStatus.
@@ -1045,6 +1507,11 @@ func (in *FabricPeer) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FabricPeerCouchDB) DeepCopyInto(out *FabricPeerCouchDB) {
 	*out = *in
+	if in.PasswordRef != nil {
+		in, out := &in.PasswordRef, &out.PasswordRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricPeerCouchDB.
@@ -1165,7 +1632,7 @@ func (in *FabricPeerSpec) DeepCopyInto(out *FabricPeerSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.Gossip = in.Gossip
-	out.CouchDB = in.CouchDB
+	in.CouchDB.DeepCopyInto(&out.CouchDB)
 	in.Secret.DeepCopyInto(&out.Secret)
 	out.Service = in.Service
 	out.Storage = in.Storage
@@ -1177,6 +1644,11 @@ func (in *FabricPeerSpec) DeepCopyInto(out *FabricPeerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.BCCSP != nil {
+		in, out := &in.BCCSP, &out.BCCSP
+		*out = new(BCCSP)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricPeerSpec.
@@ -1260,6 +1732,36 @@ func (in *FabricTLSCACrypto) DeepCopy() *FabricTLSCACrypto {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedCrypto) DeepCopyInto(out *GeneratedCrypto) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedCrypto.
+func (in *GeneratedCrypto) DeepCopy() *GeneratedCrypto {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedCrypto)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HSMVolume) DeepCopyInto(out *HSMVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HSMVolume.
+func (in *HSMVolume) DeepCopy() *HSMVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(HSMVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OrdererCapabilities) DeepCopyInto(out *OrdererCapabilities) {
 	*out = *in
@@ -1278,7 +1780,7 @@ func (in *OrdererCapabilities) DeepCopy() *OrdererCapabilities {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OrdererEnrollment) DeepCopyInto(out *OrdererEnrollment) {
 	*out = *in
-	out.Component = in.Component
+	in.Component.DeepCopyInto(&out.Component)
 	in.TLS.DeepCopyInto(&out.TLS)
 }
 
@@ -1374,6 +1876,11 @@ func (in *OrdererService) DeepCopy() *OrdererService {
 func (in *OrdererSystemChannel) DeepCopyInto(out *OrdererSystemChannel) {
 	*out = *in
 	out.Config = in.Config
+	if in.ConfigUpdate != nil {
+		in, out := &in.ConfigUpdate, &out.ConfigUpdate
+		*out = new(OrdererSystemChannelConfigUpdate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrdererSystemChannel.
@@ -1386,6 +1893,22 @@ func (in *OrdererSystemChannel) DeepCopy() *OrdererSystemChannel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrdererSystemChannelConfigUpdate) DeepCopyInto(out *OrdererSystemChannelConfigUpdate) {
+	*out = *in
+	in.NetworkConfigRef.DeepCopyInto(&out.NetworkConfigRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrdererSystemChannelConfigUpdate.
+func (in *OrdererSystemChannelConfigUpdate) DeepCopy() *OrdererSystemChannelConfigUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(OrdererSystemChannelConfigUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PeerService) DeepCopyInto(out *PeerService) {
 	*out = *in
@@ -1405,6 +1928,16 @@ func (in *PeerService) DeepCopy() *PeerService {
 func (in *Secret) DeepCopyInto(out *Secret) {
 	*out = *in
 	in.Enrollment.DeepCopyInto(&out.Enrollment)
+	if in.Crypto != nil {
+		in, out := &in.Crypto, &out.Crypto
+		*out = new(Crypto)
+		**out = **in
+	}
+	if in.Generated != nil {
+		in, out := &in.Generated, &out.Generated
+		*out = new(GeneratedCrypto)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Secret.
@@ -1473,7 +2006,22 @@ func (in *Storage) DeepCopy() *Storage {
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
 	out.Catls = in.Catls
+	if in.CARef != nil {
+		in, out := &in.CARef, &out.CARef
+		*out = new(CARef)
+		**out = **in
+	}
 	in.Csr.DeepCopyInto(&out.Csr)
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerTLS)
+		**out = **in
+	}
+	if in.EnrollsecretRef != nil {
+		in, out := &in.EnrollsecretRef, &out.EnrollsecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLS.
@@ -1485,3 +2033,57 @@ func (in *TLS) DeepCopy() *TLS {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuth) DeepCopyInto(out *VaultAuth) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuth.
+func (in *VaultAuth) DeepCopy() *VaultAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultKeyStore) DeepCopyInto(out *VaultKeyStore) {
+	*out = *in
+	out.Catls = in.Catls
+	in.Auth.DeepCopyInto(&out.Auth)
+	out.PKI = in.PKI
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultKeyStore.
+func (in *VaultKeyStore) DeepCopy() *VaultKeyStore {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultKeyStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultPKI) DeepCopyInto(out *VaultPKI) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPKI.
+func (in *VaultPKI) DeepCopy() *VaultPKI {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultPKI)
+	in.DeepCopyInto(out)
+	return out
+}
+
@@ -57,3 +57,48 @@ Generate mysql chart name
 {{- define "mysql.fullname" -}}
 {{- printf "%s-%s" .Release.Name "mysql" | trunc 63 | trimSuffix "-" -}}
 {{- end -}}
+
+{{/*
+Volumes, init container and mounts giving the CA server access to the PKCS11 library and token.
+*/}}
+{{- define "hlf-ca.hsmVolumes" -}}
+{{- if .Values.hsm.enabled }}
+{{- if .Values.hsm.libraryImage }}
+        - name: hsm-library
+          emptyDir: {}
+{{- end }}
+{{- if .Values.hsm.volume.claimName }}
+        - name: hsm-data
+          persistentVolumeClaim:
+            claimName: {{ .Values.hsm.volume.claimName }}
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-ca.hsmInitContainers" -}}
+{{- if and .Values.hsm.enabled .Values.hsm.libraryImage }}
+      initContainers:
+        - name: hsm-library
+          image: {{ .Values.hsm.libraryImage }}
+          command:
+            - sh
+            - -c
+            - cp -r {{ dir .Values.hsm.library }}/. /hsm-library/
+          volumeMounts:
+            - mountPath: /hsm-library
+              name: hsm-library
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-ca.hsmVolumeMounts" -}}
+{{- if .Values.hsm.enabled }}
+{{- if .Values.hsm.libraryImage }}
+            - mountPath: {{ dir .Values.hsm.library }}
+              name: hsm-library
+{{- end }}
+{{- if .Values.hsm.volume.claimName }}
+            - mountPath: {{ .Values.hsm.volume.mountPath }}
+              name: hsm-data
+{{- end }}
+{{- end }}
+{{- end -}}
@@ -44,3 +44,61 @@ release: {{ .Release.Name | quote }}
 chart: {{ include "hlf-ordnode.chart" . }}
 {{- end -}}
 
+
+{{/*
+Volumes, init container, environment and mounts giving the orderer access to the PKCS11 library and token.
+*/}}
+{{- define "hlf-ordnode.hsmVolumes" -}}
+{{- if .Values.hsm.enabled }}
+{{- if .Values.hsm.libraryImage }}
+        - name: hsm-library
+          emptyDir: {}
+{{- end }}
+{{- if .Values.hsm.volume.claimName }}
+        - name: hsm-data
+          persistentVolumeClaim:
+            claimName: {{ .Values.hsm.volume.claimName }}
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-ordnode.hsmInitContainers" -}}
+{{- if and .Values.hsm.enabled .Values.hsm.libraryImage }}
+      initContainers:
+        - name: hsm-library
+          image: {{ .Values.hsm.libraryImage }}
+          command:
+            - sh
+            - -c
+            - cp -r {{ dir .Values.hsm.library }}/. /hsm-library/
+          volumeMounts:
+            - mountPath: /hsm-library
+              name: hsm-library
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-ordnode.hsmEnv" -}}
+{{- if .Values.hsm.enabled }}
+            - name: ORDERER_GENERAL_BCCSP_PKCS11_PIN
+              valueFrom:
+                secretKeyRef:
+                  name: {{ .Values.hsm.pinSecret.name }}
+                  key: {{ .Values.hsm.pinSecret.key }}
+{{- with .Values.hsm.env }}
+{{ toYaml . | indent 12 }}
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-ordnode.hsmVolumeMounts" -}}
+{{- if .Values.hsm.enabled }}
+{{- if .Values.hsm.libraryImage }}
+            - mountPath: {{ dir .Values.hsm.library }}
+              name: hsm-library
+{{- end }}
+{{- if .Values.hsm.volume.claimName }}
+            - mountPath: {{ .Values.hsm.volume.mountPath }}
+              name: hsm-data
+{{- end }}
+{{- end }}
+{{- end -}}
@@ -43,3 +43,61 @@ heritage: {{ .Release.Service | quote }}
 release: {{ .Release.Name | quote }}
 chart: {{ include "hlf-peer.chart" . }}
 {{- end -}}
+
+{{/*
+Volumes, init container, environment and mounts giving the peer access to the PKCS11 library and token.
+*/}}
+{{- define "hlf-peer.hsmVolumes" -}}
+{{- if .Values.hsm.enabled }}
+{{- if .Values.hsm.libraryImage }}
+        - name: hsm-library
+          emptyDir: {}
+{{- end }}
+{{- if .Values.hsm.volume.claimName }}
+        - name: hsm-data
+          persistentVolumeClaim:
+            claimName: {{ .Values.hsm.volume.claimName }}
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-peer.hsmInitContainers" -}}
+{{- if and .Values.hsm.enabled .Values.hsm.libraryImage }}
+      initContainers:
+        - name: hsm-library
+          image: {{ .Values.hsm.libraryImage }}
+          command:
+            - sh
+            - -c
+            - cp -r {{ dir .Values.hsm.library }}/. /hsm-library/
+          volumeMounts:
+            - mountPath: /hsm-library
+              name: hsm-library
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-peer.hsmEnv" -}}
+{{- if .Values.hsm.enabled }}
+            - name: CORE_PEER_BCCSP_PKCS11_PIN
+              valueFrom:
+                secretKeyRef:
+                  name: {{ .Values.hsm.pinSecret.name }}
+                  key: {{ .Values.hsm.pinSecret.key }}
+{{- with .Values.hsm.env }}
+{{ toYaml . | indent 12 }}
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "hlf-peer.hsmVolumeMounts" -}}
+{{- if .Values.hsm.enabled }}
+{{- if .Values.hsm.libraryImage }}
+            - mountPath: {{ dir .Values.hsm.library }}
+              name: hsm-library
+{{- end }}
+{{- if .Values.hsm.volume.claimName }}
+            - mountPath: {{ .Values.hsm.volume.mountPath }}
+              name: hsm-data
+{{- end }}
+{{- end }}
+{{- end -}}
@@ -3,9 +3,6 @@ package ca
 import (
 	"context"
 	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
-	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/base64"
@@ -17,15 +14,17 @@ import (
 	"helm.sh/helm/v3/pkg/cli"
 	"k8s.io/kubernetes/pkg/api/v1/pod"
 
-	"math/big"
 	"net"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
 	"github.com/kfsoftware/hlf-operator/controllers/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -41,8 +40,12 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // FabricCAReconciler reconciles a FabricCA object
@@ -95,62 +98,57 @@ func getExistingTLSCrypto(client *kubernetes.Clientset, chartName string, namesp
 	return crt, key, nil
 }
 
-func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, namespace string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, namespace string) (*x509.Certificate, *ecdsa.PrivateKey, string, error) {
 	secretName := fmt.Sprintf("%s--msp-cryptomaterial", chartName)
 
 	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 	tlsKeyData := secret.Data["keyfile"]
 	tlsCrtData := secret.Data["certfile"]
-	key, err := parseECDSAPrivateKey(tlsKeyData)
+	chain := string(secret.Data["chainfile"])
+	crt, err := parseX509Certificate(tlsCrtData)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
-	crt, err := parseX509Certificate(tlsCrtData)
+	if len(tlsKeyData) == 0 {
+		// the key is kept in the HSM
+		return crt, nil, chain, nil
+	}
+	key, err := parseECDSAPrivateKey(tlsKeyData)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
-	return crt, key, nil
+	return crt, key, chain, nil
 }
 
-func getExistingSignTLSCrypto(client *kubernetes.Clientset, chartName string, namespace string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+func getExistingSignTLSCrypto(client *kubernetes.Clientset, chartName string, namespace string) (*x509.Certificate, *ecdsa.PrivateKey, string, error) {
 	secretName := fmt.Sprintf("%s--msp-tls-cryptomaterial", chartName)
 
 	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 	tlsKeyData := secret.Data["keyfile"]
 	tlsCrtData := secret.Data["certfile"]
-	key, err := parseECDSAPrivateKey(tlsKeyData)
+	chain := string(secret.Data["chainfile"])
+	crt, err := parseX509Certificate(tlsCrtData)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
-	crt, err := parseX509Certificate(tlsCrtData)
+	if len(tlsKeyData) == 0 {
+		// the key is kept in the HSM
+		return crt, nil, chain, nil
+	}
+	key, err := parseECDSAPrivateKey(tlsKeyData)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
-	return crt, key, nil
+	return crt, key, chain, nil
 }
 
-// compute Subject Key Identifier
-func computeSKI(privKey *ecdsa.PrivateKey) []byte {
-	// Marshall the public key
-	raw := elliptic.Marshal(privKey.Curve, privKey.PublicKey.X, privKey.PublicKey.Y)
-
-	// Hash it
-	hash := sha256.Sum256(raw)
-	return hash[:]
-}
 func CreateDefaultTLSCA(clientSet *kubernetes.Clientset, spec hlfv1alpha1.FabricCASpec) (*x509.Certificate, *ecdsa.PrivateKey, error) {
-	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
-	if err != nil {
-		log.Fatalf("Failed to generate serial number: %v", err)
-		return nil, nil, err
-	}
 	k8sIP, err := utils.GetPublicIPKubernetes(clientSet)
 	if err != nil {
 		return nil, nil, err
@@ -171,81 +169,105 @@ func CreateDefaultTLSCA(clientSet *kubernetes.Clientset, spec hlfv1alpha1.Fabric
 			ips = append(ips, addr)
 		}
 	}
-	caPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	x509Cert := &x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
+	return certs.CreateCA(
+		pkix.Name{
 			Organization:       []string{spec.TLS.Subject.O},
 			Country:            []string{spec.TLS.Subject.C},
 			Locality:           []string{spec.TLS.Subject.L},
 			OrganizationalUnit: []string{spec.TLS.Subject.OU},
 			StreetAddress:      []string{spec.TLS.Subject.ST},
 		},
-		NotBefore:             time.Now().AddDate(0, 0, -1),
-		NotAfter:              time.Now().AddDate(10, 0, 0),
-		IsCA:                  true,
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
-		BasicConstraintsValid: true,
-		DNSNames:              dnsNames,
-		IPAddresses:           ips,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		SubjectKeyId:          computeSKI(caPrivKey),
-	}
+		dnsNames,
+		ips,
+	)
+}
+
+func CreateDefaultCA(conf hlfv1alpha1.FabricCAItemConf, pkcs11Params *certs.PKCS11Params) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	subject := pkix.Name{
+		Organization:       []string{conf.Subject.O},
+		Country:            []string{conf.Subject.C},
+		Locality:           []string{conf.Subject.L},
+		OrganizationalUnit: []string{conf.Subject.OU},
+		StreetAddress:      []string{conf.Subject.ST},
+		CommonName:         conf.Subject.CN,
+	}
+	if pkcs11Params != nil {
+		crt, err := certs.CreatePKCS11CA(pkcs11Params, subject, nil, nil)
+		return crt, nil, err
+	}
+	return certs.CreateCA(subject, nil, nil)
+}
 
-	caBytes, err := x509.CreateCertificate(rand.Reader, x509Cert, x509Cert, &caPrivKey.PublicKey, caPrivKey)
+// enrollIntermediateCA registers and enrolls the CA against its parent, returning the CA certificate, its key and
+// the chain from the root of the parent down to the CA
+func enrollIntermediateCA(client *kubernetes.Clientset, conf *hlfv1alpha1.FabricCA, item hlfv1alpha1.FabricCAItemConf, parent *certs.ParentCAParams, pkcs11Params *certs.PKCS11Params) (*x509.Certificate, *ecdsa.PrivateKey, string, error) {
+	ref := parent.Ref
+	enrollSecret, err := utils.ResolveSecretValue(client, conf.Namespace, ref.Enrollsecret, ref.EnrollsecretRef)
 	if err != nil {
-		return nil, nil, err
-	}
-	crt, err := x509.ParseCertificate(caBytes)
+		return nil, nil, "", err
+	}
+	identity := ref.Identity
+	if identity == "" {
+		identity = fmt.Sprintf("%s-%s-%s", conf.Namespace, conf.Name, item.Name)
+	}
+	crypto, err := certs.EnrollIntermediateCA(certs.EnrollIntermediateCARequest{
+		TLSCert:      parent.TLSCert,
+		URL:          parent.URL,
+		Name:         parent.Name,
+		MSPID:        conf.Name,
+		EnrollID:     ref.Enrollid,
+		EnrollSecret: enrollSecret,
+		User:         identity,
+		PKCS11:       pkcs11Params,
+	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
-	return crt, caPrivKey, nil
+	chain := string(utils.EncodeX509Certificate(crypto.RootCert)) +
+		crypto.EncodedIntermediateCerts() +
+		string(utils.EncodeX509Certificate(crypto.Cert))
+	return crypto.Cert, crypto.Key, chain, nil
 }
 
-func CreateDefaultCA(conf hlfv1alpha1.FabricCAItemConf) (*x509.Certificate, *ecdsa.PrivateKey, error) {
-	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
-	if err != nil {
-		log.Fatalf("Failed to generate serial number: %v", err)
-		return nil, nil, err
+// getCAStatusCerts returns the root and the intermediate certificates of the CA, the CA is its own root when it
+// has no chain
+func getCAStatusCerts(crt *x509.Certificate, chain string) (string, string, error) {
+	if strings.TrimSpace(chain) == "" {
+		return string(utils.EncodeX509Certificate(crt)), "", nil
 	}
-	caPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rootCrt, intermediateCerts, err := certs.ParseCAChain([]byte(chain))
 	if err != nil {
-		return nil, nil, err
+		return "", "", err
 	}
-
-	signCA := &x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			Organization:       []string{conf.Subject.O},
-			Country:            []string{conf.Subject.C},
-			Locality:           []string{conf.Subject.L},
-			OrganizationalUnit: []string{conf.Subject.OU},
-			StreetAddress:      []string{conf.Subject.ST},
-			CommonName:         conf.Subject.CN,
-		},
-		NotBefore:             time.Now().AddDate(0, 0, -1),
-		NotAfter:              time.Now().AddDate(10, 0, 0),
-		IsCA:                  true,
-		SubjectKeyId:          computeSKI(caPrivKey),
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageKeyEncipherment,
-		BasicConstraintsValid: true,
+	if rootCrt.Equal(crt) {
+		return string(utils.EncodeX509Certificate(rootCrt)), "", nil
 	}
-	caBytes, err := x509.CreateCertificate(rand.Reader, signCA, signCA, &caPrivKey.PublicKey, caPrivKey)
-	if err != nil {
-		return nil, nil, err
+	included := false
+	for _, intermediateCert := range intermediateCerts {
+		if intermediateCert.Equal(crt) {
+			included = true
+			break
+		}
 	}
-	crt, err := x509.ParseCertificate(caBytes)
+	if !included {
+		intermediateCerts = append(intermediateCerts, crt)
+	}
+	return string(utils.EncodeX509Certificate(rootCrt)), certs.EncodeCertificates(intermediateCerts), nil
+}
+
+// encodePrivateKey returns an empty PEM when the key is kept in the HSM
+func encodePrivateKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	if key == nil {
+		return []byte{}, nil
+	}
+	encodedPK, err := x509.MarshalPKCS8PrivateKey(key)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return crt, caPrivKey, nil
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: encodedPK,
+	}), nil
 }
 
 func newActionCfg(log logr.Logger, clusterCfg *rest.Config, namespace string) (*action.Configuration, error) {
@@ -269,7 +291,7 @@ func actionLogger(logger logr.Logger) func(format string, v ...interface{}) {
 		logger.Info(fmt.Sprintf(format, v...))
 	}
 }
-func mapCRDItemConfToChart(conf hlfv1alpha1.FabricCAItemConf) FabricCAChartItemConf {
+func mapCRDItemConfToChart(client *kubernetes.Clientset, namespace string, conf hlfv1alpha1.FabricCAItemConf) (FabricCAChartItemConf, error) {
 	names := []FabricCAChartNames{}
 	for _, name := range conf.CSR.Names {
 		names = append(names, FabricCAChartNames{
@@ -282,9 +304,13 @@ func mapCRDItemConfToChart(conf hlfv1alpha1.FabricCAItemConf) FabricCAChartItemC
 	}
 	identities := []FabricCAChartIdentity{}
 	for _, identity := range conf.Registry.Identities {
+		pass, err := utils.ResolveSecretValue(client, namespace, identity.Pass, identity.PassRef)
+		if err != nil {
+			return FabricCAChartItemConf{}, err
+		}
 		identities = append(identities, FabricCAChartIdentity{
 			Name:        identity.Name,
-			Pass:        identity.Pass,
+			Pass:        pass,
 			Type:        identity.Type,
 			Affiliation: identity.Affiliation,
 			Attrs: FabricCAChartIdentityAttrs{
@@ -333,7 +359,190 @@ func mapCRDItemConfToChart(conf hlfv1alpha1.FabricCAItemConf) FabricCAChartItemC
 			},
 		},
 	}
-	return item
+	pkcs11Params, err := certs.GetPKCS11Params(client, namespace, conf.BCCSP.PKCS11Config())
+	if err != nil {
+		return FabricCAChartItemConf{}, err
+	}
+	if pkcs11Params != nil {
+		item.BCCSP.PKCS11 = &FabricCAChartBCCSPPKCS11{
+			Library:  pkcs11Params.Library,
+			Label:    pkcs11Params.Label,
+			Pin:      pkcs11Params.Pin,
+			Hash:     pkcs11Params.Hash,
+			Security: pkcs11Params.Security,
+		}
+	}
+	if conf.Signing != nil {
+		item.Signing = mapCRDSigningToChart(*conf.Signing)
+	}
+	if conf.LDAP != nil {
+		item.LDAP, err = mapCRDLDAPToChart(*conf.LDAP)
+		if err != nil {
+			return FabricCAChartItemConf{}, err
+		}
+	}
+	return item, nil
+}
+
+// ldapBindPasswordPlaceholder is replaced in the pod by the password of the bind DN, so that it's only read from
+// its Secret and never rendered in the configuration of the CA
+const ldapBindPasswordPlaceholder = "__LDAP_BIND_PASSWORD__"
+
+var (
+	postgresDBNameRegexp = regexp.MustCompile(`(^|\s)dbname=(\S+)`)
+	mysqlDBNameRegexp    = regexp.MustCompile(`\)/([^/?]+)`)
+)
+
+// getTLSCADatasource returns the datasource of the TLS CA, it runs in the same server as the CA, so it keeps its
+// registry and certificates in its own database with the _tls suffix, or a -tls file for sqlite3
+func getTLSCADatasource(dbType string, datasource string) (string, error) {
+	switch dbType {
+	case "postgres":
+		if strings.HasPrefix(datasource, "postgres://") || strings.HasPrefix(datasource, "postgresql://") {
+			dsURL, err := url.Parse(datasource)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid postgres datasource")
+			}
+			dbName := strings.TrimPrefix(dsURL.Path, "/")
+			if dbName == "" {
+				return "", errors.New("the postgres datasource doesn't set the database name")
+			}
+			dsURL.Path = "/" + dbName + "_tls"
+			return dsURL.String(), nil
+		}
+		if !postgresDBNameRegexp.MatchString(datasource) {
+			return "", errors.New("the postgres datasource doesn't set dbname")
+		}
+		return postgresDBNameRegexp.ReplaceAllString(datasource, "${1}dbname=${2}_tls"), nil
+	case "mysql":
+		if !mysqlDBNameRegexp.MatchString(datasource) {
+			return "", errors.New("the mysql datasource doesn't set the database name")
+		}
+		return mysqlDBNameRegexp.ReplaceAllString(datasource, ")/${1}_tls"), nil
+	default:
+		if datasource == "" {
+			return "", nil
+		}
+		if strings.HasSuffix(datasource, ".db") {
+			return strings.TrimSuffix(datasource, ".db") + "-tls.db", nil
+		}
+		return datasource + "-tls", nil
+	}
+}
+
+// mapCRDLDAPToChart adds the bind DN to the URL of the LDAP server, as expected by the Fabric CA, the chart mounts
+// the password from its Secret
+func mapCRDLDAPToChart(ldap hlfv1alpha1.FabricCALDAP) (*FabricCAChartLDAP, error) {
+	ldapURL, err := url.Parse(ldap.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid LDAP url")
+	}
+	var bindPasswordSecret *FabricCAChartSecretKeyRef
+	if ldap.BindDN != "" {
+		if ldap.BindPasswordRef != nil {
+			ldapURL.User = url.UserPassword(ldap.BindDN, ldapBindPasswordPlaceholder)
+			bindPasswordSecret = &FabricCAChartSecretKeyRef{
+				Name: ldap.BindPasswordRef.Name,
+				Key:  ldap.BindPasswordRef.Key,
+			}
+		} else {
+			ldapURL.User = url.User(ldap.BindDN)
+		}
+	}
+	chartLDAP := &FabricCAChartLDAP{
+		URL:                ldapURL.String(),
+		BindPasswordSecret: bindPasswordSecret,
+		UserFilter:         ldap.UserFilter,
+		GroupFilter:        ldap.GroupFilter,
+		Attribute: FabricCAChartLDAPAttribute{
+			Names:      ldap.Attribute.Names,
+			Converters: []FabricCAChartLDAPNameValue{},
+			Maps:       map[string][]FabricCAChartLDAPNameValue{},
+		},
+	}
+	if chartLDAP.UserFilter == "" {
+		chartLDAP.UserFilter = "(uid=%s)"
+	}
+	if chartLDAP.GroupFilter == "" {
+		chartLDAP.GroupFilter = "(memberUid=%s)"
+	}
+	if len(chartLDAP.Attribute.Names) == 0 {
+		chartLDAP.Attribute.Names = []string{"uid", "member"}
+	}
+	for _, converter := range ldap.Attribute.Converters {
+		chartLDAP.Attribute.Converters = append(chartLDAP.Attribute.Converters, FabricCAChartLDAPNameValue{
+			Name:  converter.Name,
+			Value: converter.Value,
+		})
+	}
+	for name, entries := range ldap.Attribute.Maps {
+		chartEntries := []FabricCAChartLDAPNameValue{}
+		for _, entry := range entries {
+			chartEntries = append(chartEntries, FabricCAChartLDAPNameValue{
+				Name:  entry.Name,
+				Value: entry.Value,
+			})
+		}
+		chartLDAP.Attribute.Maps[name] = chartEntries
+	}
+	if ldap.Catls.Cacert != "" {
+		caCert, err := base64.StdEncoding.DecodeString(ldap.Catls.Cacert)
+		if err != nil {
+			return nil, err
+		}
+		chartLDAP.CACert = string(caCert)
+	}
+	return chartLDAP, nil
+}
+
+// defaultSigningProfiles are the profiles of the Fabric CA server configuration, the operator enrolls intermediate CAs
+// and TLS certificates with them so they're kept when the signing section is customized
+func defaultSigningProfiles() map[string]FabricCAChartSigningProfile {
+	return map[string]FabricCAChartSigningProfile{
+		"ca": {
+			Usage:        []string{"cert sign", "crl sign"},
+			Expiry:       "43800h",
+			CAConstraint: &FabricCAChartSigningCAConstraint{IsCA: true},
+		},
+		"tls": {
+			Usage:  []string{"signing", "key encipherment", "server auth", "client auth", "key agreement"},
+			Expiry: "8760h",
+		},
+	}
+}
+func mapCRDSigningProfileToChart(profile hlfv1alpha1.FabricCASigningProfile, defaultUsage []string) FabricCAChartSigningProfile {
+	chartProfile := FabricCAChartSigningProfile{
+		Usage:  profile.Usage,
+		Expiry: profile.Expiry,
+	}
+	if len(chartProfile.Usage) == 0 {
+		chartProfile.Usage = defaultUsage
+	}
+	if chartProfile.Expiry == "" {
+		chartProfile.Expiry = "8760h"
+	}
+	if profile.CAConstraint != nil {
+		chartProfile.CAConstraint = &FabricCAChartSigningCAConstraint{
+			IsCA:           profile.CAConstraint.IsCA,
+			MaxPathLen:     profile.CAConstraint.MaxPathLen,
+			MaxPathLenZero: profile.CAConstraint.MaxPathLenZero,
+		}
+	}
+	return chartProfile
+}
+func mapCRDSigningToChart(signing hlfv1alpha1.FabricCASigning) *FabricCAChartSigning {
+	profiles := defaultSigningProfiles()
+	for name, profile := range signing.Profiles {
+		defaultUsage := []string{"digital signature"}
+		if defaultProfile, ok := profiles[name]; ok {
+			defaultUsage = defaultProfile.Usage
+		}
+		profiles[name] = mapCRDSigningProfileToChart(profile, defaultUsage)
+	}
+	return &FabricCAChartSigning{
+		Default:  mapCRDSigningProfileToChart(signing.Default, []string{"digital signature"}),
+		Profiles: profiles,
+	}
 }
 func parseCrypto(key string, cert string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
 	keyBytes, err := base64.StdEncoding.DecodeString(key)
@@ -354,32 +563,92 @@ func parseCrypto(key string, cert string) (*x509.Certificate, *ecdsa.PrivateKey,
 	}
 	return x509Cert, pk, nil
 }
-func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartName string, namespace string) (*FabricCAChart, error) {
+
+// getCertManagerHosts returns the SANs of the CA TLS certificate, including the <name>.<namespace> host intermediate
+// CAs reach their parent on and the public IP of the cluster the operator enrolls through caRef on
+func getCertManagerHosts(conf *hlfv1alpha1.FabricCA, k8sIP string) []string {
+	hosts := []string{fmt.Sprintf("%s.%s", conf.Name, conf.Namespace)}
+	hosts = append(hosts, conf.Spec.Hosts...)
+	if k8sIP != "" && !utils.Contains(hosts, k8sIP) {
+		hosts = append(hosts, k8sIP)
+	}
+	return hosts
+}
+
+// getReplicas returns the number of pods of the CA, CAs created before replicas existed run a single pod
+func getReplicas(spec hlfv1alpha1.FabricCASpec) int {
+	if spec.Replicas < 1 {
+		return 1
+	}
+	return spec.Replicas
+}
+
+// validateReplicas checks that all the replicas of the CA share the same database, the signing keys are
+// shared through the Secrets of the release
+func validateReplicas(spec hlfv1alpha1.FabricCASpec) error {
+	if getReplicas(spec) == 1 {
+		return nil
+	}
+	if spec.Database.Type != "postgres" && spec.Database.Type != "mysql" {
+		return errors.Errorf("a CA with %d replicas requires a postgres or mysql database, got %s", spec.Replicas, spec.Database.Type)
+	}
+	if spec.Database.DatasourceRef == nil {
+		return errors.Errorf("a CA with %d replicas requires the datasource in a Secret through db.datasourceRef", spec.Replicas)
+	}
+	return nil
+}
+
+func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartName string, namespace string, parentCA *certs.ParentCAParams, parentTLSCA *certs.ParentCAParams) (*FabricCAChart, error) {
 	spec := conf.Spec
-	tlsCert, tlsKey, err := getExistingTLSCrypto(client, chartName, namespace)
-	if err != nil {
-		tlsCert, tlsKey, err = CreateDefaultTLSCA(client, spec)
+	var tlsCert *x509.Certificate
+	var tlsKey *ecdsa.PrivateKey
+	var tlsIntermediateCerts string
+	var err error
+	if spec.TLS.CertManager != nil {
+		tlsCrypto, err := certs.GetCertManagerCryptoMaterial(client, namespace, conf.Name)
 		if err != nil {
 			return nil, err
 		}
+		tlsCert, tlsKey = tlsCrypto.Cert, tlsCrypto.Key
+		tlsIntermediateCerts = tlsCrypto.EncodedIntermediateCerts()
+	} else {
+		tlsCert, tlsKey, err = getExistingTLSCrypto(client, chartName, namespace)
+		if err != nil {
+			tlsCert, tlsKey, err = CreateDefaultTLSCA(client, spec)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	signPKCS11Params, err := certs.GetPKCS11Params(client, namespace, spec.CA.BCCSP.PKCS11Config())
+	if err != nil {
+		return nil, err
+	}
+	tlsCAPKCS11Params, err := certs.GetPKCS11Params(client, namespace, spec.TLSCA.BCCSP.PKCS11Config())
+	if err != nil {
+		return nil, err
 	}
-	signCert, signKey, err := getExistingSignCrypto(client, chartName, namespace)
+	signCert, signKey, signChain, err := getExistingSignCrypto(client, chartName, namespace)
 	if err != nil {
-		if conf.Spec.CA.CA != nil && conf.Spec.CA.CA.Key != "" && conf.Spec.CA.CA.Cert != "" {
+		if parentCA != nil {
+			signCert, signKey, signChain, err = enrollIntermediateCA(client, conf, spec.CA, parentCA, signPKCS11Params)
+		} else if conf.Spec.CA.CA != nil && conf.Spec.CA.CA.Key != "" && conf.Spec.CA.CA.Cert != "" {
 			signCert, signKey, err = parseCrypto(conf.Spec.CA.CA.Key, conf.Spec.CA.CA.Cert)
 		} else {
-			signCert, signKey, err = CreateDefaultCA(spec.CA)
+			signCert, signKey, err = CreateDefaultCA(spec.CA, signPKCS11Params)
 		}
 		if err != nil {
 			return nil, err
 		}
 	}
-	caTLSSignCert, caTLSSignKey, err := getExistingSignTLSCrypto(client, chartName, namespace)
+	caTLSSignCert, caTLSSignKey, caTLSSignChain, err := getExistingSignTLSCrypto(client, chartName, namespace)
 	if err != nil {
-		if conf.Spec.TLSCA.CA != nil && conf.Spec.TLSCA.CA.Key != "" && conf.Spec.TLSCA.CA.Cert != "" {
+		if parentTLSCA != nil {
+			caTLSSignCert, caTLSSignKey, caTLSSignChain, err = enrollIntermediateCA(client, conf, spec.TLSCA, parentTLSCA, tlsCAPKCS11Params)
+		} else if conf.Spec.TLSCA.CA != nil && conf.Spec.TLSCA.CA.Key != "" && conf.Spec.TLSCA.CA.Cert != "" {
 			caTLSSignCert, caTLSSignKey, err = parseCrypto(conf.Spec.TLSCA.CA.Key, conf.Spec.TLSCA.CA.Cert)
 		} else {
-			caTLSSignCert, caTLSSignKey, err = CreateDefaultCA(spec.TLSCA)
+			caTLSSignCert, caTLSSignKey, err = CreateDefaultCA(spec.TLSCA, tlsCAPKCS11Params)
 		}
 		if err != nil {
 			return nil, err
@@ -402,27 +671,19 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 		Type:  "CERTIFICATE",
 		Bytes: signCert.Raw,
 	})
-	signEncodedPK, err := x509.MarshalPKCS8PrivateKey(signKey)
+	signPEMEncodedPK, err := encodePrivateKey(signKey)
 	if err != nil {
 		return nil, err
 	}
-	signPEMEncodedPK := pem.EncodeToMemory(&pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: signEncodedPK,
-	})
 
 	caTLSSignCRTEncoded := pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: caTLSSignCert.Raw,
 	})
-	caTLSSignEncodedPK, err := x509.MarshalPKCS8PrivateKey(caTLSSignKey)
+	caTLSSignPEMEncodedPK, err := encodePrivateKey(caTLSSignKey)
 	if err != nil {
 		return nil, err
 	}
-	caTLSSignPEMEncodedPK := pem.EncodeToMemory(&pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: caTLSSignEncodedPK,
-	})
 	istioPort := 443
 	if spec.Istio != nil && spec.Istio.Port != 0 {
 		istioPort = spec.Istio.Port
@@ -434,17 +695,17 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 	msp := Msp{
 		Keyfile:        string(signPEMEncodedPK),
 		Certfile:       string(signCRTEncoded),
-		Chainfile:      "",
+		Chainfile:      signChain,
 		TLSCAKeyfile:   string(caTLSSignPEMEncodedPK),
 		TLSCACertfile:  string(caTLSSignCRTEncoded),
-		TLSCAChainfile: "",
+		TLSCAChainfile: caTLSSignChain,
 		TlsKeyFile:     string(tlsPEMEncodedPK),
-		TlsCertFile:    string(tlsCRTEncoded),
+		TlsCertFile:    string(tlsCRTEncoded) + tlsIntermediateCerts,
 	}
-	if conf.Spec.CA.CA != nil {
+	if parentCA == nil && conf.Spec.CA.CA != nil {
 		msp.Chainfile = conf.Spec.CA.CA.Chain
 	}
-	if conf.Spec.TLSCA.CA != nil {
+	if parentTLSCA == nil && conf.Spec.TLSCA.CA != nil {
 		msp.TLSCAChainfile = conf.Spec.TLSCA.CA.Chain
 	}
 	var serviceMonitor ServiceMonitor
@@ -466,7 +727,45 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 		}
 	}
 
+	datasource, err := utils.ResolveSecretValue(client, namespace, spec.Database.Datasource, spec.Database.DatasourceRef)
+	if err != nil {
+		return nil, err
+	}
+	tlsDatasource, err := getTLSCADatasource(spec.Database.Type, datasource)
+	if err != nil {
+		return nil, err
+	}
+	caConf, err := mapCRDItemConfToChart(client, namespace, spec.CA)
+	if err != nil {
+		return nil, err
+	}
+	tlsCAConf, err := mapCRDItemConfToChart(client, namespace, spec.TLSCA)
+	if err != nil {
+		return nil, err
+	}
+	var hsm HSM
+	// both CAs run in the same container, so they share the library of the first one using a HSM
+	for _, pkcs11Conf := range []*hlfv1alpha1.BCCSPPKCS11{spec.CA.BCCSP.PKCS11Config(), spec.TLSCA.BCCSP.PKCS11Config()} {
+		if pkcs11Conf == nil {
+			continue
+		}
+		hsm = HSM{
+			Enabled:      true,
+			Library:      pkcs11Conf.Library,
+			LibraryImage: pkcs11Conf.LibraryImage,
+			Env:          pkcs11Conf.Env,
+		}
+		if pkcs11Conf.Volume != nil {
+			hsm.Volume = HSMVolume{
+				ClaimName: pkcs11Conf.Volume.ClaimName,
+				MountPath: pkcs11Conf.Volume.MountPath,
+			}
+		}
+		break
+	}
 	var c = FabricCAChart{
+		HSM:              hsm,
+		Idemix:           mapCRDIdemixToChart(conf),
 		FullNameOverride: conf.Name,
 		Istio: Istio{
 			Port:  istioPort,
@@ -482,8 +781,10 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 			Type: string(spec.Service.ServiceType),
 			Port: 7054,
 		},
+		Replicas: getReplicas(spec),
 		Persistence: Persistence{
-			Enabled:      true,
+			// the database of a CA with several replicas is external, there's nothing to keep in the pods
+			Enabled:      getReplicas(spec) == 1,
 			Annotations:  map[string]string{},
 			StorageClass: spec.Storage.StorageClass,
 			AccessMode:   string(spec.Storage.AccessMode),
@@ -491,8 +792,9 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 		},
 		Msp: msp,
 		Database: Database{
-			Type:       spec.Database.Type,
-			Datasource: spec.Database.Datasource,
+			Type:          spec.Database.Type,
+			Datasource:    datasource,
+			TLSDatasource: tlsDatasource,
 		},
 		Resources: Resources{
 			Requests: Requests{
@@ -519,8 +821,8 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 			},
 		},
 
-		Ca:    mapCRDItemConfToChart(spec.CA),
-		TLSCA: mapCRDItemConfToChart(spec.TLSCA),
+		Ca:    caConf,
+		TLSCA: tlsCAConf,
 		Cors: Cors{
 			Enabled: spec.Cors.Enabled,
 			Origins: spec.Cors.Origins,
@@ -530,13 +832,15 @@ func GetConfig(conf *hlfv1alpha1.FabricCA, client *kubernetes.Clientset, chartNa
 }
 
 type Status struct {
-	Status    hlfv1alpha1.DeploymentStatus
-	TlsCert   string
-	CACert    string
-	TLSCACert string
-	NodeURL   string
-	NodePort  int
-	NodeHost  string
+	Status                 hlfv1alpha1.DeploymentStatus
+	TlsCert                string
+	CACert                 string
+	CAIntermediateCerts    string
+	TLSCACert              string
+	TLSCAIntermediateCerts string
+	NodeURL                string
+	NodePort               int
+	NodeHost               string
 }
 
 func GetServiceName(releaseName string) string {
@@ -565,29 +869,28 @@ func GetCAState(clientSet *kubernetes.Clientset, ca *hlfv1alpha1.FabricCA, relea
 	if err != nil {
 		return nil, err
 	}
-	if len(pods.Items) > 0 {
+	// the CA is running once every replica of the current revision is ready, so that the status
+	// doesn't depend on the pod listed last when there are several replicas
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	if dep.Status.UpdatedReplicas >= replicas && dep.Status.ReadyReplicas >= replicas {
+		r.Status = hlfv1alpha1.RunningStatus
+	} else {
 		for _, item := range pods.Items {
 			if pod.IsPodReadyConditionTrue(item.Status) {
-				r.Status = hlfv1alpha1.RunningStatus
-			} else {
-				switch item.Status.Phase {
-				case corev1.PodPending:
-					r.Status = hlfv1alpha1.PendingStatus
-				case corev1.PodFailed:
-					r.Status = hlfv1alpha1.FailedStatus
-				case corev1.PodSucceeded:
-				case corev1.PodRunning:
-				case corev1.PodUnknown:
+				continue
+			}
+			switch item.Status.Phase {
+			case corev1.PodFailed:
+				r.Status = hlfv1alpha1.FailedStatus
+			case corev1.PodUnknown:
+				if r.Status != hlfv1alpha1.FailedStatus {
 					r.Status = hlfv1alpha1.UnknownStatus
 				}
 			}
 		}
-	} else {
-		if dep.Status.ReadyReplicas == *dep.Spec.Replicas {
-			r.Status = hlfv1alpha1.RunningStatus
-		} else {
-			r.Status = hlfv1alpha1.PendingStatus
-		}
 	}
 	svcName := GetServiceName(releaseName)
 	svc, err := clientSet.CoreV1().Services(ns).Get(ctx, svcName, v1.GetOptions{})
@@ -603,16 +906,22 @@ func GetCAState(clientSet *kubernetes.Clientset, ca *hlfv1alpha1.FabricCA, relea
 		return nil, err
 	}
 	r.TlsCert = string(utils.EncodeX509Certificate(tlsCrt))
-	signCrt, _, err := getExistingSignCrypto(clientSet, releaseName, ns)
+	signCrt, _, signChain, err := getExistingSignCrypto(clientSet, releaseName, ns)
 	if err != nil {
 		return nil, err
 	}
-	r.CACert = string(utils.EncodeX509Certificate(signCrt))
-	tlsCACrt, _, err := getExistingSignTLSCrypto(clientSet, releaseName, ns)
+	r.CACert, r.CAIntermediateCerts, err = getCAStatusCerts(signCrt, signChain)
+	if err != nil {
+		return nil, err
+	}
+	tlsCACrt, _, tlsCAChain, err := getExistingSignTLSCrypto(clientSet, releaseName, ns)
+	if err != nil {
+		return nil, err
+	}
+	r.TLSCACert, r.TLSCAIntermediateCerts, err = getCAStatusCerts(tlsCACrt, tlsCAChain)
 	if err != nil {
 		return nil, err
 	}
-	r.TLSCACert = string(utils.EncodeX509Certificate(tlsCACrt))
 	return r, nil
 }
 
@@ -694,6 +1003,43 @@ func Reconcile(
 			return ctrl.Result{}, err
 		}
 	}
+	clientSet, err := utils.GetClientKubeWithConf(r.Config)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if hlf.Spec.TLS.CertManager != nil {
+		k8sIP, err := utils.GetPublicIPKubernetes(clientSet)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		err = certs.EnsureCertManagerCertificate(ctx, r.Client, r.Scheme, hlf, hlf.Name, hlf.Spec.TLS.CertManager, getCertManagerHosts(hlf, k8sIP))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	err = validateReplicas(hlf.Spec)
+	if err != nil {
+		setConditionStatus(hlf, hlfv1alpha1.FailedStatus, false, err, false)
+		return r.updateCRStatusOrFailReconcile(ctx, r.Log, hlf)
+	}
+	var idemixKeys map[string][]byte
+	if hlf.Spec.Idemix != nil {
+		idemixKeys, err = ensureIdemixIssuerKeys(ctx, r, hlf)
+		if err != nil {
+			setConditionStatus(hlf, hlfv1alpha1.FailedStatus, false, err, false)
+			return r.updateCRStatusOrFailReconcile(ctx, r.Log, hlf)
+		}
+	}
+	parentCA, err := certs.ResolveParentCA(ctx, r.Client, clientSet, ns, hlf.Spec.CA.Intermediate.ParentRef, false)
+	if err != nil {
+		setConditionStatus(hlf, hlfv1alpha1.FailedStatus, false, err, false)
+		return r.updateCRStatusOrFailReconcile(ctx, r.Log, hlf)
+	}
+	parentTLSCA, err := certs.ResolveParentCA(ctx, r.Client, clientSet, ns, hlf.Spec.TLSCA.Intermediate.ParentRef, true)
+	if err != nil {
+		setConditionStatus(hlf, hlfv1alpha1.FailedStatus, false, err, false)
+		return r.updateCRStatusOrFailReconcile(ctx, r.Log, hlf)
+	}
 
 	cmdStatus := action.NewStatus(cfg)
 	exists := true
@@ -708,10 +1054,6 @@ func Reconcile(
 		}
 	}
 	log.Debugf("Release %s exists=%v", releaseName, exists)
-	clientSet, err := utils.GetClientKubeWithConf(r.Config)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
 
 	if exists {
 		// update
@@ -734,13 +1076,24 @@ func Reconcile(
 		fca.Status.TlsCert = s.TlsCert
 		fca.Status.TLSCACert = s.TLSCACert
 		fca.Status.CACert = s.CACert
+		fca.Status.CAIntermediateCerts = s.CAIntermediateCerts
+		fca.Status.TLSCAIntermediateCerts = s.TLSCAIntermediateCerts
 		fca.Status.NodePort = s.NodePort
+		fca.Status.IdemixIssuerPublicKey = idemixIssuerPublicKey(idemixKeys)
+		fca.Status.IdemixRevocationPublicKey = string(idemixKeys[certs.IdemixRevocationPublicKey])
+		if hlf.Spec.CRLPropagation == nil {
+			fca.Status.CRLPropagation = nil
+			fca.Status.CRLCheckTime = nil
+		} else if now := time.Now(); s.Status == hlfv1alpha1.RunningStatus && crlCheckDue(ctx, r, hlf, now) {
+			fca.Status.CRLPropagation = propagateCRL(ctx, r, hlf, s.NodeURL, s.TlsCert)
+			fca.Status.CRLCheckTime = &v1.Time{Time: now}
+		}
 		fca.Status.Conditions.SetCondition(status.Condition{
 			Type:               status.ConditionType(s.Status),
 			Status:             "True",
 			LastTransitionTime: v1.Time{},
 		})
-		c, err := GetConfig(hlf, clientSet, releaseName, req.Namespace)
+		c, err := GetConfig(hlf, clientSet, releaseName, req.Namespace, parentCA, parentTLSCA)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -779,6 +1132,15 @@ func Reconcile(
 				RequeueAfter: 10 * time.Second,
 			}, nil
 		case hlfv1alpha1.RunningStatus:
+			if crlPropagationFailed(fca.Status.CRLPropagation) {
+				return utils.RetryChannelUpdate(fmt.Sprintf("CRL propagation of CA %s", fca.Name)), nil
+			}
+			if fca.Spec.CRLPropagation != nil {
+				// the CRL is generated again to find new revocations
+				return ctrl.Result{
+					RequeueAfter: crlCheckInterval,
+				}, nil
+			}
 			return ctrl.Result{}, nil
 		default:
 			return ctrl.Result{
@@ -796,7 +1158,7 @@ func Reconcile(
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		c, err := GetConfig(hlf, clientSet, name, req.Namespace)
+		c, err := GetConfig(hlf, clientSet, name, req.Namespace, parentCA, parentTLSCA)
 		if err != nil {
 			reqLogger.Error(err, "Failed to get config")
 			return ctrl.Result{}, err
@@ -900,5 +1262,24 @@ func (r *FabricCAReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hlfv1alpha1.FabricCA{}).
 		Owns(&appsv1.Deployment{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.SecretReferrersMapper("CAs", r.List, func() runtime.Object { return &hlfv1alpha1.FabricCAList{} })},
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.findCAsForCRLConfigMap)},
+			builder.WithPredicates(crlConfigMapPredicate),
+		).
 		Complete(r)
 }
+
+// findCAsForCRLConfigMap enqueues the CA whose CRL was written to the ConfigMap, so that it's propagated to the
+// channels without waiting for the next check
+func (r *FabricCAReconciler) findCAsForCRLConfigMap(o handler.MapObject) []reconcile.Request {
+	caName := crlConfigMapCA(o.Meta)
+	if caName == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: *caName}}
+}
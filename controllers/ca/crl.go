@@ -0,0 +1,295 @@
+package ca
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// crlConfigMapKey is the key of the CRL in the ConfigMaps written by kubectl hlf ca revoke/gencrl --crl-configmap
+	crlConfigMapKey = "crl.pem"
+	// CRLConfigMapLabel is set to the name of the CA in the ConfigMaps with its CRL, a change of them triggers the
+	// propagation of the CRL without waiting for the next check
+	CRLConfigMapLabel = "hlf.kungfusoftware.es/crl"
+	// crlCheckInterval is how often the CRL of the CA is generated to find new revocations
+	crlCheckInterval = 5 * time.Minute
+)
+
+// crlCheckDue returns whether the CRL of the CA has to be checked, either because the last check is older than
+// crlCheckInterval, it failed more than utils.ChannelUpdateRetryInterval ago or a newer CRL was written to a ConfigMap
+// of the CA
+func crlCheckDue(ctx context.Context, r *FabricCAReconciler, ca *hlfv1alpha1.FabricCA, now time.Time) bool {
+	lastCheck := ca.Status.CRLCheckTime
+	if lastCheck == nil {
+		return true
+	}
+	elapsed := now.Sub(lastCheck.Time)
+	if elapsed >= crlCheckInterval {
+		return true
+	}
+	if crlPropagationFailed(ca.Status.CRLPropagation) && elapsed >= utils.ChannelUpdateRetryInterval {
+		return true
+	}
+	configMaps := &corev1.ConfigMapList{}
+	err := r.List(ctx, configMaps, client.InNamespace(ca.Namespace), client.MatchingLabels{CRLConfigMapLabel: ca.Name})
+	if err != nil {
+		log.Errorf("Failed to list the CRL ConfigMaps of CA %s: %v", ca.Name, err)
+		return false
+	}
+	for _, configMap := range configMaps.Items {
+		crl, err := x509.ParseCRL([]byte(configMap.Data[crlConfigMapKey]))
+		if err != nil {
+			continue
+		}
+		if crl.TBSCertList.ThisUpdate.After(lastCheck.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateCRL returns the CRL of the CA, generated with the first identity of its registry allowed to
+func generateCRL(r *FabricCAReconciler, ca *hlfv1alpha1.FabricCA, url string, tlsCert string) (*pkix.CertificateList, error) {
+	for _, identity := range ca.Spec.CA.Registry.Identities {
+		if !identity.Attrs.GenCRL {
+			continue
+		}
+		secret, err := utils.ResolveSecretValue(r.ClientSet, ca.Namespace, identity.Pass, identity.PassRef)
+		if err != nil {
+			return nil, err
+		}
+		crlPem, err := certs.GenCRL(certs.RegistrarParams{
+			TLSCert:      tlsCert,
+			URL:          url,
+			Name:         ca.Spec.CA.Name,
+			MSPID:        ca.Spec.CRLPropagation.MSPID,
+			EnrollID:     identity.Name,
+			EnrollSecret: secret,
+		}, certs.GenCRLRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParseCRL(crlPem)
+	}
+	return nil, errors.New("no identity of the registry of the CA has hf.GenCRL")
+}
+
+// getOrgChannels returns the channels joined by the peers of the organization in the network config
+func getOrgChannels(sdk *fabsdk.FabricSDK, resClient *resmgmt.Client, mspID string) ([]string, error) {
+	configBackend, err := sdk.Config()
+	if err != nil {
+		return nil, err
+	}
+	endpointConfig, err := fabImpl.ConfigFromBackend(configBackend)
+	if err != nil {
+		return nil, err
+	}
+	var peers []string
+	for _, org := range endpointConfig.NetworkConfig().Organizations {
+		if org.MSPID == mspID {
+			peers = append(peers, org.Peers...)
+		}
+	}
+	if len(peers) == 0 {
+		return nil, errors.Errorf("the network config has no peers of %s to find its channels", mspID)
+	}
+	channels := map[string]bool{}
+	for _, peer := range peers {
+		response, err := resClient.QueryChannels(resmgmt.WithTargetEndpoints(peer))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query the channels of peer %s", peer)
+		}
+		for _, channel := range response.Channels {
+			channels[channel.ChannelId] = true
+		}
+	}
+	var channelIDs []string
+	for channelID := range channels {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+	return channelIDs, nil
+}
+
+// sameRevokedCertificates returns whether both CRLs revoke the same certificates
+func sameRevokedCertificates(a *pkix.CertificateList, b *pkix.CertificateList) bool {
+	if len(a.TBSCertList.RevokedCertificates) != len(b.TBSCertList.RevokedCertificates) {
+		return false
+	}
+	serials := map[string]bool{}
+	for _, revoked := range a.TBSCertList.RevokedCertificates {
+		serials[revoked.SerialNumber.String()] = true
+	}
+	for _, revoked := range b.TBSCertList.RevokedCertificates {
+		if !serials[revoked.SerialNumber.String()] {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeRevocationList replaces the CRLs of the same issuer in the revocation list, it returns false when the
+// revocation list has a CRL of the issuer as recent or revoking the same certificates, the CA generates a new CRL
+// every time it's asked to
+func mergeRevocationList(revocationList []*pkix.CertificateList, crl *pkix.CertificateList) ([]*pkix.CertificateList, bool) {
+	issuer := crl.TBSCertList.Issuer.String()
+	merged := []*pkix.CertificateList{crl}
+	for _, existing := range revocationList {
+		if existing.TBSCertList.Issuer.String() != issuer {
+			merged = append(merged, existing)
+			continue
+		}
+		if !existing.TBSCertList.ThisUpdate.Before(crl.TBSCertList.ThisUpdate) || sameRevokedCertificates(existing, crl) {
+			return revocationList, false
+		}
+	}
+	return merged, true
+}
+
+// updateChannelCRL sets the CRL in the MSP of the organization in the channel, it returns the ID of the transaction
+// or an empty string when the channel is up to date
+func updateChannelCRL(resClient *resmgmt.Client, channelID string, mspID string, crl *pkix.CertificateList) (string, error) {
+	block, err := resClient.QueryConfigBlockFromOrderer(channelID)
+	if err != nil {
+		return "", err
+	}
+	channelConfig, err := resource.ExtractConfigFromBlock(block)
+	if err != nil {
+		return "", err
+	}
+	cftxGen := configtx.New(channelConfig)
+	org := cftxGen.Application().Organization(mspID)
+	if org == nil {
+		return "", errors.Errorf("organization %s not found in the channel", mspID)
+	}
+	msp, err := org.MSP()
+	if err != nil {
+		return "", err
+	}
+	revocationList, changed := mergeRevocationList(msp.RevocationList, crl)
+	if !changed {
+		return "", nil
+	}
+	msp.RevocationList = revocationList
+	err = org.SetMSP(msp)
+	if err != nil {
+		return "", err
+	}
+	configUpdateBytes, err := cftxGen.ComputeMarshaledUpdate(channelID)
+	if err != nil {
+		return "", err
+	}
+	return utils.SaveChannelConfigUpdate(resClient, channelID, configUpdateBytes)
+}
+
+// propagateCRL generates the CRL of the CA and updates the RevocationList of the organization in every channel it
+// belongs to, the status of a channel is reported as failed when its config can't be updated
+func propagateCRL(ctx context.Context, r *FabricCAReconciler, ca *hlfv1alpha1.FabricCA, url string, tlsCert string) []hlfv1alpha1.FabricCAChannelCRLStatus {
+	propagation := ca.Spec.CRLPropagation
+	previous := map[string]hlfv1alpha1.FabricCAChannelCRLStatus{}
+	for _, channelStatus := range ca.Status.CRLPropagation {
+		previous[channelStatus.Channel] = channelStatus
+	}
+	failAll := func(err error) []hlfv1alpha1.FabricCAChannelCRLStatus {
+		statuses := []hlfv1alpha1.FabricCAChannelCRLStatus{}
+		for _, channelStatus := range ca.Status.CRLPropagation {
+			channelStatus.Status = hlfv1alpha1.CRLPropagationFailed
+			channelStatus.Message = err.Error()
+			statuses = append(statuses, channelStatus)
+		}
+		if len(statuses) == 0 {
+			// the channels aren't known yet, the error is reported without a channel
+			statuses = append(statuses, hlfv1alpha1.FabricCAChannelCRLStatus{
+				Status:  hlfv1alpha1.CRLPropagationFailed,
+				Message: err.Error(),
+			})
+		}
+		return statuses
+	}
+	crl, err := generateCRL(r, ca, url, tlsCert)
+	if err != nil {
+		return failAll(errors.Wrapf(err, "failed to generate the CRL"))
+	}
+	sdk, resClient, err := utils.NewChannelAdminClient(ctx, r.Client, ca.Namespace, propagation.NetworkConfigRef, propagation.User, propagation.MSPID)
+	if err != nil {
+		return failAll(err)
+	}
+	defer sdk.Close()
+	channels, err := getOrgChannels(sdk, resClient, propagation.MSPID)
+	if err != nil {
+		return failAll(err)
+	}
+	statuses := make([]hlfv1alpha1.FabricCAChannelCRLStatus, len(channels))
+	for i, channelID := range channels {
+		channelStatus := hlfv1alpha1.FabricCAChannelCRLStatus{
+			Channel: channelID,
+			Status:  hlfv1alpha1.CRLPropagationSynced,
+			TxID:    previous[channelID].TxID,
+		}
+		txID, err := updateChannelCRL(resClient, channelID, propagation.MSPID, crl)
+		if err != nil {
+			log.Errorf("Failed to propagate the CRL of CA %s to channel %s: %v", ca.Name, channelID, err)
+			channelStatus.Status = hlfv1alpha1.CRLPropagationFailed
+			channelStatus.Message = err.Error()
+		} else if txID != "" {
+			log.Infof("CRL of CA %s propagated to channel %s, txID=%s", ca.Name, channelID, txID)
+			channelStatus.TxID = txID
+		}
+		statuses[i] = channelStatus
+	}
+	return statuses
+}
+
+func crlPropagationFailed(statuses []hlfv1alpha1.FabricCAChannelCRLStatus) bool {
+	for _, channelStatus := range statuses {
+		if channelStatus.Status == hlfv1alpha1.CRLPropagationFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// crlConfigMapCA returns the CA whose CRL the ConfigMap holds, nil when it isn't labeled with CRLConfigMapLabel
+func crlConfigMapCA(meta v1.Object) *types.NamespacedName {
+	name, ok := meta.GetLabels()[CRLConfigMapLabel]
+	if !ok || name == "" {
+		return nil
+	}
+	return &types.NamespacedName{Namespace: meta.GetNamespace(), Name: name}
+}
+
+// crlConfigMapPredicate only lets through the ConfigMaps with the CRL of a CA
+var crlConfigMapPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return crlConfigMapCA(e.Meta) != nil
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return crlConfigMapCA(e.MetaNew) != nil
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return crlConfigMapCA(e.Meta) != nil
+	},
+}
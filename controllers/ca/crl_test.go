@@ -0,0 +1,93 @@
+package ca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestCRL(t *testing.T, issuer string, thisUpdate time.Time, serials ...int64) *pkix.CertificateList {
+	g := NewWithT(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: issuer},
+		NotBefore:             thisUpdate.Add(-time.Hour),
+		NotAfter:              thisUpdate.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	g.Expect(err).NotTo(HaveOccurred())
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: thisUpdate})
+	}
+	crlDer, err := cert.CreateCRL(rand.Reader, key, revoked, thisUpdate, thisUpdate.Add(time.Hour))
+	g.Expect(err).NotTo(HaveOccurred())
+	crl, err := x509.ParseDERCRL(crlDer)
+	g.Expect(err).NotTo(HaveOccurred())
+	return crl
+}
+
+func TestSameRevokedCertificates(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	g.Expect(sameRevokedCertificates(newTestCRL(t, "ca", now, 1, 2), newTestCRL(t, "ca", now, 2, 1))).To(BeTrue())
+	g.Expect(sameRevokedCertificates(newTestCRL(t, "ca", now), newTestCRL(t, "ca", now))).To(BeTrue())
+	g.Expect(sameRevokedCertificates(newTestCRL(t, "ca", now, 1), newTestCRL(t, "ca", now, 1, 2))).To(BeFalse())
+	g.Expect(sameRevokedCertificates(newTestCRL(t, "ca", now, 1, 3), newTestCRL(t, "ca", now, 1, 2))).To(BeFalse())
+}
+
+func TestMergeRevocationList(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	existing := newTestCRL(t, "ca", now, 1)
+	other := newTestCRL(t, "other-ca", now, 5)
+
+	tests := []struct {
+		name        string
+		crl         *pkix.CertificateList
+		wantChanged bool
+		wantLen     int
+	}{
+		{name: "newer CRL revoking more certificates", crl: newTestCRL(t, "ca", now.Add(time.Minute), 1, 2), wantChanged: true, wantLen: 2},
+		{name: "newer CRL revoking the same certificates", crl: newTestCRL(t, "ca", now.Add(time.Minute), 1), wantChanged: false, wantLen: 2},
+		{name: "older CRL", crl: newTestCRL(t, "ca", now.Add(-time.Minute), 1, 2), wantChanged: false, wantLen: 2},
+		{name: "CRL as recent", crl: newTestCRL(t, "ca", now, 1, 2), wantChanged: false, wantLen: 2},
+		{name: "CRL of a new issuer", crl: newTestCRL(t, "new-ca", now, 7), wantChanged: true, wantLen: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			merged, changed := mergeRevocationList([]*pkix.CertificateList{existing, other}, tt.crl)
+			g.Expect(changed).To(Equal(tt.wantChanged))
+			g.Expect(merged).To(HaveLen(tt.wantLen))
+			g.Expect(merged).To(ContainElement(other))
+			if tt.wantChanged {
+				g.Expect(merged).To(ContainElement(tt.crl))
+			} else {
+				g.Expect(merged).To(ContainElement(existing))
+			}
+		})
+	}
+}
+
+func TestCRLConfigMapCA(t *testing.T) {
+	g := NewWithT(t)
+	labeled := &v1.ObjectMeta{Name: "org1-crl", Namespace: "default", Labels: map[string]string{CRLConfigMapLabel: "org1-ca"}}
+	g.Expect(crlConfigMapCA(labeled)).To(Equal(&types.NamespacedName{Namespace: "default", Name: "org1-ca"}))
+	g.Expect(crlConfigMapCA(&v1.ObjectMeta{Name: "other", Namespace: "default"})).To(BeNil())
+}
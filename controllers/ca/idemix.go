@@ -0,0 +1,80 @@
+package ca
+
+import (
+	"context"
+	"encoding/base64"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var idemixIssuerKeyNames = []string{
+	certs.IdemixIssuerPublicKey,
+	certs.IdemixIssuerSecretKey,
+	certs.IdemixRevocationPublicKey,
+	certs.IdemixRevocationPrivateKey,
+}
+
+// ensureIdemixIssuerKeys returns the Idemix issuer keys of the CA, they are generated in a Secret owned by the CA
+// unless spec.idemix.issuerKeysSecret is set
+func ensureIdemixIssuerKeys(ctx context.Context, r *FabricCAReconciler, ca *hlfv1alpha1.FabricCA) (map[string][]byte, error) {
+	secretName := ca.IdemixSecretName()
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: ca.Namespace, Name: secretName}, secret)
+	if apierrors.IsNotFound(err) && ca.Spec.Idemix.IssuerKeysSecret == "" {
+		keys, err := certs.GenerateIdemixIssuerKeys()
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      secretName,
+				Namespace: ca.Namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: keys,
+		}
+		err = controllerutil.SetControllerReference(ca, secret, r.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		err = r.Create(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+		return keys, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the Idemix issuer keys secret %s", secretName)
+	}
+	for _, key := range idemixIssuerKeyNames {
+		if len(secret.Data[key]) == 0 {
+			return nil, errors.Errorf("key %s not found in the Idemix issuer keys secret %s", key, secretName)
+		}
+	}
+	return secret.Data, nil
+}
+
+func mapCRDIdemixToChart(ca *hlfv1alpha1.FabricCA) *FabricCAChartIdemix {
+	if ca.Spec.Idemix == nil {
+		return nil
+	}
+	return &FabricCAChartIdemix{
+		IssuerKeysSecret:   ca.IdemixSecretName(),
+		RHPoolSize:         ca.Spec.Idemix.RHPoolSize,
+		NonceExpiration:    ca.Spec.Idemix.NonceExpiration,
+		NonceSweepInterval: ca.Spec.Idemix.NonceSweepInterval,
+	}
+}
+
+func idemixIssuerPublicKey(keys map[string][]byte) string {
+	if keys == nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(keys[certs.IdemixIssuerPublicKey])
+}
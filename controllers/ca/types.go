@@ -1,10 +1,13 @@
 package ca
 
+import corev1 "k8s.io/api/core/v1"
+
 type FabricCAChart struct {
 	Istio            Istio                 `json:"istio"`
 	FullNameOverride string                `json:"fullnameOverride"`
 	Image            Image                 `json:"image"`
 	Service          Service               `json:"service"`
+	Replicas         int                   `json:"replicas"`
 	Persistence      Persistence           `json:"persistence"`
 	Msp              Msp                   `json:"msp"`
 	Database         Database              `json:"db"`
@@ -19,6 +22,14 @@ type FabricCAChart struct {
 	TLSCA            FabricCAChartItemConf `json:"tlsCA"`
 	Cors             Cors                  `json:"cors"`
 	ServiceMonitor   ServiceMonitor        `json:"serviceMonitor"`
+	HSM              HSM                   `json:"hsm"`
+	Idemix           *FabricCAChartIdemix  `json:"idemix,omitempty"`
+}
+type FabricCAChartIdemix struct {
+	IssuerKeysSecret   string `json:"issuerKeysSecret"`
+	RHPoolSize         int    `json:"rhpoolsize"`
+	NonceExpiration    string `json:"nonceexpiration"`
+	NonceSweepInterval string `json:"noncesweepinterval"`
 }
 type ServiceMonitor struct {
 	Enabled           bool              `json:"enabled"`
@@ -48,11 +59,56 @@ type FabricCAChartItemConf struct {
 	Registry     FabricCAChartRegistry     `json:"registry"`
 	Intermediate FabricCAChartIntermediate `json:"intermediate"`
 	BCCSP        FabricCAChartBCCSP        `json:"bccsp"`
+	Signing      *FabricCAChartSigning     `json:"signing,omitempty"`
+	LDAP         *FabricCAChartLDAP        `json:"ldap,omitempty"`
 	Affiliations []Affiliation             `json:"affiliations"`
 }
+type FabricCAChartLDAP struct {
+	URL                string                     `json:"url"`
+	BindPasswordSecret *FabricCAChartSecretKeyRef `json:"bindPasswordSecret,omitempty"`
+	UserFilter         string                     `json:"userfilter"`
+	GroupFilter        string                     `json:"groupfilter"`
+	CACert             string                     `json:"cacert,omitempty"`
+	Attribute          FabricCAChartLDAPAttribute `json:"attribute"`
+}
+type FabricCAChartSecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+type FabricCAChartLDAPAttribute struct {
+	Names      []string                                `json:"names"`
+	Converters []FabricCAChartLDAPNameValue            `json:"converters"`
+	Maps       map[string][]FabricCAChartLDAPNameValue `json:"maps"`
+}
+type FabricCAChartLDAPNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+type FabricCAChartSigning struct {
+	Default  FabricCAChartSigningProfile            `json:"default"`
+	Profiles map[string]FabricCAChartSigningProfile `json:"profiles"`
+}
+type FabricCAChartSigningProfile struct {
+	Usage        []string                          `json:"usage"`
+	Expiry       string                            `json:"expiry"`
+	CAConstraint *FabricCAChartSigningCAConstraint `json:"caconstraint,omitempty"`
+}
+type FabricCAChartSigningCAConstraint struct {
+	IsCA           bool `json:"isca"`
+	MaxPathLen     int  `json:"maxpathlen"`
+	MaxPathLenZero bool `json:"maxpathlenzero"`
+}
 type FabricCAChartBCCSP struct {
-	Default string               `json:"default"`
-	SW      FabricCAChartBCCSPSW `json:"sw"`
+	Default string                    `json:"default"`
+	SW      FabricCAChartBCCSPSW      `json:"sw"`
+	PKCS11  *FabricCAChartBCCSPPKCS11 `json:"pkcs11,omitempty"`
+}
+type FabricCAChartBCCSPPKCS11 struct {
+	Library  string `json:"library"`
+	Label    string `json:"label"`
+	Pin      string `json:"pin"`
+	Hash     string `json:"hash"`
+	Security int    `json:"security"`
 }
 type FabricCAChartBCCSPSW struct {
 	Hash     string `json:"hash"`
@@ -190,8 +246,9 @@ type Mysql struct {
 	ConfigurationFiles ConfigurationFiles `json:"configurationFiles"`
 }
 type Database struct {
-	Type       string `json:"type"`
-	Datasource string `json:"datasource"`
+	Type          string `json:"type"`
+	Datasource    string `json:"datasource"`
+	TLSDatasource string `json:"tlsDatasource"`
 }
 
 type Names struct {
@@ -227,3 +284,15 @@ type NodeSelector struct {
 }
 type Affinity struct {
 }
+
+type HSM struct {
+	Enabled      bool            `json:"enabled"`
+	Library      string          `json:"library"`
+	LibraryImage string          `json:"libraryImage"`
+	Env          []corev1.EnvVar `json:"env"`
+	Volume       HSMVolume       `json:"volume"`
+}
+type HSMVolume struct {
+	ClaimName string `json:"claimName"`
+	MountPath string `json:"mountPath"`
+}
@@ -0,0 +1,148 @@
+package certs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func getReferencedCA(ctx context.Context, cl client.Client, namespace string, ref *hlfv1alpha1.CARef) (*hlfv1alpha1.FabricCA, error) {
+	ns := ref.Namespace
+	if ns == "" {
+		ns = namespace
+	}
+	ca := &hlfv1alpha1.FabricCA{}
+	err := cl.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ns}, ca)
+	if err != nil {
+		return nil, err
+	}
+	if ca.Status.TlsCert == "" {
+		return nil, fmt.Errorf("CA %s/%s has no TLS certificate in its status yet", ns, ref.Name)
+	}
+	return ca, nil
+}
+
+// getCAService returns the service of the FabricCA, named after its release
+func getCAService(ctx context.Context, clientSet kubernetes.Interface, ca *hlfv1alpha1.FabricCA) (*corev1.Service, error) {
+	svc, err := clientSet.CoreV1().Services(ca.Namespace).Get(ctx, ca.Name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("service of CA %s/%s has no ports", ca.Namespace, ca.Name)
+	}
+	return svc, nil
+}
+
+// getCAAddress returns the host and port the operator enrolls against the FabricCA on: the public IP of the cluster
+// and the node port of the CA service, so that it works when the operator runs outside of the cluster, or the service
+// host and port when the CA isn't exposed through a node port
+func getCAAddress(ctx context.Context, clientSet kubernetes.Interface, ca *hlfv1alpha1.FabricCA) (string, int, error) {
+	svc, err := getCAService(ctx, clientSet, ca)
+	if err != nil {
+		return "", 0, err
+	}
+	port := svc.Spec.Ports[0]
+	nodePort := int(port.NodePort)
+	if nodePort == 0 {
+		nodePort = ca.Status.NodePort
+	}
+	if nodePort != 0 {
+		k8sIP, err := utils.GetPublicIPKubernetes(clientSet)
+		if err != nil {
+			return "", 0, err
+		}
+		if k8sIP != "" {
+			return k8sIP, nodePort, nil
+		}
+	}
+	return fmt.Sprintf("%s.%s", svc.Name, svc.Namespace), int(port.Port), nil
+}
+
+// ResolveComponentCA fills the CA connection details of the component from the referenced FabricCA, if any
+func ResolveComponentCA(ctx context.Context, cl client.Client, clientSet kubernetes.Interface, namespace string, component *hlfv1alpha1.Component) error {
+	if component.CARef == nil {
+		return nil
+	}
+	ca, err := getReferencedCA(ctx, cl, namespace, component.CARef)
+	if err != nil {
+		return err
+	}
+	host, port, err := getCAAddress(ctx, clientSet, ca)
+	if err != nil {
+		return err
+	}
+	component.Cahost = host
+	component.Caport = port
+	component.Caname = ca.Spec.CA.Name
+	component.Catls = hlfv1alpha1.Catls{
+		Cacert: base64.StdEncoding.EncodeToString([]byte(ca.Status.TlsCert)),
+	}
+	return nil
+}
+
+// ResolveTLSCA fills the CA connection details of the TLS enrollment from the referenced FabricCA, if any
+func ResolveTLSCA(ctx context.Context, cl client.Client, clientSet kubernetes.Interface, namespace string, tls *hlfv1alpha1.TLS) error {
+	if tls.CARef == nil {
+		return nil
+	}
+	ca, err := getReferencedCA(ctx, cl, namespace, tls.CARef)
+	if err != nil {
+		return err
+	}
+	host, port, err := getCAAddress(ctx, clientSet, ca)
+	if err != nil {
+		return err
+	}
+	tls.Cahost = host
+	tls.Caport = port
+	tls.Caname = ca.Spec.TLSCA.Name
+	tls.Catls = hlfv1alpha1.Catls{
+		Cacert: base64.StdEncoding.EncodeToString([]byte(ca.Status.TlsCert)),
+	}
+	return nil
+}
+
+// ParentCAParams holds the connection details of the parent CA an intermediate CA is enrolled against
+type ParentCAParams struct {
+	URL     string
+	Name    string
+	TLSCert string
+	// registrar of the parent CA and identity of the intermediate CA
+	Ref *hlfv1alpha1.FabricCAParentRef
+}
+
+// ResolveParentCA returns the connection details of the parent FabricCA of an intermediate CA, the TLS CA of the
+// parent is used when tlsCA is set, nil when the CA isn't enrolled against a parent. The intermediate CA reaches its
+// parent from inside the cluster, through the service of the parent
+func ResolveParentCA(ctx context.Context, cl client.Client, clientSet kubernetes.Interface, namespace string, ref *hlfv1alpha1.FabricCAParentRef, tlsCA bool) (*ParentCAParams, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	ca, err := getReferencedCA(ctx, cl, namespace, &hlfv1alpha1.CARef{Name: ref.Name, Namespace: ref.Namespace})
+	if err != nil {
+		return nil, err
+	}
+	svc, err := getCAService(ctx, clientSet, ca)
+	if err != nil {
+		return nil, err
+	}
+	caName := ca.Spec.CA.Name
+	if tlsCA {
+		caName = ca.Spec.TLSCA.Name
+	}
+	return &ParentCAParams{
+		URL:     fmt.Sprintf("https://%s.%s:%d", svc.Name, svc.Namespace, svc.Spec.Ports[0].Port),
+		Name:    caName,
+		TLSCert: ca.Status.TlsCert,
+		Ref:     ref,
+	}, nil
+}
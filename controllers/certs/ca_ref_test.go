@@ -0,0 +1,107 @@
+package certs
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveCA(t *testing.T) {
+	newCA := func(nodePort int) *hlfv1alpha1.FabricCA {
+		ca := &hlfv1alpha1.FabricCA{ObjectMeta: v1.ObjectMeta{Name: "org1-ca", Namespace: "cas"}}
+		ca.Spec.CA.Name = "ca"
+		ca.Spec.TLSCA.Name = "tlsca"
+		ca.Status.TlsCert = "-----BEGIN CERTIFICATE-----"
+		ca.Status.NodePort = nodePort
+		return ca
+	}
+	newService := func(nodePort int32) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: v1.ObjectMeta{Name: "org1-ca", Namespace: "cas"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "https", Port: 7054, NodePort: nodePort}},
+			},
+		}
+	}
+	node := &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "node1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "172.18.0.2"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		ca         *hlfv1alpha1.FabricCA
+		objects    []runtime.Object
+		expectHost string
+		expectPort int
+	}{
+		{
+			name:       "node port service",
+			ca:         newCA(30954),
+			objects:    []runtime.Object{newService(30954), node},
+			expectHost: "172.18.0.2",
+			expectPort: 30954,
+		},
+		{
+			name:       "node port from the CA status",
+			ca:         newCA(30954),
+			objects:    []runtime.Object{newService(0), node},
+			expectHost: "172.18.0.2",
+			expectPort: 30954,
+		},
+		{
+			name:       "cluster service",
+			ca:         newCA(0),
+			objects:    []runtime.Object{newService(0), node},
+			expectHost: "org1-ca.cas",
+			expectPort: 7054,
+		},
+		{
+			name:       "no nodes",
+			ca:         newCA(30954),
+			objects:    []runtime.Object{newService(30954)},
+			expectHost: "org1-ca.cas",
+			expectPort: 7054,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := runtime.NewScheme()
+			g.Expect(hlfv1alpha1.AddToScheme(scheme)).To(Succeed())
+			cl := fake.NewFakeClientWithScheme(scheme, tt.ca)
+			clientSet := k8sfake.NewSimpleClientset(tt.objects...)
+			ref := &hlfv1alpha1.CARef{Name: "org1-ca", Namespace: "cas"}
+
+			component := &hlfv1alpha1.Component{CARef: ref}
+			err := ResolveComponentCA(context.Background(), cl, clientSet, "default", component)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(component.Cahost).To(Equal(tt.expectHost))
+			g.Expect(component.Caport).To(Equal(tt.expectPort))
+			g.Expect(component.Caname).To(Equal("ca"))
+			g.Expect(component.Catls.Cacert).To(Equal(base64.StdEncoding.EncodeToString([]byte(tt.ca.Status.TlsCert))))
+
+			tls := &hlfv1alpha1.TLS{CARef: ref}
+			err = ResolveTLSCA(context.Background(), cl, clientSet, "default", tls)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(tls.Cahost).To(Equal(tt.expectHost))
+			g.Expect(tls.Caport).To(Equal(tt.expectPort))
+			g.Expect(tls.Caname).To(Equal("tlsca"))
+
+			// intermediate CAs reach their parent from inside the cluster
+			parent, err := ResolveParentCA(context.Background(), cl, clientSet, "default", &hlfv1alpha1.FabricCAParentRef{Name: "org1-ca", Namespace: "cas"}, true)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(parent.URL).To(Equal("https://org1-ca.cas:7054"))
+			g.Expect(parent.Name).To(Equal("tlsca"))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	certManagerCertKey   = "tls.crt"
+	certManagerKeyKey    = "tls.key"
+	certManagerCACertKey = "ca.crt"
+)
+
+var certManagerCertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// ServiceHosts returns the DNS names of a Kubernetes service inside the cluster
+func ServiceHosts(name string, namespace string) []string {
+	return []string{
+		name,
+		fmt.Sprintf("%s.%s", name, namespace),
+		fmt.Sprintf("%s.%s.svc", name, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace),
+	}
+}
+
+// EnsureCertManagerCertificate creates or updates the cert-manager Certificate issuing the TLS certificate named
+// name in the namespace of the owner, the certificate is stored in the Secret named after
+// hlfv1alpha1.CertManagerSecretName and the Certificate is deleted along with the owner
+func EnsureCertManagerCertificate(ctx context.Context, cl client.Client, scheme *runtime.Scheme, owner v1.Object, name string, conf *hlfv1alpha1.CertManagerTLS, hosts []string) error {
+	dnsNames, ips := splitHosts(hosts)
+	var ipAddresses []interface{}
+	for _, ip := range ips {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+	var names []interface{}
+	for _, dnsName := range dnsNames {
+		names = append(names, dnsName)
+	}
+	kind := conf.IssuerRef.Kind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	group := conf.IssuerRef.Group
+	if group == "" {
+		group = certManagerCertificateGVK.Group
+	}
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certManagerCertificateGVK)
+	certificate.SetName(hlfv1alpha1.CertManagerSecretName(name))
+	certificate.SetNamespace(owner.GetNamespace())
+	_, err := controllerutil.CreateOrUpdate(ctx, cl, certificate, func() error {
+		err := controllerutil.SetControllerReference(owner, certificate, scheme)
+		if err != nil {
+			return err
+		}
+		spec := map[string]interface{}{
+			"secretName": hlfv1alpha1.CertManagerSecretName(name),
+			"commonName": name,
+			"issuerRef": map[string]interface{}{
+				"name":  conf.IssuerRef.Name,
+				"kind":  kind,
+				"group": group,
+			},
+			"privateKey": map[string]interface{}{
+				"algorithm": "ECDSA",
+				"size":      int64(256),
+				"encoding":  "PKCS8",
+			},
+			"usages": []interface{}{
+				"digital signature",
+				"key encipherment",
+				"server auth",
+				"client auth",
+			},
+		}
+		if len(names) > 0 {
+			spec["dnsNames"] = names
+		}
+		if len(ipAddresses) > 0 {
+			spec["ipAddresses"] = ipAddresses
+		}
+		certificate.Object["spec"] = spec
+		return nil
+	})
+	return err
+}
+
+// GetCertManagerCryptoMaterial reads the TLS certificate issued by cert-manager for the resource, the issuer CA
+// is returned as the root certificate
+func GetCertManagerCryptoMaterial(client *kubernetes.Clientset, namespace string, name string) (*CryptoMaterial, error) {
+	secretName := hlfv1alpha1.CertManagerSecretName(name)
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("certificate for %s/%s not issued yet: %w", namespace, name, err)
+	}
+	for _, key := range []string{certManagerCertKey, certManagerKeyKey, certManagerCACertKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, fmt.Errorf("secret %s/%s is missing key %s", namespace, secretName, key)
+		}
+	}
+	chain, err := ParseCertificates(secret.Data[certManagerCertKey])
+	if err != nil || len(chain) == 0 {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %v", namespace, secretName, certManagerCertKey, err)
+	}
+	key, err := utils.ParseECDSAPrivateKey(secret.Data[certManagerKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %w", namespace, secretName, certManagerKeyKey, err)
+	}
+	rootCrt, err := utils.ParseX509Certificate(secret.Data[certManagerCACertKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %w", namespace, secretName, certManagerCACertKey, err)
+	}
+	return &CryptoMaterial{
+		Cert:              chain[0],
+		Key:               key,
+		RootCert:          rootCrt,
+		IntermediateCerts: chain[1:],
+	}, nil
+}
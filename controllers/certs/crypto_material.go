@@ -0,0 +1,260 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	CryptoCertKey              = "cert.pem"
+	CryptoKeyKey               = "key.pem"
+	CryptoCACertKey            = "cacert.pem"
+	CryptoIntermediateCertsKey = "intermediatecerts.pem"
+	nodeIntermediateCertsKey   = "intcacert.pem"
+)
+
+// CryptoMaterial is a certificate and key pair issued by an external PKI
+type CryptoMaterial struct {
+	Cert              *x509.Certificate
+	Key               *ecdsa.PrivateKey
+	RootCert          *x509.Certificate
+	IntermediateCerts []*x509.Certificate
+}
+
+// EncodedIntermediateCerts returns the intermediate certificates as a PEM bundle
+func (c *CryptoMaterial) EncodedIntermediateCerts() string {
+	return EncodeCertificates(c.IntermediateCerts)
+}
+
+// EncodeCertificates returns the certificates as a PEM bundle
+func EncodeCertificates(crts []*x509.Certificate) string {
+	var buf bytes.Buffer
+	for _, crt := range crts {
+		buf.Write(utils.EncodeX509Certificate(crt))
+	}
+	return buf.String()
+}
+
+// ParseCAChain splits a PEM CA chain into its root and the intermediate certificates. The root is the self-signed
+// certificate of the chain or, when the chain ends at an intermediate CA, the certificate whose issuer isn't part
+// of the chain, which is then trusted as the root of the MSP
+func ParseCAChain(chain []byte) (*x509.Certificate, []*x509.Certificate, error) {
+	crts, err := ParseCertificates(chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(crts) == 0 {
+		return nil, nil, errors.New("the CA chain is empty")
+	}
+	rootIdx := -1
+	for i, crt := range crts {
+		if bytes.Equal(crt.RawIssuer, crt.RawSubject) && crt.CheckSignatureFrom(crt) == nil {
+			rootIdx = i
+			break
+		}
+	}
+	if rootIdx == -1 {
+		for i, crt := range crts {
+			if !isIssuedByAny(crt, crts) {
+				rootIdx = i
+				break
+			}
+		}
+	}
+	if rootIdx == -1 {
+		return nil, nil, errors.New("the CA chain has no top certificate")
+	}
+	var intermediateCerts []*x509.Certificate
+	for i, crt := range crts {
+		if i != rootIdx {
+			intermediateCerts = append(intermediateCerts, crt)
+		}
+	}
+	return crts[rootIdx], intermediateCerts, nil
+}
+
+// isIssuedByAny returns whether another certificate of the chain signed crt
+func isIssuedByAny(crt *x509.Certificate, crts []*x509.Certificate) bool {
+	for _, issuer := range crts {
+		if issuer == crt || !bytes.Equal(crt.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+		if crt.CheckSignatureFrom(issuer) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIntermediateCertsFromSecret returns the intermediate certificates stored by the node charts as a PEM bundle,
+// empty when the Secret doesn't exist because the node was enrolled against a root CA
+func GetIntermediateCertsFromSecret(client *kubernetes.Clientset, namespace string, secretName string) (string, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(secret.Data[nodeIntermediateCertsKey]), nil
+}
+
+// GetCryptoMaterial reads the crypto material stored in the given Secret
+func GetCryptoMaterial(client *kubernetes.Clientset, namespace string, secretName string) (*CryptoMaterial, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{CryptoCertKey, CryptoKeyKey, CryptoCACertKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, fmt.Errorf("secret %s/%s is missing key %s", namespace, secretName, key)
+		}
+	}
+	crt, err := utils.ParseX509Certificate(secret.Data[CryptoCertKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %w", namespace, secretName, CryptoCertKey, err)
+	}
+	key, err := utils.ParseECDSAPrivateKey(secret.Data[CryptoKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %w", namespace, secretName, CryptoKeyKey, err)
+	}
+	rootCrt, err := utils.ParseX509Certificate(secret.Data[CryptoCACertKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %w", namespace, secretName, CryptoCACertKey, err)
+	}
+	intermediateCerts, err := ParseCertificates(secret.Data[CryptoIntermediateCertsKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: invalid %s: %w", namespace, secretName, CryptoIntermediateCertsKey, err)
+	}
+	return &CryptoMaterial{
+		Cert:              crt,
+		Key:               key,
+		RootCert:          rootCrt,
+		IntermediateCerts: intermediateCerts,
+	}, nil
+}
+
+// ParseCertificates parses all the certificates of a PEM bundle
+func ParseCertificates(contents []byte) ([]*x509.Certificate, error) {
+	var crts []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, contents = pem.Decode(contents)
+		if block == nil {
+			break
+		}
+		crt, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		crts = append(crts, crt)
+	}
+	return crts, nil
+}
+
+func checkKeyPair(c *CryptoMaterial) error {
+	pub, ok := c.Cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || pub.X.Cmp(c.Key.X) != 0 || pub.Y.Cmp(c.Key.Y) != 0 {
+		return fmt.Errorf("private key doesn't match certificate %s", c.Cert.Subject)
+	}
+	return nil
+}
+
+// ValidateCryptoMaterial checks that the signing identity is valid for the MSP and that the TLS
+// certificate chains to its root and is issued for all the hosts
+func ValidateCryptoMaterial(mspID string, sign *CryptoMaterial, tls *CryptoMaterial, hosts []string) error {
+	err := checkKeyPair(sign)
+	if err != nil {
+		return err
+	}
+	err = checkKeyPair(tls)
+	if err != nil {
+		return err
+	}
+	var intermediateCerts [][]byte
+	for _, crt := range sign.IntermediateCerts {
+		intermediateCerts = append(intermediateCerts, utils.EncodeX509Certificate(crt))
+	}
+	var tlsIntermediateCerts [][]byte
+	for _, crt := range tls.IntermediateCerts {
+		tlsIntermediateCerts = append(tlsIntermediateCerts, utils.EncodeX509Certificate(crt))
+	}
+	signKey, err := utils.EncodePrivateKey(sign.Key)
+	if err != nil {
+		return err
+	}
+	fabricConf, err := proto.Marshal(&mspproto.FabricMSPConfig{
+		Name:                 mspID,
+		RootCerts:            [][]byte{utils.EncodeX509Certificate(sign.RootCert)},
+		IntermediateCerts:    intermediateCerts,
+		TlsRootCerts:         [][]byte{utils.EncodeX509Certificate(tls.RootCert)},
+		TlsIntermediateCerts: tlsIntermediateCerts,
+		SigningIdentity: &mspproto.SigningIdentityInfo{
+			PublicSigner: utils.EncodeX509Certificate(sign.Cert),
+			PrivateSigner: &mspproto.KeyInfo{
+				KeyMaterial: signKey,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// the MSP of Fabric with its default BCCSP, the crypto suite of the SDK can't import the keys of the MSP config.
+	// The 1.0 rules don't require the admins or node OUs of the organization, only the identity is validated
+	mspInstance, err := msp.New(&msp.BCCSPNewOpts{NewBaseOpts: msp.NewBaseOpts{Version: msp.MSPv1_0}}, factory.GetDefault())
+	if err != nil {
+		return err
+	}
+	err = mspInstance.Setup(&mspproto.MSPConfig{
+		Type:   int32(msp.FABRIC),
+		Config: fabricConf,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid signing identity for MSP %s: %w", mspID, err)
+	}
+	// the setup only checks the expiration of the signing certificate, not that it chains to the roots of the MSP
+	signingIdentity, err := mspInstance.GetDefaultSigningIdentity()
+	if err != nil {
+		return fmt.Errorf("invalid signing identity for MSP %s: %w", mspID, err)
+	}
+	// validates the identity embedded in the signing identity against the MSP
+	err = signingIdentity.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid signing identity for MSP %s: %w", mspID, err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(tls.RootCert)
+	intermediates := x509.NewCertPool()
+	for _, crt := range tls.IntermediateCerts {
+		intermediates.AddCert(crt)
+	}
+	_, err = tls.Cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("invalid TLS certificate: %w", err)
+	}
+	for _, host := range hosts {
+		err = tls.Cert.VerifyHostname(host)
+		if err != nil {
+			return fmt.Errorf("invalid TLS certificate: %w", err)
+		}
+	}
+	return nil
+}
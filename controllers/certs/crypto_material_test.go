@@ -0,0 +1,165 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	. "github.com/onsi/gomega"
+)
+
+func newTestCA(t *testing.T, cn string) *CryptoMaterial {
+	crt, key, err := CreateCA(pkix.Name{CommonName: cn, Organization: []string{cn}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CryptoMaterial{Cert: crt, Key: key, RootCert: crt}
+}
+
+func newTestCrypto(t *testing.T, ca *CryptoMaterial, cn string, hosts []string, isTLS bool) *CryptoMaterial {
+	crt, key, err := CreateCertificate(ca.Cert, ca.Key, pkix.Name{CommonName: cn}, hosts, isTLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CryptoMaterial{Cert: crt, Key: key, RootCert: ca.Cert}
+}
+
+func newTestIntermediateCA(t *testing.T, parent *CryptoMaterial, cn string) *CryptoMaterial {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := caTemplate(serialNumber, pkix.Name{CommonName: cn}, nil, nil, computeSKI(key))
+	crtBytes, err := x509.CreateCertificate(rand.Reader, template, parent.Cert, &key.PublicKey, parent.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt, err := x509.ParseCertificate(crtBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CryptoMaterial{Cert: crt, Key: key, RootCert: parent.RootCert}
+}
+
+func TestParseCAChain(t *testing.T) {
+	root := newTestCA(t, "root-ca")
+	intermediate := newTestIntermediateCA(t, root, "intermediate-ca")
+	subIntermediate := newTestIntermediateCA(t, intermediate, "sub-intermediate-ca")
+	encode := func(crts ...*x509.Certificate) []byte {
+		var chain []byte
+		for _, crt := range crts {
+			chain = append(chain, utils.EncodeX509Certificate(crt)...)
+		}
+		return chain
+	}
+
+	tests := []struct {
+		name              string
+		chain             []byte
+		root              *x509.Certificate
+		intermediateCerts []*x509.Certificate
+	}{
+		{name: "root CA", chain: encode(root.Cert), root: root.Cert},
+		{name: "intermediate CA", chain: encode(intermediate.Cert, root.Cert), root: root.Cert, intermediateCerts: []*x509.Certificate{intermediate.Cert}},
+		{name: "root first", chain: encode(root.Cert, intermediate.Cert), root: root.Cert, intermediateCerts: []*x509.Certificate{intermediate.Cert}},
+		{name: "chain ending at an intermediate", chain: encode(intermediate.Cert), root: intermediate.Cert},
+		{name: "chain of intermediates", chain: encode(subIntermediate.Cert, intermediate.Cert), root: intermediate.Cert, intermediateCerts: []*x509.Certificate{subIntermediate.Cert}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			rootCrt, intermediateCerts, err := ParseCAChain(tt.chain)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(rootCrt.Equal(tt.root)).To(BeTrue())
+			g.Expect(intermediateCerts).To(HaveLen(len(tt.intermediateCerts)))
+			for i, crt := range tt.intermediateCerts {
+				g.Expect(intermediateCerts[i].Equal(crt)).To(BeTrue())
+			}
+		})
+	}
+
+	g := NewWithT(t)
+	_, _, err := ParseCAChain(nil)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestValidateCryptoMaterial(t *testing.T) {
+	hosts := []string{"org1-peer0.default", "127.0.0.1"}
+	signCA := newTestCA(t, "ca")
+	tlsCA := newTestCA(t, "tlsca")
+	foreignCA := newTestCA(t, "foreign-ca")
+
+	tests := []struct {
+		name    string
+		sign    func() *CryptoMaterial
+		tls     func() *CryptoMaterial
+		hosts   []string
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			sign:  func() *CryptoMaterial { return newTestCrypto(t, signCA, "peer0", nil, false) },
+			tls:   func() *CryptoMaterial { return newTestCrypto(t, tlsCA, "peer0", hosts, true) },
+			hosts: hosts,
+		},
+		{
+			name: "signcert from a foreign CA",
+			sign: func() *CryptoMaterial {
+				crypto := newTestCrypto(t, foreignCA, "peer0", nil, false)
+				crypto.RootCert = signCA.Cert
+				return crypto
+			},
+			tls:     func() *CryptoMaterial { return newTestCrypto(t, tlsCA, "peer0", hosts, true) },
+			hosts:   hosts,
+			wantErr: "invalid signing identity",
+		},
+		{
+			name: "key not matching the signcert",
+			sign: func() *CryptoMaterial {
+				crypto := newTestCrypto(t, signCA, "peer0", nil, false)
+				crypto.Key = newTestCrypto(t, signCA, "peer1", nil, false).Key
+				return crypto
+			},
+			tls:     func() *CryptoMaterial { return newTestCrypto(t, tlsCA, "peer0", hosts, true) },
+			hosts:   hosts,
+			wantErr: "private key doesn't match",
+		},
+		{
+			name: "TLS certificate from a foreign CA",
+			sign: func() *CryptoMaterial { return newTestCrypto(t, signCA, "peer0", nil, false) },
+			tls: func() *CryptoMaterial {
+				crypto := newTestCrypto(t, foreignCA, "peer0", hosts, true)
+				crypto.RootCert = tlsCA.Cert
+				return crypto
+			},
+			hosts:   hosts,
+			wantErr: "invalid TLS certificate",
+		},
+		{
+			name:    "TLS certificate missing a host",
+			sign:    func() *CryptoMaterial { return newTestCrypto(t, signCA, "peer0", nil, false) },
+			tls:     func() *CryptoMaterial { return newTestCrypto(t, tlsCA, "peer0", hosts, true) },
+			hosts:   append([]string{"peer0.example.com"}, hosts...),
+			wantErr: "invalid TLS certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateCryptoMaterial("Org1MSP", tt.sign(), tt.tls(), tt.hosts)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/bccsp/signer"
+)
+
+// compute Subject Key Identifier
+func computeSKI(privKey *ecdsa.PrivateKey) []byte {
+	// Marshall the public key
+	raw := elliptic.Marshal(privKey.Curve, privKey.PublicKey.X, privKey.PublicKey.Y)
+
+	// Hash it
+	hash := sha256.Sum256(raw)
+	return hash[:]
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(rand.Reader, serialNumberLimit)
+}
+
+func splitHosts(hosts []string) ([]string, []net.IP) {
+	var dnsNames []string
+	var ips []net.IP
+	for _, host := range hosts {
+		addr := net.ParseIP(host)
+		if addr == nil {
+			dnsNames = append(dnsNames, host)
+		} else {
+			ips = append(ips, addr)
+		}
+	}
+	return dnsNames, ips
+}
+
+func caTemplate(serialNumber *big.Int, subject pkix.Name, dnsNames []string, ips []net.IP, ski []byte) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             time.Now().AddDate(0, 0, -1),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		DNSNames:              dnsNames,
+		IPAddresses:           ips,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		SubjectKeyId:          ski,
+	}
+}
+
+// CreateCA creates a self signed CA certificate valid for ten years
+func CreateCA(subject pkix.Name, dnsNames []string, ips []net.IP) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	caPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	x509Cert := caTemplate(serialNumber, subject, dnsNames, ips, computeSKI(caPrivKey))
+	caBytes, err := x509.CreateCertificate(rand.Reader, x509Cert, x509Cert, &caPrivKey.PublicKey, caPrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	crt, err := x509.ParseCertificate(caBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crt, caPrivKey, nil
+}
+
+// CreatePKCS11CA creates a self signed CA certificate valid for ten years, its key is generated in the HSM
+// and found by the CA server through the subject key identifier of the certificate
+func CreatePKCS11CA(params *PKCS11Params, subject pkix.Name, dnsNames []string, ips []net.IP) (*x509.Certificate, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	cryptoSuite, err := newPKCS11CryptoSuite(&pkcs11CryptoConfig{params: params})
+	if err != nil {
+		return nil, err
+	}
+	key, err := cryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(false))
+	if err != nil {
+		return nil, err
+	}
+	caSigner, err := signer.New(cryptoSuite, key)
+	if err != nil {
+		return nil, err
+	}
+	x509Cert := caTemplate(serialNumber, subject, dnsNames, ips, key.SKI())
+	caBytes, err := x509.CreateCertificate(rand.Reader, x509Cert, x509Cert, caSigner.Public(), caSigner)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(caBytes)
+}
+
+// CreateCertificate issues a certificate signed by the given CA, TLS certificates are valid for the hosts
+func CreateCertificate(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, subject pkix.Name, hosts []string, isTLS bool) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               subject,
+		NotBefore:             time.Now().AddDate(0, 0, -1),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		SubjectKeyId:          computeSKI(privKey),
+		AuthorityKeyId:        caCert.SubjectKeyId,
+	}
+	if isTLS {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+		template.DNSNames, template.IPAddresses = splitHosts(hosts)
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, caCert, &privKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	crt, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crt, privKey, nil
+}
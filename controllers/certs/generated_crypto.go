@@ -0,0 +1,309 @@
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"strings"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	GeneratedCACertKey    = "cacert.pem"
+	GeneratedCAKeyKey     = "cakey.pem"
+	GeneratedTLSCACertKey = "tlscacert.pem"
+	GeneratedTLSCAKeyKey  = "tlscakey.pem"
+)
+
+// GeneratedAdminSecretName returns the name of the Secret holding the admin identity generated for the MSP
+func GeneratedAdminSecretName(mspID string) string {
+	return fmt.Sprintf("%s-generated-admin", strings.ToLower(mspID))
+}
+
+// GeneratedCASecretName returns the name of the Secret holding the CAs generated for the MSP
+func GeneratedCASecretName(mspID string, conf *hlfv1alpha1.GeneratedCrypto) string {
+	if conf != nil && conf.CASecretName != "" {
+		return conf.CASecretName
+	}
+	return fmt.Sprintf("%s-generated-ca", strings.ToLower(mspID))
+}
+
+// GetNodeCryptoMaterial returns the signing and TLS material of a node that doesn't enroll against a CA,
+// either provided in Secrets or generated by the operator. The generated Secrets of the node are owned by owner
+func GetNodeCryptoMaterial(client *kubernetes.Clientset, namespace string, owner v1.OwnerReference, nodeName string, mspID string, nodeOU string, secret *hlfv1alpha1.Secret, hosts []string) (*CryptoMaterial, *CryptoMaterial, error) {
+	var signCrypto, tlsCrypto *CryptoMaterial
+	var err error
+	if secret.Crypto != nil {
+		signCrypto, err = GetCryptoMaterial(client, namespace, secret.Crypto.Sign.SecretName)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsCrypto, err = GetCryptoMaterial(client, namespace, secret.Crypto.TLS.SecretName)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		signCrypto, tlsCrypto, err = getGeneratedCryptoMaterial(client, namespace, owner, nodeName, mspID, nodeOU, secret.Generated, hosts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	err = ValidateCryptoMaterial(mspID, signCrypto, tlsCrypto, hosts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signCrypto, tlsCrypto, nil
+}
+
+// GetNodeCACerts returns the signing and TLS CAs of a node that doesn't enroll against a CA, only the root and
+// intermediate certificates of the returned material are meant to be used
+func GetNodeCACerts(client *kubernetes.Clientset, namespace string, mspID string, secret *hlfv1alpha1.Secret) (*CryptoMaterial, *CryptoMaterial, error) {
+	if secret.Crypto != nil {
+		signCrypto, err := GetCryptoMaterial(client, namespace, secret.Crypto.Sign.SecretName)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsCrypto, err := GetCryptoMaterial(client, namespace, secret.Crypto.TLS.SecretName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return signCrypto, tlsCrypto, nil
+	}
+	if secret.Generated == nil {
+		return nil, nil, errors.New("the node enrolls against a CA")
+	}
+	secretName := GeneratedCASecretName(mspID, secret.Generated)
+	caSecret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseGeneratedCAs(caSecret)
+}
+
+func getGeneratedCryptoMaterial(client *kubernetes.Clientset, namespace string, owner v1.OwnerReference, nodeName string, mspID string, nodeOU string, conf *hlfv1alpha1.GeneratedCrypto, hosts []string) (*CryptoMaterial, *CryptoMaterial, error) {
+	signCA, tlsCA, err := getOrCreateGeneratedCAs(client, namespace, GeneratedCASecretName(mspID, conf), mspID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the admin identity is used to operate the channels of the organization, as the CAs it isn't owned by the nodes
+	_, err = getOrCreateNodeCrypto(
+		client,
+		namespace,
+		nil,
+		GeneratedAdminSecretName(mspID),
+		signCA,
+		pkix.Name{
+			CommonName:         "admin",
+			Organization:       []string{mspID},
+			OrganizationalUnit: []string{"admin"},
+		},
+		nil,
+		false,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	signCrypto, err := getOrCreateNodeCrypto(
+		client,
+		namespace,
+		&owner,
+		fmt.Sprintf("%s-generated-sign", nodeName),
+		signCA,
+		pkix.Name{
+			CommonName:         nodeName,
+			Organization:       []string{mspID},
+			OrganizationalUnit: []string{nodeOU},
+		},
+		nil,
+		false,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsHosts := append([]string{nodeName, fmt.Sprintf("%s.%s", nodeName, namespace)}, hosts...)
+	tlsCrypto, err := getOrCreateNodeCrypto(
+		client,
+		namespace,
+		&owner,
+		fmt.Sprintf("%s-generated-tls", nodeName),
+		tlsCA,
+		pkix.Name{
+			CommonName:   nodeName,
+			Organization: []string{mspID},
+		},
+		tlsHosts,
+		true,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signCrypto, tlsCrypto, nil
+}
+
+// getOrCreateGeneratedCAs returns the CAs of the MSP, the Secret isn't owned by the nodes since the channels of the
+// organization trust these CAs beyond the lifetime of any of its nodes
+func getOrCreateGeneratedCAs(client *kubernetes.Clientset, namespace string, secretName string, mspID string) (*CryptoMaterial, *CryptoMaterial, error) {
+	ctx := context.Background()
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, secretName, v1.GetOptions{})
+	if err == nil {
+		return parseGeneratedCAs(secret)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, nil, err
+	}
+	signCert, signKey, err := CreateCA(pkix.Name{CommonName: "ca", Organization: []string{mspID}}, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsCert, tlsKey, err := CreateCA(pkix.Name{CommonName: "tlsca", Organization: []string{mspID}}, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	signKeyPEM, err := utils.EncodePrivateKey(signKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsKeyPEM, err := utils.EncodePrivateKey(tlsKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = client.CoreV1().Secrets(namespace).Create(ctx, &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			GeneratedCACertKey:    utils.EncodeX509Certificate(signCert),
+			GeneratedCAKeyKey:     signKeyPEM,
+			GeneratedTLSCACertKey: utils.EncodeX509Certificate(tlsCert),
+			GeneratedTLSCAKeyKey:  tlsKeyPEM,
+		},
+	}, v1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// another node of the organization created the CAs in the meantime
+		secret, err = client.CoreV1().Secrets(namespace).Get(ctx, secretName, v1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return parseGeneratedCAs(secret)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	signCA := &CryptoMaterial{Cert: signCert, Key: signKey, RootCert: signCert}
+	tlsCA := &CryptoMaterial{Cert: tlsCert, Key: tlsKey, RootCert: tlsCert}
+	return signCA, tlsCA, nil
+}
+
+func parseGeneratedCAs(secret *corev1.Secret) (*CryptoMaterial, *CryptoMaterial, error) {
+	signCA, err := parseGeneratedCA(secret.Data[GeneratedCACertKey], secret.Data[GeneratedCAKeyKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("secret %s/%s: invalid CA: %w", secret.Namespace, secret.Name, err)
+	}
+	tlsCA, err := parseGeneratedCA(secret.Data[GeneratedTLSCACertKey], secret.Data[GeneratedTLSCAKeyKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("secret %s/%s: invalid TLS CA: %w", secret.Namespace, secret.Name, err)
+	}
+	return signCA, tlsCA, nil
+}
+
+func parseGeneratedCA(certData []byte, keyData []byte) (*CryptoMaterial, error) {
+	crt, err := utils.ParseX509Certificate(certData)
+	if err != nil {
+		return nil, err
+	}
+	key, err := utils.ParseECDSAPrivateKey(keyData)
+	if err != nil {
+		return nil, err
+	}
+	return &CryptoMaterial{Cert: crt, Key: key, RootCert: crt}, nil
+}
+
+func coversHosts(crt *x509.Certificate, hosts []string) bool {
+	for _, host := range hosts {
+		if crt.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func isIssuedBy(existing *CryptoMaterial, ca *CryptoMaterial, hosts []string) bool {
+	return existing.RootCert.Equal(ca.Cert) && coversHosts(existing.Cert, hosts)
+}
+
+// getOrCreateNodeCrypto issues the certificate into the Secret unless it already holds one issued by the CA for the
+// hosts, the Secret is owned by owner when set
+func getOrCreateNodeCrypto(client *kubernetes.Clientset, namespace string, owner *v1.OwnerReference, secretName string, ca *CryptoMaterial, subject pkix.Name, hosts []string, isTLS bool) (*CryptoMaterial, error) {
+	ctx := context.Background()
+	existing, err := GetCryptoMaterial(client, namespace, secretName)
+	if err == nil && isIssuedBy(existing, ca, hosts) {
+		return existing, nil
+	}
+	crt, key, err := CreateCertificate(ca.Cert, ca.Key, subject, hosts, isTLS)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := utils.EncodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]byte{
+		CryptoCertKey:   utils.EncodeX509Certificate(crt),
+		CryptoKeyKey:    keyPEM,
+		CryptoCACertKey: utils.EncodeX509Certificate(ca.Cert),
+	}
+	var ownerReferences []v1.OwnerReference
+	if owner != nil {
+		ownerReferences = []v1.OwnerReference{*owner}
+	}
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, secretName, v1.GetOptions{})
+	if err == nil {
+		secret.Data = data
+		if owner != nil && !hasOwnerReference(secret, *owner) {
+			secret.OwnerReferences = append(secret.OwnerReferences, *owner)
+		}
+		_, err = client.CoreV1().Secrets(namespace).Update(ctx, secret, v1.UpdateOptions{})
+	} else if apierrors.IsNotFound(err) {
+		_, err = client.CoreV1().Secrets(namespace).Create(ctx, &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:            secretName,
+				Namespace:       namespace,
+				OwnerReferences: ownerReferences,
+			},
+			Data: data,
+		}, v1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			// a concurrent reconcile issued the certificate in the meantime
+			existing, err = GetCryptoMaterial(client, namespace, secretName)
+			if err != nil {
+				return nil, err
+			}
+			if !isIssuedBy(existing, ca, hosts) {
+				return nil, fmt.Errorf("secret %s/%s was created concurrently with a certificate that isn't issued by the CA", namespace, secretName)
+			}
+			return existing, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CryptoMaterial{Cert: crt, Key: key, RootCert: ca.Cert}, nil
+}
+
+func hasOwnerReference(secret *corev1.Secret, owner v1.OwnerReference) bool {
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID == owner.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package certs
+
+import (
+	"testing"
+
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIsIssuedBy(t *testing.T) {
+	ca := newTestCA(t, "tlsca")
+	otherCA := newTestCA(t, "other-tlsca")
+	existing := newTestCrypto(t, ca, "peer0", []string{"peer0", "peer0.default"}, true)
+
+	tests := []struct {
+		name  string
+		ca    *CryptoMaterial
+		hosts []string
+		want  bool
+	}{
+		{name: "same CA and hosts", ca: ca, hosts: []string{"peer0", "peer0.default"}, want: true},
+		{name: "subset of the hosts", ca: ca, hosts: []string{"peer0"}, want: true},
+		{name: "new host", ca: ca, hosts: []string{"peer0", "peer0.example.com"}, want: false},
+		{name: "other CA", ca: otherCA, hosts: []string{"peer0"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(isIssuedBy(existing, tt.ca, tt.hosts)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestParseGeneratedCAs(t *testing.T) {
+	g := NewWithT(t)
+	signCA := newTestCA(t, "ca")
+	tlsCA := newTestCA(t, "tlsca")
+	signKey, err := utils.EncodePrivateKey(signCA.Key)
+	g.Expect(err).NotTo(HaveOccurred())
+	tlsKey, err := utils.EncodePrivateKey(tlsCA.Key)
+	g.Expect(err).NotTo(HaveOccurred())
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "org1msp-generated-ca", Namespace: "default"},
+		Data: map[string][]byte{
+			GeneratedCACertKey:    utils.EncodeX509Certificate(signCA.Cert),
+			GeneratedCAKeyKey:     signKey,
+			GeneratedTLSCACertKey: utils.EncodeX509Certificate(tlsCA.Cert),
+			GeneratedTLSCAKeyKey:  tlsKey,
+		},
+	}
+
+	parsedSignCA, parsedTLSCA, err := parseGeneratedCAs(secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(parsedSignCA.Cert.Equal(signCA.Cert)).To(BeTrue())
+	g.Expect(parsedTLSCA.Cert.Equal(tlsCA.Cert)).To(BeTrue())
+
+	delete(secret.Data, GeneratedTLSCAKeyKey)
+	_, _, err = parseGeneratedCAs(secret)
+	g.Expect(err).To(MatchError(ContainSubstring("invalid TLS CA")))
+}
+
+func TestHasOwnerReference(t *testing.T) {
+	g := NewWithT(t)
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			OwnerReferences: []v1.OwnerReference{{Kind: "FabricPeer", Name: "org1-peer0", UID: types.UID("1")}},
+		},
+	}
+	g.Expect(hasOwnerReference(secret, v1.OwnerReference{Kind: "FabricPeer", Name: "org1-peer0", UID: types.UID("1")})).To(BeTrue())
+	// a node recreated with the same name is another owner
+	g.Expect(hasOwnerReference(secret, v1.OwnerReference{Kind: "FabricPeer", Name: "org1-peer0", UID: types.UID("2")})).To(BeFalse())
+}
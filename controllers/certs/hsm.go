@@ -0,0 +1,90 @@
+package certs
+
+import (
+	"errors"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"k8s.io/client-go/kubernetes"
+)
+
+var errPKCS11Unsupported = errors.New("the operator was built without PKCS#11 support, build it with CGO enabled and the pkcs11 tag")
+
+// PKCS11Params are the settings to open a session on the token of a PKCS#11 HSM
+type PKCS11Params struct {
+	Library  string
+	Label    string
+	Pin      string
+	Hash     string
+	Security int
+}
+
+// GetPKCS11Params resolves the PIN of the HSM token, nil is returned when the keys are kept in software. It fails
+// when the operator is built without PKCS#11 support so that the resource is rejected before enrolling
+func GetPKCS11Params(client *kubernetes.Clientset, namespace string, conf *hlfv1alpha1.BCCSPPKCS11) (*PKCS11Params, error) {
+	if conf == nil {
+		return nil, nil
+	}
+	if !PKCS11Supported {
+		return nil, errPKCS11Unsupported
+	}
+	pin, err := utils.ResolveSecretValue(client, namespace, "", &conf.PinRef)
+	if err != nil {
+		return nil, err
+	}
+	params := &PKCS11Params{
+		Library:  conf.Library,
+		Label:    conf.Label,
+		Pin:      pin,
+		Hash:     conf.Hash,
+		Security: conf.Security,
+	}
+	if params.Hash == "" {
+		params.Hash = "SHA2"
+	}
+	if params.Security == 0 {
+		params.Security = 256
+	}
+	return params, nil
+}
+
+// pkcs11CryptoConfig overrides the crypto suite settings of the SDK to generate the keys in the HSM
+type pkcs11CryptoConfig struct {
+	params *PKCS11Params
+}
+
+func (c *pkcs11CryptoConfig) SecurityProvider() string {
+	return "pkcs11"
+}
+
+func (c *pkcs11CryptoConfig) SecurityAlgorithm() string {
+	return c.params.Hash
+}
+
+func (c *pkcs11CryptoConfig) SecurityLevel() int {
+	return c.params.Security
+}
+
+func (c *pkcs11CryptoConfig) SecurityProviderLibPath() string {
+	return c.params.Library
+}
+
+func (c *pkcs11CryptoConfig) SecurityProviderPin() string {
+	return c.params.Pin
+}
+
+func (c *pkcs11CryptoConfig) SecurityProviderLabel() string {
+	return c.params.Label
+}
+
+func (c *pkcs11CryptoConfig) IsSecurityEnabled() bool {
+	return true
+}
+
+func (c *pkcs11CryptoConfig) SoftVerify() bool {
+	return true
+}
+
+func (c *pkcs11CryptoConfig) KeyStorePath() string {
+	return ""
+}
@@ -0,0 +1,241 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/idemix"
+	"github.com/pkg/errors"
+)
+
+// Names of the Idemix issuer key files of fabric-ca-server, also used as the keys of the Secret holding them
+const (
+	IdemixIssuerPublicKey      = "IssuerPublicKey"
+	IdemixIssuerSecretKey      = "IssuerSecretKey"
+	IdemixRevocationPublicKey  = "IssuerRevocationPublicKey"
+	IdemixRevocationPrivateKey = "IssuerRevocationPrivateKey"
+)
+
+// idemixAttributeNames are the attributes of the credentials issued by fabric-ca-server
+var idemixAttributeNames = []string{"OU", "Role", "EnrollmentID", "RevocationHandle"}
+
+// GenerateIdemixIssuerKeys generates the Idemix issuer and revocation keys in the format of fabric-ca-server
+func GenerateIdemixIssuerKeys() (map[string][]byte, error) {
+	rng, err := idemix.GetRand()
+	if err != nil {
+		return nil, err
+	}
+	issuerKey, err := idemix.NewIssuerKey(idemixAttributeNames, rng)
+	if err != nil {
+		return nil, err
+	}
+	ipkBytes, err := proto.Marshal(issuerKey.Ipk)
+	if err != nil {
+		return nil, err
+	}
+	revocationKey, err := idemix.GenerateLongTermRevocationKey()
+	if err != nil {
+		return nil, err
+	}
+	revocationPrivateKey, err := x509.MarshalECPrivateKey(revocationKey)
+	if err != nil {
+		return nil, err
+	}
+	revocationPublicKey, err := x509.MarshalPKIXPublicKey(&revocationKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		IdemixIssuerPublicKey:      ipkBytes,
+		IdemixIssuerSecretKey:      issuerKey.Isk,
+		IdemixRevocationPublicKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: revocationPublicKey}),
+		IdemixRevocationPrivateKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: revocationPrivateKey}),
+	}, nil
+}
+
+type EnrollIdemixRequest struct {
+	TLSCert string
+	URL     string
+	Name    string
+	MSPID   string
+	User    string
+	Secret  string
+}
+
+// IdemixCredential is the Idemix signing identity of a user with the public keys of its issuer
+type IdemixCredential struct {
+	SignerConfig        *msp.IdemixMSPSignerConfig
+	IssuerPublicKey     []byte
+	RevocationPublicKey []byte
+}
+
+type idemixEnrollmentRequestNet struct {
+	CredRequest *idemix.CredRequest `json:"request"`
+	CAName      string              `json:"caname,omitempty"`
+}
+
+type idemixEnrollmentResponseNet struct {
+	Credential string
+	Attrs      map[string]interface{}
+	Nonce      string
+	CRI        string
+}
+
+type caResponseNet struct {
+	Success bool
+	Result  json.RawMessage
+	Errors  []struct {
+		Code    int
+		Message string
+	}
+}
+
+func postIdemixCredential(client *http.Client, request EnrollIdemixRequest, body interface{}) (*idemixEnrollmentResponseNet, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s/api/v1/idemix/credential", strings.TrimSuffix(request.URL, "/")),
+		bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(request.User, request.Secret)
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	caResp := &caResponseNet{}
+	err = json.Unmarshal(respBody, caResp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid response from the CA, status %s", resp.Status)
+	}
+	if !caResp.Success {
+		var messages []string
+		for _, caErr := range caResp.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", caErr.Code, caErr.Message))
+		}
+		return nil, errors.Errorf("idemix enrollment failed: %s", strings.Join(messages, ", "))
+	}
+	result := &idemixEnrollmentResponseNet{}
+	err = json.Unmarshal(caResp.Result, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EnrollIdemix requests an Idemix credential for the user, the sdk client only supports X.509 enrollments
+func EnrollIdemix(request EnrollIdemixRequest) (*IdemixCredential, error) {
+	keyStorePath, err := ioutil.TempDir("", "idemix")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(keyStorePath)
+	caClient, _, _, _, err := GetClient(FabricCAParams{
+		TLSCert:  request.TLSCert,
+		URL:      request.URL,
+		Name:     request.Name,
+		MSPID:    request.MSPID,
+		EnrollID: request.User,
+	}, keyStorePath)
+	if err != nil {
+		return nil, err
+	}
+	caInfo, err := caClient.GetCAInfo()
+	if err != nil {
+		return nil, err
+	}
+	if len(caInfo.IssuerPublicKey) == 0 {
+		return nil, errors.New("the CA doesn't have an Idemix issuer public key")
+	}
+	ipk := &idemix.IssuerPublicKey{}
+	err = proto.Unmarshal(caInfo.IssuerPublicKey, ipk)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid Idemix issuer public key")
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM([]byte(request.TLSCert)) {
+		return nil, errors.New("invalid TLS certificate of the CA")
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: certPool},
+		},
+	}
+	// the first request returns the nonce the credential request is computed with
+	nonceResp, err := postIdemixCredential(client, request, idemixEnrollmentRequestNet{CAName: request.Name})
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(nonceResp.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := idemix.GetRand()
+	if err != nil {
+		return nil, err
+	}
+	sk := idemix.RandModOrder(rng)
+	credResp, err := postIdemixCredential(client, request, idemixEnrollmentRequestNet{
+		CredRequest: idemix.NewCredRequest(sk, nonce, ipk, rng),
+		CAName:      request.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	credBytes, err := base64.StdEncoding.DecodeString(credResp.Credential)
+	if err != nil {
+		return nil, err
+	}
+	cred := &idemix.Credential{}
+	err = proto.Unmarshal(credBytes, cred)
+	if err != nil {
+		return nil, err
+	}
+	err = cred.Ver(sk, ipk)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid credential issued by the CA")
+	}
+	criBytes, err := base64.StdEncoding.DecodeString(credResp.CRI)
+	if err != nil {
+		return nil, err
+	}
+	signerConfig := &msp.IdemixMSPSignerConfig{
+		Cred:                            credBytes,
+		Sk:                              idemix.BigToBytes(sk),
+		EnrollmentId:                    request.User,
+		CredentialRevocationInformation: criBytes,
+	}
+	if ou, ok := credResp.Attrs["OU"].(string); ok {
+		signerConfig.OrganizationalUnitIdentifier = ou
+	}
+	if role, ok := credResp.Attrs["Role"].(float64); ok {
+		signerConfig.Role = int32(role)
+	}
+	return &IdemixCredential{
+		SignerConfig:        signerConfig,
+		IssuerPublicKey:     caInfo.IssuerPublicKey,
+		RevocationPublicKey: caInfo.IssuerRevocationPublicKey,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package certs
+
+import (
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+// RegistrarParams is the CA and the registrar identity used to manage its identities and affiliations
+type RegistrarParams struct {
+	TLSCert      string
+	URL          string
+	Name         string
+	MSPID        string
+	EnrollID     string
+	EnrollSecret string
+}
+
+func (p RegistrarParams) getClient() (*msp.CAClientImpl, error) {
+	keystorePath, err := ioutil.TempDir("", "registrar")
+	if err != nil {
+		return nil, err
+	}
+	caClient, _, _, _, err := GetClient(FabricCAParams{
+		TLSCert:      p.TLSCert,
+		URL:          p.URL,
+		Name:         p.Name,
+		MSPID:        p.MSPID,
+		EnrollID:     p.EnrollID,
+		EnrollSecret: p.EnrollSecret,
+	}, keystorePath)
+	if err != nil {
+		return nil, err
+	}
+	return caClient, nil
+}
+
+// GetIdentities returns the identities of the CA the registrar is allowed to see
+func GetIdentities(params RegistrarParams) ([]*api.IdentityResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.GetAllIdentities(params.Name)
+}
+
+func GetIdentity(params RegistrarParams, id string) (*api.IdentityResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.GetIdentity(id, params.Name)
+}
+
+// ModifyIdentity updates an identity, the fields left empty in the request are kept
+func ModifyIdentity(params RegistrarParams, request api.IdentityRequest) (*api.IdentityResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	request.CAName = params.Name
+	return caClient.ModifyIdentity(&request)
+}
+
+// RemoveIdentity removes an identity, the CA must allow it with cfg.identities.allowremove
+func RemoveIdentity(params RegistrarParams, id string, force bool) (*api.IdentityResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.RemoveIdentity(&api.RemoveIdentityRequest{
+		ID:     id,
+		Force:  force,
+		CAName: params.Name,
+	})
+}
+
+// GetAffiliations returns the affiliation tree of the CA
+func GetAffiliations(params RegistrarParams) (*api.AffiliationResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.GetAllAffiliations(params.Name)
+}
+
+// AddAffiliation adds an affiliation, its parents are created when force is set
+func AddAffiliation(params RegistrarParams, name string, force bool) (*api.AffiliationResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.AddAffiliation(&api.AffiliationRequest{
+		Name:   name,
+		Force:  force,
+		CAName: params.Name,
+	})
+}
+
+// RemoveAffiliation removes an affiliation, its children and identities are removed when force is set and the
+// CA allows it with cfg.affiliations.allowremove
+func RemoveAffiliation(params RegistrarParams, name string, force bool) (*api.AffiliationResponse, error) {
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.RemoveAffiliation(&api.AffiliationRequest{
+		Name:   name,
+		Force:  force,
+		CAName: params.Name,
+	})
+}
@@ -0,0 +1,76 @@
+package certs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+// intermediateCAProfile is the signing profile of the Fabric CA issuing CA certificates
+const intermediateCAProfile = "ca"
+
+type EnrollIntermediateCARequest struct {
+	TLSCert      string
+	URL          string
+	Name         string
+	MSPID        string
+	EnrollID     string
+	EnrollSecret string
+	// identity of the intermediate CA in the parent CA, used as the common name of its certificate
+	User string
+	// the private key is generated in the HSM and not returned when set
+	PKCS11 *PKCS11Params
+}
+
+// EnrollIntermediateCA registers the identity of an intermediate CA in the parent CA, resetting its secret when it's
+// already registered, and enrolls the CA certificate with the "ca" signing profile of the parent
+func EnrollIntermediateCA(params EnrollIntermediateCARequest) (*CryptoMaterial, error) {
+	caClient, _, _, _, err := GetClient(FabricCAParams{
+		TLSCert:      params.TLSCert,
+		URL:          params.URL,
+		Name:         params.Name,
+		MSPID:        params.MSPID,
+		EnrollID:     params.EnrollID,
+		EnrollSecret: params.EnrollSecret,
+	}, keyStorePath)
+	if err != nil {
+		return nil, err
+	}
+	secretBytes := make([]byte, 16)
+	_, err = rand.Read(secretBytes)
+	if err != nil {
+		return nil, err
+	}
+	secret := hex.EncodeToString(secretBytes)
+	identity := &api.IdentityRequest{
+		ID:             params.User,
+		Type:           "client",
+		MaxEnrollments: -1,
+		Secret:         secret,
+		CAName:         params.Name,
+		Attributes: []api.Attribute{
+			{Name: "hf.IntermediateCA", Value: "true"},
+		},
+	}
+	_, err = caClient.GetIdentity(params.User, params.Name)
+	if err != nil {
+		_, err = caClient.CreateIdentity(identity)
+	} else {
+		_, err = caClient.ModifyIdentity(identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return EnrollUserCrypto(EnrollUserRequest{
+		TLSCert: params.TLSCert,
+		URL:     params.URL,
+		Name:    params.Name,
+		MSPID:   params.MSPID,
+		User:    params.User,
+		Secret:  secret,
+		CN:      params.User,
+		Profile: intermediateCAProfile,
+		PKCS11:  params.PKCS11,
+	})
+}
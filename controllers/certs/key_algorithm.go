@@ -0,0 +1,129 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	bccsputils "github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/bccsp/utils"
+)
+
+// GetKeyAlgorithm validates the key algorithm requested for an enrollment, ECDSA P-256 is used when empty
+func GetKeyAlgorithm(algorithm string) (hlfv1alpha1.KeyAlgorithm, error) {
+	switch hlfv1alpha1.KeyAlgorithm(algorithm) {
+	case "", hlfv1alpha1.KeyAlgorithmECDSAP256:
+		return hlfv1alpha1.KeyAlgorithmECDSAP256, nil
+	case hlfv1alpha1.KeyAlgorithmECDSAP384:
+		return hlfv1alpha1.KeyAlgorithmECDSAP384, nil
+	default:
+		return "", fmt.Errorf("unsupported key algorithm %s, supported algorithms are %s and %s",
+			algorithm, hlfv1alpha1.KeyAlgorithmECDSAP256, hlfv1alpha1.KeyAlgorithmECDSAP384)
+	}
+}
+
+type ecdsaPublicKey struct {
+	pub *ecdsa.PublicKey
+}
+
+func (k *ecdsaPublicKey) Bytes() ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(k.pub)
+}
+
+func (k *ecdsaPublicKey) SKI() []byte {
+	raw := elliptic.Marshal(k.pub.Curve, k.pub.X, k.pub.Y)
+	hash := sha256.Sum256(raw)
+	return hash[:]
+}
+
+func (k *ecdsaPublicKey) Symmetric() bool {
+	return false
+}
+
+func (k *ecdsaPublicKey) Private() bool {
+	return false
+}
+
+func (k *ecdsaPublicKey) PublicKey() (core.Key, error) {
+	return k, nil
+}
+
+type ecdsaPrivateKey struct {
+	ecdsaPublicKey
+	priv *ecdsa.PrivateKey
+}
+
+func (k *ecdsaPrivateKey) Bytes() ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func (k *ecdsaPrivateKey) Private() bool {
+	return true
+}
+
+func (k *ecdsaPrivateKey) PublicKey() (core.Key, error) {
+	return &k.ecdsaPublicKey, nil
+}
+
+// curveCryptoSuite generates the key of the enrollment on a curve the software suite doesn't support,
+// the key is kept in memory and returned with the enrolled certificate
+type curveCryptoSuite struct {
+	core.CryptoSuite
+	curve elliptic.Curve
+	key   *ecdsaPrivateKey
+}
+
+func newKeyAlgorithmCryptoSuite(swSuite core.CryptoSuite, algorithm hlfv1alpha1.KeyAlgorithm) core.CryptoSuite {
+	switch algorithm {
+	case hlfv1alpha1.KeyAlgorithmECDSAP384:
+		return &curveCryptoSuite{CryptoSuite: swSuite, curve: elliptic.P384()}
+	default:
+		return swSuite
+	}
+}
+
+func (s *curveCryptoSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	priv, err := ecdsa.GenerateKey(s.curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	s.key = &ecdsaPrivateKey{ecdsaPublicKey: ecdsaPublicKey{pub: &priv.PublicKey}, priv: priv}
+	return s.key, nil
+}
+
+func (s *curveCryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	if s.key != nil && bytes.Equal(ski, s.key.SKI()) {
+		return s.key, nil
+	}
+	return s.CryptoSuite.GetKey(ski)
+}
+
+func (s *curveCryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	key, ok := k.(*ecdsaPrivateKey)
+	if !ok {
+		return s.CryptoSuite.Sign(k, digest, opts)
+	}
+	r, sig, err := ecdsa.Sign(rand.Reader, key.priv, digest)
+	if err != nil {
+		return nil, err
+	}
+	sig, err = bccsputils.ToLowS(&key.priv.PublicKey, sig)
+	if err != nil {
+		return nil, err
+	}
+	return bccsputils.MarshalECDSASignature(r, sig)
+}
+
+// generatedKey returns the private key generated by the suite when it isn't kept in the software key store
+func generatedKey(suite core.CryptoSuite) *ecdsa.PrivateKey {
+	if s, ok := suite.(*curveCryptoSuite); ok && s.key != nil {
+		return s.key.priv
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"testing"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	bccsputils "github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/bccsp/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestGetKeyAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      hlfv1alpha1.KeyAlgorithm
+		wantErr   bool
+	}{
+		{algorithm: "", want: hlfv1alpha1.KeyAlgorithmECDSAP256},
+		{algorithm: string(hlfv1alpha1.KeyAlgorithmECDSAP256), want: hlfv1alpha1.KeyAlgorithmECDSAP256},
+		{algorithm: string(hlfv1alpha1.KeyAlgorithmECDSAP384), want: hlfv1alpha1.KeyAlgorithmECDSAP384},
+		{algorithm: "RSA2048", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			g := NewWithT(t)
+			algorithm, err := GetKeyAlgorithm(tt.algorithm)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ContainSubstring("unsupported key algorithm")))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(algorithm).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestCurveCryptoSuite(t *testing.T) {
+	g := NewWithT(t)
+	suite := newKeyAlgorithmCryptoSuite(nil, hlfv1alpha1.KeyAlgorithmECDSAP384)
+	g.Expect(generatedKey(suite)).To(BeNil())
+
+	key, err := suite.KeyGen(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(key.Private()).To(BeTrue())
+	found, err := suite.GetKey(key.SKI())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(Equal(key))
+
+	privKey := generatedKey(suite)
+	g.Expect(privKey).NotTo(BeNil())
+	g.Expect(privKey.Curve).To(Equal(elliptic.P384()))
+
+	digest := sha256.Sum256([]byte("enrollment"))
+	signature, err := suite.Sign(key, digest[:], nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	r, s, err := bccsputils.UnmarshalECDSASignature(signature)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ecdsa.Verify(&privKey.PublicKey, digest[:], r, s)).To(BeTrue())
+	lowS, err := bccsputils.IsLowS(&privKey.PublicKey, s)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(lowS).To(BeTrue())
+}
@@ -0,0 +1,15 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+package certs
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+)
+
+// PKCS11Supported is false when the operator is built without the pkcs11 tag, as with CGO disabled
+const PKCS11Supported = false
+
+func newPKCS11CryptoSuite(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	return nil, errPKCS11Unsupported
+}
@@ -0,0 +1,24 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+package certs
+
+import (
+	"testing"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	. "github.com/onsi/gomega"
+)
+
+func TestGetPKCS11ParamsWithoutPKCS11Support(t *testing.T) {
+	g := NewWithT(t)
+	params, err := GetPKCS11Params(nil, "default", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(params).To(BeNil())
+
+	_, err = GetPKCS11Params(nil, "default", &hlfv1alpha1.BCCSPPKCS11{
+		Library: "/usr/lib/softhsm/libsofthsm2.so",
+		Label:   "hlf",
+	})
+	g.Expect(err).To(MatchError(errPKCS11Unsupported))
+}
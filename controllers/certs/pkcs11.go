@@ -0,0 +1,16 @@
+//go:build pkcs11
+// +build pkcs11
+
+package certs
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
+)
+
+// PKCS11Supported is true when the operator is built with the pkcs11 tag
+const PKCS11Supported = true
+
+func newPKCS11CryptoSuite(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	return pkcs11.GetSuiteByConfig(config)
+}
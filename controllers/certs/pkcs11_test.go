@@ -0,0 +1,34 @@
+//go:build pkcs11
+// +build pkcs11
+
+package certs
+
+import (
+	"crypto/x509/pkix"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestCreatePKCS11CA generates the CA key in a SoftHSM token, initialized e.g. with
+// softhsm2-util --init-token --free --label hlf --so-pin 1234 --pin 98765432
+func TestCreatePKCS11CA(t *testing.T) {
+	library := os.Getenv("PKCS11_LIB")
+	if library == "" {
+		t.Skip("PKCS11_LIB isn't set, e.g. /usr/lib/softhsm/libsofthsm2.so")
+	}
+	g := NewWithT(t)
+	params := &PKCS11Params{
+		Library:  library,
+		Label:    os.Getenv("PKCS11_LABEL"),
+		Pin:      os.Getenv("PKCS11_PIN"),
+		Hash:     "SHA2",
+		Security: 256,
+	}
+	crt, err := CreatePKCS11CA(params, pkix.Name{CommonName: "ca", Organization: []string{"Org1MSP"}}, nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(crt.IsCA).To(BeTrue())
+	g.Expect(crt.CheckSignatureFrom(crt)).To(Succeed())
+	g.Expect(crt.SubjectKeyId).NotTo(BeEmpty())
+}
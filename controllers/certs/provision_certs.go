@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -89,6 +90,10 @@ type FabricCAParams struct {
 	MSPID        string
 	EnrollID     string
 	EnrollSecret string
+	// keys are generated in the HSM when set
+	PKCS11 *PKCS11Params
+	// ECDSA_P256 or ECDSA_P384, only honored by the software key store
+	KeyAlgorithm string
 }
 
 func getFabricConfig(params FabricCAParams) (*FabricConfig, error) {
@@ -152,6 +157,10 @@ type EnrollUserRequest struct {
 	CN         string
 	Profile    string
 	Attributes []*api.AttributeRequest
+	// ECDSA_P256 or ECDSA_P384, ECDSA_P256 when empty
+	KeyAlgorithm string
+	// the private key is generated in the HSM and not returned when set
+	PKCS11 *PKCS11Params
 }
 type GetCAInfoRequest struct {
 	TLSCert string
@@ -171,6 +180,9 @@ type RegisterUserRequest struct {
 	Secret       string
 	Type         string
 	Attributes   []api.Attribute
+	Affiliation  string
+	// unlimited enrollments when nil, zero takes the max enrollments of the registry of the CA
+	MaxEnrollments *int
 }
 
 const (
@@ -190,11 +202,15 @@ func RegisterUser(params RegisterUserRequest) (string, error) {
 		return "", err
 	}
 
+	maxEnrollments := -1
+	if params.MaxEnrollments != nil {
+		maxEnrollments = *params.MaxEnrollments
+	}
 	secret, err := caClient.Register(&api.RegistrationRequest{
 		Name:           params.User,
 		Type:           params.Type,
-		MaxEnrollments: -1,
-		Affiliation:    "",
+		MaxEnrollments: maxEnrollments,
+		Affiliation:    params.Affiliation,
 		Attributes:     params.Attributes,
 		CAName:         params.Name,
 		Secret:         params.Secret,
@@ -227,18 +243,31 @@ func GetCAInfo(params GetCAInfoRequest) (*api.GetCAInfoResponse, error) {
 }
 
 func EnrollUser(params EnrollUserRequest) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
-	keystorePath, err := ioutil.TempDir("", "enroll")
+	crypto, err := EnrollUserCrypto(params)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	caClient, _, mgr, _, err := GetClient(FabricCAParams{
-		TLSCert: params.TLSCert,
-		URL:     params.URL,
-		Name:    params.Name,
-		MSPID:   params.MSPID,
+	return crypto.Cert, crypto.Key, crypto.RootCert, nil
+}
+
+// EnrollUserCrypto enrolls the user and returns its certificate along with the root and the intermediate
+// certificates of the CA chain, so that identities issued by an intermediate CA can be validated
+func EnrollUserCrypto(params EnrollUserRequest) (*CryptoMaterial, error) {
+	keystorePath, err := ioutil.TempDir("", "enroll")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(keystorePath)
+	caClient, _, mgr, cryptoSuite, err := GetClient(FabricCAParams{
+		TLSCert:      params.TLSCert,
+		URL:          params.URL,
+		Name:         params.Name,
+		MSPID:        params.MSPID,
+		PKCS11:       params.PKCS11,
+		KeyAlgorithm: params.KeyAlgorithm,
 	}, keystorePath)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 	err = caClient.Enroll(&api.EnrollmentRequest{
 		Name:     params.User,
@@ -254,37 +283,45 @@ func EnrollUser(params EnrollUserRequest) (*x509.Certificate, *ecdsa.PrivateKey,
 		},
 	})
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 	mgrIdentity := mgr[strings.ToLower(params.MSPID)].(*msp.IdentityManager)
 	u, err := mgrIdentity.GetUser(params.User)
 	if err != nil {
-		return nil, nil, nil, err
-	}
-	hexSubjectID := hex.EncodeToString(u.PrivateKey().SKI())
-	keyPath := fmt.Sprintf("%s/%s_sk", keystorePath, hexSubjectID)
-	pkBytes, err := ioutil.ReadFile(keyPath)
-	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	userKey, err := utils.ParseECDSAPrivateKey(pkBytes)
-	if err != nil {
-		return nil, nil, nil, err
+	userKey := generatedKey(cryptoSuite)
+	if userKey == nil && params.PKCS11 == nil {
+		hexSubjectID := hex.EncodeToString(u.PrivateKey().SKI())
+		keyPath := fmt.Sprintf("%s/%s_sk", keystorePath, hexSubjectID)
+		pkBytes, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		userKey, err = utils.ParseECDSAPrivateKey(pkBytes)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	userCrt, err := utils.ParseX509Certificate(u.EnrollmentCertificate())
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 	info, err := caClient.GetCAInfo()
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	rootCrt, err := utils.ParseX509Certificate(info.CAChain)
+	rootCrt, intermediateCerts, err := ParseCAChain(info.CAChain)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	return userCrt, userKey, rootCrt, nil
+	return &CryptoMaterial{
+		Cert:              userCrt,
+		Key:               userKey,
+		RootCert:          rootCrt,
+		IntermediateCerts: intermediateCerts,
+	}, nil
 }
 
 type GetUserRequest struct {
@@ -378,43 +415,11 @@ func (m *mockKeyStorePath) KeyStorePath() string {
 }
 
 func GetClient(ca FabricCAParams, keyStorePath string) (*msp.CAClientImpl, *msp.MemoryUserStore, map[string]mspprov.IdentityManager, core.CryptoSuite, error) {
-	m1 := &mockIsSecurityEnabled{}
-	m2 := &mockSecurityAlgorithm{}
-	m3 := &mockSecurityLevel{}
-	m4 := &mockSecurityProvider{}
-	m5 := &mockSoftVerify{}
-	m6 := &mockSecurityProviderLibPath{}
-	m7 := &mockSecurityProviderPin{}
-	m8 := &mockSecurityProviderLabel{}
-	m9 := &mockKeyStorePath{
-		Path: keyStorePath,
-	}
 	mspID := ca.MSPID
-	fabricConfig, err := getFabricConfig(ca)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	configYaml, err := yaml.Marshal(fabricConfig)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	configBackend, err := config.FromRaw(configYaml, "yaml")()
+	configBackend, cryptoSuite, err := getCryptoSuite(ca, keyStorePath)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	cryptSuiteConfig2 := cryptosuite.ConfigFromBackend(configBackend...)
-	cryptSuiteConfigOption, err := cryptosuite.BuildCryptoSuiteConfigFromOptions(
-		m1, m2, m3, m4, m5, m6, m7, m8, m9,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	cryptSuiteConfig1, ok := cryptSuiteConfigOption.(*cryptosuite.CryptoConfigOptions)
-	if !ok {
-		return nil, nil, nil, nil, errors.New(fmt.Sprintf("BuildCryptoSuiteConfigFromOptions did not return an Options instance %T", cryptSuiteConfigOption))
-	}
-	cryptSuiteConfig := cryptosuite.UpdateMissingOptsWithDefaultConfig(cryptSuiteConfig1, cryptSuiteConfig2)
-
 	endpointConfig, err := fabImpl.ConfigFromBackend(configBackend...)
 	if err != nil {
 		return nil, nil, nil, nil, err
@@ -423,10 +428,6 @@ func GetClient(ca FabricCAParams, keyStorePath string) (*msp.CAClientImpl, *msp.
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	cryptoSuite, err := sw.GetSuiteByConfig(cryptSuiteConfig)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
 	userStore := msp.NewMemoryUserStore()
 	identityManagers := make(map[string]mspprov.IdentityManager)
 	netConfig := endpointConfig.NetworkConfig()
@@ -458,6 +459,64 @@ func GetClient(ca FabricCAParams, keyStorePath string) (*msp.CAClientImpl, *msp.
 	return client, userStore, identityManagers, cryptoSuite, nil
 }
 
+// getCryptoSuite returns the SDK configuration of the CA and the crypto suite keeping the keys of the enrollments in
+// the key store path, or in the HSM
+func getCryptoSuite(ca FabricCAParams, keyStorePath string) ([]core.ConfigBackend, core.CryptoSuite, error) {
+	m1 := &mockIsSecurityEnabled{}
+	m2 := &mockSecurityAlgorithm{}
+	m3 := &mockSecurityLevel{}
+	m4 := &mockSecurityProvider{}
+	m5 := &mockSoftVerify{}
+	m6 := &mockSecurityProviderLibPath{}
+	m7 := &mockSecurityProviderPin{}
+	m8 := &mockSecurityProviderLabel{}
+	m9 := &mockKeyStorePath{
+		Path: keyStorePath,
+	}
+	fabricConfig, err := getFabricConfig(ca)
+	if err != nil {
+		return nil, nil, err
+	}
+	configYaml, err := yaml.Marshal(fabricConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	configBackend, err := config.FromRaw(configYaml, "yaml")()
+	if err != nil {
+		return nil, nil, err
+	}
+	cryptSuiteConfig2 := cryptosuite.ConfigFromBackend(configBackend...)
+	cryptSuiteConfigOption, err := cryptosuite.BuildCryptoSuiteConfigFromOptions(
+		m1, m2, m3, m4, m5, m6, m7, m8, m9,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	cryptSuiteConfig1, ok := cryptSuiteConfigOption.(*cryptosuite.CryptoConfigOptions)
+	if !ok {
+		return nil, nil, errors.New(fmt.Sprintf("BuildCryptoSuiteConfigFromOptions did not return an Options instance %T", cryptSuiteConfigOption))
+	}
+	cryptSuiteConfig := cryptosuite.UpdateMissingOptsWithDefaultConfig(cryptSuiteConfig1, cryptSuiteConfig2)
+
+	keyAlgorithm, err := GetKeyAlgorithm(ca.KeyAlgorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cryptoSuite core.CryptoSuite
+	if ca.PKCS11 != nil {
+		cryptoSuite, err = newPKCS11CryptoSuite(&pkcs11CryptoConfig{params: ca.PKCS11})
+	} else {
+		cryptoSuite, err = sw.GetSuiteByConfig(cryptSuiteConfig)
+		if err == nil {
+			cryptoSuite = newKeyAlgorithmCryptoSuite(cryptoSuite, keyAlgorithm)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return configBackend, cryptoSuite, nil
+}
+
 type identityManagerProvider struct {
 	identityManager map[string]mspprov.IdentityManager
 }
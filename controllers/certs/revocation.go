@@ -0,0 +1,134 @@
+package certs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	calib "github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric-ca/lib"
+	caapi "github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric-ca/sdkinternal/pkg/api"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric-ca/sdkinternal/pkg/util"
+)
+
+// RevocationReasons are the reasons accepted by the CA to revoke a certificate
+var RevocationReasons = []string{
+	"unspecified",
+	"keycompromise",
+	"cacompromise",
+	"affiliationchanged",
+	"superseded",
+	"cessationofoperation",
+	"certificatehold",
+	"removefromcrl",
+	"privilegewithdrawn",
+	"aacompromise",
+}
+
+// ValidateRevocationReason checks the reason is one of RevocationReasons, empty means unspecified
+func ValidateRevocationReason(reason string) error {
+	if reason == "" {
+		return nil
+	}
+	for _, r := range RevocationReasons {
+		if strings.ToLower(reason) == r {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid revocation reason %s, supported reasons are %s", reason, strings.Join(RevocationReasons, ", "))
+}
+
+type RevokeRequest struct {
+	// ID of the identity whose certificates are revoked, Serial and AKI are required when empty
+	ID     string
+	Serial string
+	AKI    string
+	Reason string
+}
+
+// Revoke revokes the certificates of an identity or a single certificate, the response includes the CRL of the CA
+func Revoke(params RegistrarParams, request RevokeRequest) (*api.RevocationResponse, error) {
+	if request.ID == "" && (request.Serial == "" || request.AKI == "") {
+		return nil, fmt.Errorf("the enrollment ID or the serial and AKI of the certificate are required")
+	}
+	caClient, err := params.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return caClient.Revoke(&api.RevocationRequest{
+		Name:   request.ID,
+		Serial: request.Serial,
+		AKI:    request.AKI,
+		Reason: request.Reason,
+		CAName: params.Name,
+		GenCRL: true,
+	})
+}
+
+type GenCRLRequest struct {
+	RevokedAfter  time.Time
+	RevokedBefore time.Time
+	ExpireAfter   time.Time
+	ExpireBefore  time.Time
+}
+
+type genCRLResponseNet struct {
+	CRL string
+}
+
+// GenCRL returns the PEM encoded CRL of the CA with the unexpired revoked certificates in the time ranges of the request
+func GenCRL(params RegistrarParams, request GenCRLRequest) ([]byte, error) {
+	// the sdk client doesn't expose gencrl, the registrar is enrolled once with the fabric-ca client instead
+	mspDir, err := ioutil.TempDir("", "gencrl")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(mspDir)
+	_, cryptoSuite, err := getCryptoSuite(FabricCAParams{
+		TLSCert:      params.TLSCert,
+		URL:          params.URL,
+		Name:         params.Name,
+		MSPID:        params.MSPID,
+		EnrollID:     params.EnrollID,
+		EnrollSecret: params.EnrollSecret,
+	}, mspDir)
+	if err != nil {
+		return nil, err
+	}
+	client := &calib.Client{
+		Config: &calib.ClientConfig{
+			URL:    params.URL,
+			MSPDir: mspDir,
+			CAName: params.Name,
+			CSP:    cryptoSuite,
+		},
+	}
+	client.Config.TLS.Enabled = true
+	client.Config.TLS.CertFiles = [][]byte{[]byte(params.TLSCert)}
+	enrollment, err := client.Enroll(&caapi.EnrollmentRequest{
+		Name:   params.EnrollID,
+		Secret: params.EnrollSecret,
+		CAName: params.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	reqBody, err := util.Marshal(caapi.GenCRLRequest{
+		CAName:        params.Name,
+		RevokedAfter:  request.RevokedAfter,
+		RevokedBefore: request.RevokedBefore,
+		ExpireAfter:   request.ExpireAfter,
+		ExpireBefore:  request.ExpireBefore,
+	}, "GenCRL")
+	if err != nil {
+		return nil, err
+	}
+	var result genCRLResponseNet
+	err = enrollment.Identity.Post("gencrl", reqBody, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	return util.B64Decode(result.CRL)
+}
@@ -0,0 +1,265 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"k8s.io/client-go/kubernetes"
+)
+
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultParams are the settings to reach Vault and the PKI role issuing the signing identity
+type VaultParams struct {
+	Address    string
+	CACert     string
+	Token      string
+	AuthRole   string
+	AuthPath   string
+	AgentRole  string
+	Mount      string
+	PKIRole    string
+	CommonName string
+	TTL        string
+	// OU of the node type the PKI role must set, peer or orderer
+	NodeOU string
+}
+
+// GetVaultParams resolves the Vault settings of the signing identity of a node of type nodeOU, nil is returned when
+// the key is kept in a Secret
+func GetVaultParams(client *kubernetes.Clientset, namespace string, conf *hlfv1alpha1.Component, nodeOU string) (*VaultParams, error) {
+	if conf.Vault == nil {
+		return nil, nil
+	}
+	vault := conf.Vault
+	params := &VaultParams{
+		Address:    strings.TrimSuffix(vault.Address, "/"),
+		AuthRole:   vault.Auth.Role,
+		AuthPath:   vault.Auth.Path,
+		AgentRole:  vault.AgentRole,
+		Mount:      vault.PKI.Mount,
+		PKIRole:    vault.PKI.Role,
+		CommonName: vault.PKI.CommonName,
+		TTL:        vault.PKI.TTL,
+		NodeOU:     nodeOU,
+	}
+	if params.PKIRole == "" {
+		return nil, errors.New("vault requires a PKI role issuing the signing certificate")
+	}
+	if vault.Catls.Cacert != "" {
+		caCert, err := base64.StdEncoding.DecodeString(vault.Catls.Cacert)
+		if err != nil {
+			return nil, err
+		}
+		params.CACert = string(caCert)
+	}
+	if vault.Auth.TokenRef != nil {
+		token, err := utils.ResolveSecretValue(client, namespace, "", vault.Auth.TokenRef)
+		if err != nil {
+			return nil, err
+		}
+		params.Token = token
+	} else if params.AuthRole == "" {
+		return nil, errors.New("vault auth requires either a token or a role of the Kubernetes auth method")
+	}
+	if params.AuthPath == "" {
+		params.AuthPath = "kubernetes"
+	}
+	if params.Mount == "" {
+		params.Mount = "pki"
+	}
+	if params.CommonName == "" {
+		params.CommonName = conf.Enrollid
+	}
+	return params, nil
+}
+
+type vaultError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *vaultError) Error() string {
+	return fmt.Sprintf("vault responded with status %d: %s", e.StatusCode, strings.Join(e.Errors, ", "))
+}
+
+type vaultClient struct {
+	address    string
+	token      string
+	httpClient *http.Client
+}
+
+func newVaultClient(params *VaultParams) (*vaultClient, error) {
+	tlsConfig := &tls.Config{}
+	if params.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(params.CACert)) {
+			return nil, errors.New("invalid vault CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	c := &vaultClient{
+		address: params.Address,
+		token:   params.Token,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+	if c.token == "" {
+		jwt, err := ioutil.ReadFile(serviceAccountTokenPath)
+		if err != nil {
+			return nil, err
+		}
+		var resp struct {
+			Auth struct {
+				ClientToken string `json:"client_token"`
+			} `json:"auth"`
+		}
+		err = c.request(http.MethodPost, fmt.Sprintf("auth/%s/login", params.AuthPath), map[string]interface{}{
+			"role": params.AuthRole,
+			"jwt":  string(jwt),
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		c.token = resp.Auth.ClientToken
+	}
+	return c, nil
+}
+
+func (c *vaultClient) do(method string, path string, body interface{}) ([]byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/v1/%s", c.address, path), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Vault-Token", c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		vaultErr := &vaultError{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(data, vaultErr)
+		return nil, vaultErr
+	}
+	return data, nil
+}
+
+func (c *vaultClient) request(method string, path string, body interface{}, out interface{}) error {
+	data, err := c.do(method, path, body)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// GetVaultPKICryptoMaterial returns the CA chain of the Vault PKI mount, the signing certificate
+// and its key are issued in the node pod by the Vault agent and never reach the operator
+func GetVaultPKICryptoMaterial(params *VaultParams) (*CryptoMaterial, error) {
+	client, err := newVaultClient(params)
+	if err != nil {
+		return nil, err
+	}
+	err = checkVaultPKIRole(client, params)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := client.do(http.MethodGet, fmt.Sprintf("%s/ca_chain", params.Mount), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(chain)) == 0 {
+		chain, err = client.do(http.MethodGet, fmt.Sprintf("%s/ca/pem", params.Mount), nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rootCrt, intermediateCerts, err := ParseCAChain(chain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA chain of the vault mount %s: %w", params.Mount, err)
+	}
+	return &CryptoMaterial{
+		RootCert:          rootCrt,
+		IntermediateCerts: intermediateCerts,
+	}, nil
+}
+
+// checkVaultPKIRole checks that the PKI role sets the OU of the node type, the issue endpoint of Vault takes the OU of
+// the certificates from the role only, without it the identity isn't classified by the NodeOUs of the MSP
+func checkVaultPKIRole(client *vaultClient, params *VaultParams) error {
+	if params.NodeOU == "" {
+		return nil
+	}
+	var role struct {
+		Data struct {
+			OU []string `json:"ou"`
+		} `json:"data"`
+	}
+	err := client.request(http.MethodGet, fmt.Sprintf("%s/roles/%s", params.Mount, params.PKIRole), nil, &role)
+	if err != nil {
+		return fmt.Errorf("failed to read the PKI role %s of the vault mount %s: %w", params.PKIRole, params.Mount, err)
+	}
+	if !utils.Contains(role.Data.OU, params.NodeOU) {
+		return fmt.Errorf("PKI role %s of the vault mount %s must set ou=%s, it sets %v", params.PKIRole, params.Mount, params.NodeOU, role.Data.OU)
+	}
+	return nil
+}
+
+// VaultAgentAnnotations returns the pod annotations for the Vault agent injector to write the signing key and
+// certificate issued by Vault PKI to /vault/secrets before the node starts
+func VaultAgentAnnotations(params *VaultParams) map[string]string {
+	annotations := map[string]string{
+		"vault.hashicorp.com/agent-inject":            "true",
+		"vault.hashicorp.com/agent-pre-populate-only": "true",
+		"vault.hashicorp.com/role":                    params.AgentRole,
+		"vault.hashicorp.com/service":                 params.Address,
+	}
+	path := fmt.Sprintf("%s/issue/%s", params.Mount, params.PKIRole)
+	// both templates request the same certificate, the agent issues it once, the OU of the node comes from the role
+	args := fmt.Sprintf(`"%s" "common_name=%s"`, path, params.CommonName)
+	if params.TTL != "" {
+		args = fmt.Sprintf(`%s "ttl=%s"`, args, params.TTL)
+	}
+	annotations["vault.hashicorp.com/agent-inject-secret-key.pem"] = path
+	annotations["vault.hashicorp.com/agent-inject-template-key.pem"] = fmt.Sprintf(
+		`{{- with secret %s -}}{{ .Data.private_key }}{{- end }}`,
+		args,
+	)
+	annotations["vault.hashicorp.com/agent-inject-secret-cert.pem"] = path
+	annotations["vault.hashicorp.com/agent-inject-template-cert.pem"] = fmt.Sprintf(
+		`{{- with secret %s -}}{{ .Data.certificate }}{{- end }}`,
+		args,
+	)
+	return annotations
+}
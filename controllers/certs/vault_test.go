@@ -0,0 +1,176 @@
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestGetVaultParams(t *testing.T) {
+	g := NewWithT(t)
+	conf := &hlfv1alpha1.Component{
+		Enrollid: "peer",
+		Vault: &hlfv1alpha1.VaultKeyStore{
+			Address:   "http://vault.vault:8200/",
+			Auth:      hlfv1alpha1.VaultAuth{Role: "hlf-operator"},
+			AgentRole: "org1-peer0",
+			PKI:       hlfv1alpha1.VaultPKI{Role: "org1-peer"},
+		},
+	}
+	params, err := GetVaultParams(nil, "default", conf, "peer")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(params.Address).To(Equal("http://vault.vault:8200"))
+	g.Expect(params.AuthPath).To(Equal("kubernetes"))
+	g.Expect(params.Mount).To(Equal("pki"))
+	g.Expect(params.CommonName).To(Equal("peer"))
+	g.Expect(params.NodeOU).To(Equal("peer"))
+
+	conf.Vault.PKI.Role = ""
+	_, err = GetVaultParams(nil, "default", conf, "peer")
+	g.Expect(err).To(MatchError(ContainSubstring("PKI role")))
+
+	conf.Vault.PKI.Role = "org1-peer"
+	conf.Vault.Auth.Role = ""
+	_, err = GetVaultParams(nil, "default", conf, "peer")
+	g.Expect(err).To(MatchError(ContainSubstring("token or a role")))
+
+	params, err = GetVaultParams(nil, "default", &hlfv1alpha1.Component{}, "peer")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(params).To(BeNil())
+}
+
+func TestGetVaultPKICryptoMaterial(t *testing.T) {
+	g := NewWithT(t)
+	ca := newTestCA(t, "vault-ca")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/pki/ca_chain":
+			// a root CA mount has no chain
+		case "/v1/pki/ca/pem":
+			_, _ = w.Write(utils.EncodeX509Certificate(ca.Cert))
+		case "/v1/pki/roles/org1-peer":
+			_, _ = w.Write([]byte(`{"data":{"ou":["peer"]}}`))
+		case "/v1/pki/roles/org1-client":
+			_, _ = w.Write([]byte(`{"data":{"ou":[]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	crypto, err := GetVaultPKICryptoMaterial(&VaultParams{Address: server.URL, Token: "s.token", Mount: "pki"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(crypto.RootCert.Equal(ca.Cert)).To(BeTrue())
+	g.Expect(crypto.IntermediateCerts).To(BeEmpty())
+
+	_, err = GetVaultPKICryptoMaterial(&VaultParams{Address: server.URL, Token: "s.other", Mount: "pki"})
+	g.Expect(err).To(MatchError(ContainSubstring("status 403: permission denied")))
+
+	// the role must set the OU of the node, the issue endpoint doesn't take it
+	_, err = GetVaultPKICryptoMaterial(&VaultParams{Address: server.URL, Token: "s.token", Mount: "pki", PKIRole: "org1-peer", NodeOU: "peer"})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = GetVaultPKICryptoMaterial(&VaultParams{Address: server.URL, Token: "s.token", Mount: "pki", PKIRole: "org1-client", NodeOU: "peer"})
+	g.Expect(err).To(MatchError(ContainSubstring("PKI role org1-client of the vault mount pki must set ou=peer")))
+	_, err = GetVaultPKICryptoMaterial(&VaultParams{Address: server.URL, Token: "s.token", Mount: "pki", PKIRole: "org1-orderer", NodeOU: "orderer"})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to read the PKI role org1-orderer")))
+}
+
+func TestVaultAgentAnnotations(t *testing.T) {
+	g := NewWithT(t)
+	annotations := VaultAgentAnnotations(&VaultParams{
+		Address:    "http://vault.vault:8200",
+		AgentRole:  "org1-peer0",
+		Mount:      "pki",
+		PKIRole:    "org1-peer",
+		CommonName: "peer",
+		TTL:        "8760h",
+	})
+	g.Expect(annotations).To(HaveKeyWithValue("vault.hashicorp.com/role", "org1-peer0"))
+	g.Expect(annotations).To(HaveKeyWithValue("vault.hashicorp.com/agent-inject-secret-key.pem", "pki/issue/org1-peer"))
+	g.Expect(annotations).To(HaveKeyWithValue(
+		"vault.hashicorp.com/agent-inject-template-cert.pem",
+		`{{- with secret "pki/issue/org1-peer" "common_name=peer" "ttl=8760h" -}}{{ .Data.certificate }}{{- end }}`,
+	))
+	for key := range annotations {
+		g.Expect(key).NotTo(ContainSubstring("transit"))
+	}
+}
+
+// TestVaultDevServer runs against a Vault dev server, e.g. `vault server -dev -dev-root-token-id=root`
+// with VAULT_ADDR=http://127.0.0.1:8200 and VAULT_TOKEN=root
+func TestVaultDevServer(t *testing.T) {
+	address := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if address == "" || token == "" {
+		t.Skip("VAULT_ADDR and VAULT_TOKEN are not set")
+	}
+	g := NewWithT(t)
+	mount := fmt.Sprintf("pki-hlf-%d", time.Now().UnixNano())
+	params := &VaultParams{
+		Address:    strings.TrimSuffix(address, "/"),
+		Token:      token,
+		Mount:      mount,
+		PKIRole:    "peer",
+		CommonName: "peer0",
+		NodeOU:     "peer",
+	}
+	client, err := newVaultClient(params)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = client.request(http.MethodPost, fmt.Sprintf("sys/mounts/%s", mount), map[string]interface{}{"type": "pki"}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		_ = client.request(http.MethodDelete, fmt.Sprintf("sys/mounts/%s", mount), nil, nil)
+	}()
+	err = client.request(http.MethodPost, fmt.Sprintf("%s/root/generate/internal", mount), map[string]interface{}{
+		"common_name": "vault-ca",
+		"key_type":    "ec",
+		"key_bits":    256,
+	}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = client.request(http.MethodPost, fmt.Sprintf("%s/roles/peer", mount), map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"key_bits":       256,
+		"ou":             "peer",
+	}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	crypto, err := GetVaultPKICryptoMaterial(params)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(crypto.RootCert.Subject.CommonName).To(Equal("vault-ca"))
+
+	// the same request the Vault agent of the node does, the key never leaves the pod
+	var issued struct {
+		Data struct {
+			Certificate string `json:"certificate"`
+			PrivateKey  string `json:"private_key"`
+		} `json:"data"`
+	}
+	err = client.request(http.MethodPost, fmt.Sprintf("%s/issue/%s", mount, params.PKIRole), map[string]interface{}{
+		"common_name": params.CommonName,
+	}, &issued)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(issued.Data.PrivateKey).NotTo(BeEmpty())
+	block, _ := pem.Decode([]byte(issued.Data.Certificate))
+	g.Expect(block).NotTo(BeNil())
+	crt, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(crt.Subject.OrganizationalUnit).To(ContainElement("peer"))
+	g.Expect(crt.CheckSignatureFrom(crypto.RootCert)).To(Succeed())
+}
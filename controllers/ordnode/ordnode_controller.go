@@ -36,6 +36,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // FabricOrdererNodeReconciler reconciles a FabricOrdererNode object
@@ -127,6 +129,28 @@ func (r *FabricOrdererNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result,
 			return ctrl.Result{}, err
 		}
 	}
+	clientSet, err := utils.GetClientKubeWithConf(r.Config)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	resolvedOrdererNode := fabricOrdererNode.DeepCopy()
+	if resolvedOrdererNode.Spec.Secret != nil {
+		err = certs.ResolveComponentCA(ctx, r.Client, clientSet, ns, &resolvedOrdererNode.Spec.Secret.Enrollment.Component)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		err = certs.ResolveTLSCA(ctx, r.Client, clientSet, ns, &resolvedOrdererNode.Spec.Secret.Enrollment.TLS)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if tlsParams := resolvedOrdererNode.Spec.Secret.Enrollment.TLS; tlsParams.CertManager != nil {
+			hosts := append(append([]string{}, tlsParams.Csr.Hosts...), certs.ServiceHosts(releaseName, ns)...)
+			err = certs.EnsureCertManagerCertificate(ctx, r.Client, r.Scheme, fabricOrdererNode, resolvedOrdererNode.Name, tlsParams.CertManager, hosts)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
 	cmdStatus := action.NewStatus(cfg)
 	exists := true
 	_, err = cmdStatus.Run(releaseName)
@@ -140,10 +164,6 @@ func (r *FabricOrdererNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result,
 		}
 	}
 	log.Printf("Release %s exists=%v", releaseName, exists)
-	clientSet, err := utils.GetClientKubeWithConf(r.Config)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
 	if exists {
 		// update
 		s, err := GetOrdererState(cfg, r.Config, releaseName, ns)
@@ -155,6 +175,7 @@ func (r *FabricOrdererNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result,
 		fOrderer.Status.Status = s.Status
 		fOrderer.Status.NodePort = s.NodePort
 		fOrderer.Status.TlsCert = s.TlsCert
+		fOrderer.Status.TlsCACert = s.TlsCACert
 		fOrderer.Status.TlsAdminCert = s.TlsAdminCert
 		fOrderer.Status.AdminPort = s.AdminPort
 		fOrderer.Status.OperationsPort = s.OperationsPort
@@ -164,7 +185,7 @@ func (r *FabricOrdererNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result,
 		})
 
 		log.Printf("Status hasn't changed, skipping update")
-		c, err := getConfig(fabricOrdererNode, clientSet, releaseName, req.Namespace)
+		c, err := getConfig(resolvedOrdererNode, clientSet, releaseName, req.Namespace)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -230,7 +251,7 @@ func (r *FabricOrdererNodeReconciler) Reconcile(req ctrl.Request) (ctrl.Result,
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		c, err := getConfig(fabricOrdererNode, clientSet, releaseName, req.Namespace)
+		c, err := getConfig(resolvedOrdererNode, clientSet, releaseName, req.Namespace)
 		if err != nil {
 			reqLogger.Error(err, fmt.Sprintf("Failed to get config for orderer %s/%s", req.Namespace, req.Name))
 			return ctrl.Result{}, err
@@ -320,9 +341,20 @@ func setConditionStatus(p *hlfv1alpha1.FabricOrdererNode, conditionType hlfv1alp
 }
 
 func (r *FabricOrdererNodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	newList := func() runtime.Object {
+		return &hlfv1alpha1.FabricOrdererNodeList{}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hlfv1alpha1.FabricOrdererNode{}).
 		Owns(&appsv1.Deployment{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.SecretReferrersMapper("orderer nodes", r.List, newList)},
+		).
+		Watches(
+			&source.Kind{Type: &hlfv1alpha1.FabricCA{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.CAReferrersMapper("orderer nodes", r.List, newList)},
+		).
 		Complete(r)
 }
 
@@ -386,6 +418,23 @@ func getExistingTLSCrypto(client *kubernetes.Clientset, chartName string, namesp
 	return crt, key, rootCrt, nil
 }
 
+// getExistingTLSIntermediateCerts returns the intermediate certificates served along the TLS certificate
+func getExistingTLSIntermediateCerts(client *kubernetes.Clientset, chartName string, namespace string) (string, error) {
+	secretName := fmt.Sprintf("%s-tls", chartName)
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.Background(), secretName, v1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	chain, err := certs.ParseCertificates(secret.Data["tls.crt"])
+	if err != nil {
+		return "", err
+	}
+	if len(chain) == 0 {
+		return "", nil
+	}
+	return certs.EncodeCertificates(chain[1:]), nil
+}
+
 func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, namespace string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
 	secretCrtName := fmt.Sprintf("%s-idcert", chartName)
 	secretKeyName := fmt.Sprintf("%s-idkey", chartName)
@@ -414,6 +463,10 @@ func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, names
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if len(signKeyData) == 0 {
+		// the key is kept in the HSM
+		return crt, nil, rootCrt, nil
+	}
 	key, err := utils.ParseECDSAPrivateKey(signKeyData)
 	if err != nil {
 		return nil, nil, nil, err
@@ -421,38 +474,36 @@ func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, names
 	return crt, key, rootCrt, nil
 }
 
-func CreateTLSCryptoMaterial(conf *hlfv1alpha1.FabricOrdererNode, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, hosts []string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
-	tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(certs.EnrollUserRequest{
-		TLSCert:    tlsCertString,
-		URL:        caurl,
-		Name:       caName,
-		MSPID:      conf.Spec.MspID,
-		User:       enrollID,
-		Secret:     enrollSecret,
-		Hosts:      hosts,
-		CN:         "",
-		Profile:    "tls",
-		Attributes: nil,
+func CreateTLSCryptoMaterial(conf *hlfv1alpha1.FabricOrdererNode, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, hosts []string) (*certs.CryptoMaterial, error) {
+	return certs.EnrollUserCrypto(certs.EnrollUserRequest{
+		TLSCert:      tlsCertString,
+		URL:          caurl,
+		Name:         caName,
+		MSPID:        conf.Spec.MspID,
+		User:         enrollID,
+		Secret:       enrollSecret,
+		Hosts:        hosts,
+		CN:           "",
+		Profile:      conf.Spec.Secret.Enrollment.TLS.EnrollProfile(),
+		KeyAlgorithm: string(conf.Spec.Secret.Enrollment.TLS.KeyAlgorithm),
+		Attributes:   nil,
 	})
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	return tlsCert, tlsKey, tlsRootCert, nil
 }
 
 func CreateTLSAdminCryptoMaterial(conf *hlfv1alpha1.FabricOrdererNode, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, hosts []string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, *x509.Certificate, error) {
 	tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(
 		certs.EnrollUserRequest{
-			TLSCert:    tlsCertString,
-			URL:        caurl,
-			Name:       caName,
-			MSPID:      conf.Spec.MspID,
-			User:       enrollID,
-			Secret:     enrollSecret,
-			Hosts:      hosts,
-			CN:         "",
-			Profile:    "tls",
-			Attributes: nil,
+			TLSCert:      tlsCertString,
+			URL:          caurl,
+			Name:         caName,
+			MSPID:        conf.Spec.MspID,
+			User:         enrollID,
+			Secret:       enrollSecret,
+			Hosts:        hosts,
+			CN:           "",
+			Profile:      conf.Spec.Secret.Enrollment.TLS.EnrollProfile(),
+			KeyAlgorithm: string(conf.Spec.Secret.Enrollment.TLS.KeyAlgorithm),
+			Attributes:   nil,
 		},
 	)
 	if err != nil {
@@ -461,19 +512,18 @@ func CreateTLSAdminCryptoMaterial(conf *hlfv1alpha1.FabricOrdererNode, caName st
 	return tlsCert, tlsKey, tlsRootCert, tlsRootCert, nil
 }
 
-func CreateSignCryptoMaterial(conf *hlfv1alpha1.FabricOrdererNode, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
-	tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(certs.EnrollUserRequest{
-		TLSCert: tlsCertString,
-		URL:     caurl,
-		Name:    caName,
-		MSPID:   conf.Spec.MspID,
-		User:    enrollID,
-		Secret:  enrollSecret,
+func CreateSignCryptoMaterial(conf *hlfv1alpha1.FabricOrdererNode, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, pkcs11Params *certs.PKCS11Params) (*certs.CryptoMaterial, error) {
+	return certs.EnrollUserCrypto(certs.EnrollUserRequest{
+		TLSCert:      tlsCertString,
+		URL:          caurl,
+		Name:         caName,
+		MSPID:        conf.Spec.MspID,
+		User:         enrollID,
+		Secret:       enrollSecret,
+		Profile:      conf.Spec.Secret.Enrollment.Component.Profile,
+		KeyAlgorithm: string(conf.Spec.Secret.Enrollment.Component.KeyAlgorithm),
+		PKCS11:       pkcs11Params,
 	})
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	return tlsCert, tlsKey, tlsRootCert, nil
 }
 
 func getConfig(conf *hlfv1alpha1.FabricOrdererNode, client *kubernetes.Clientset, chartName string, namespace string) (*fabricOrdChart, error) {
@@ -483,64 +533,149 @@ func getConfig(conf *hlfv1alpha1.FabricOrdererNode, client *kubernetes.Clientset
 	tlsHosts := []string{}
 	ingressHosts := []string{}
 	tlsHosts = append(tlsHosts, tlsParams.Csr.Hosts...)
-	tlsCert, tlsKey, tlsRootCert, err := getExistingTLSCrypto(client, chartName, namespace)
+	var tlsCert, tlsRootCert, adminCert, adminRootCert, adminClientRootCert, signCert, signRootCert *x509.Certificate
+	var tlsKey, adminKey, signKey *ecdsa.PrivateKey
+	var intCACert, tlsIntermediateCerts string
+	pkcs11Params, err := certs.GetPKCS11Params(client, namespace, spec.BCCSP.PKCS11Config())
 	if err != nil {
-		cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
-		if err != nil {
-			return nil, err
-		}
-		tlsCert, tlsKey, tlsRootCert, err = CreateTLSCryptoMaterial(
-			conf,
-			tlsParams.Caname,
-			tlsCAUrl,
-			tlsParams.Enrollid,
-			tlsParams.Enrollsecret,
-			string(cacert),
-			tlsHosts,
-		)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
-
-	adminCert, adminKey, adminRootCert, adminClientRootCert, err := getExistingTLSAdminCrypto(client, chartName, namespace)
+	vaultParams, err := certs.GetVaultParams(client, namespace, &spec.Secret.Enrollment.Component, "orderer")
 	if err != nil {
-		cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
-		if err != nil {
-			return nil, err
+		return nil, err
+	}
+	if pkcs11Params != nil && vaultParams != nil {
+		return nil, errors.New("the signing key can't be kept both in the HSM and in Vault")
+	}
+	if !conf.Spec.Secret.EnrollsAgainstCA() {
+		if pkcs11Params != nil {
+			return nil, errors.New("the PKCS11 BCCSP requires enrolling the signing identity against a CA")
 		}
-		adminCert, adminKey, adminRootCert, adminClientRootCert, err = CreateTLSAdminCryptoMaterial(
-			conf,
-			tlsParams.Caname,
-			tlsCAUrl,
-			tlsParams.Enrollid,
-			tlsParams.Enrollsecret,
-			string(cacert),
+		if vaultParams != nil {
+			return nil, errors.New("Vault PKI issues the signing identity, it can't be combined with provided or generated crypto material")
+		}
+		signCrypto, tlsCrypto, err := certs.GetNodeCryptoMaterial(
+			client,
+			namespace,
+			*v1.NewControllerRef(conf, hlfv1alpha1.GroupVersion.WithKind("FabricOrdererNode")),
+			chartName,
+			spec.MspID,
+			"orderer",
+			conf.Spec.Secret,
 			tlsHosts,
 		)
 		if err != nil {
 			return nil, err
 		}
-	}
-	signParams := conf.Spec.Secret.Enrollment.Component
-	caUrl := fmt.Sprintf("https://%s:%d", signParams.Cahost, signParams.Caport)
-	signCert, signKey, signRootCert, err := getExistingSignCrypto(client, chartName, namespace)
-	if err != nil {
-		cacert, err := base64.StdEncoding.DecodeString(signParams.Catls.Cacert)
-		if err != nil {
-			return nil, err
+		tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+		adminCert, adminKey, adminRootCert, adminClientRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert, tlsCrypto.RootCert
+		signCert, signKey, signRootCert = signCrypto.Cert, signCrypto.Key, signCrypto.RootCert
+		intCACert = signCrypto.EncodedIntermediateCerts()
+		// the orderer MSP has no TLS intermediates folder, so the chain is served along the TLS certificate
+		tlsIntermediateCerts = tlsCrypto.EncodedIntermediateCerts()
+	} else {
+		if tlsParams.CertManager != nil {
+			tlsCrypto, err := certs.GetCertManagerCryptoMaterial(client, namespace, conf.Name)
+			if err != nil {
+				return nil, err
+			}
+			tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+			adminCert, adminKey, adminRootCert, adminClientRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert, tlsCrypto.RootCert
+			tlsIntermediateCerts = tlsCrypto.EncodedIntermediateCerts()
+		} else {
+			tlsEnrollSecret, err := utils.ResolveSecretValue(client, namespace, tlsParams.Enrollsecret, tlsParams.EnrollsecretRef)
+			if err != nil {
+				return nil, err
+			}
+			tlsCert, tlsKey, tlsRootCert, err = getExistingTLSCrypto(client, chartName, namespace)
+			if err == nil {
+				tlsIntermediateCerts, err = getExistingTLSIntermediateCerts(client, chartName, namespace)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
+				if err != nil {
+					return nil, err
+				}
+				tlsCrypto, err := CreateTLSCryptoMaterial(
+					conf,
+					tlsParams.Caname,
+					tlsCAUrl,
+					tlsParams.Enrollid,
+					tlsEnrollSecret,
+					string(cacert),
+					tlsHosts,
+				)
+				if err != nil {
+					return nil, err
+				}
+				tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+				tlsIntermediateCerts = tlsCrypto.EncodedIntermediateCerts()
+			}
+
+			adminCert, adminKey, adminRootCert, adminClientRootCert, err = getExistingTLSAdminCrypto(client, chartName, namespace)
+			if err != nil {
+				cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
+				if err != nil {
+					return nil, err
+				}
+				adminCert, adminKey, adminRootCert, adminClientRootCert, err = CreateTLSAdminCryptoMaterial(
+					conf,
+					tlsParams.Caname,
+					tlsCAUrl,
+					tlsParams.Enrollid,
+					tlsEnrollSecret,
+					string(cacert),
+					tlsHosts,
+				)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
-		signCert, signKey, signRootCert, err = CreateSignCryptoMaterial(
-			conf,
-			signParams.Caname,
-			caUrl,
-			signParams.Enrollid,
-			signParams.Enrollsecret,
-			string(cacert),
-		)
+		signParams := conf.Spec.Secret.Enrollment.Component
+		caUrl := fmt.Sprintf("https://%s:%d", signParams.Cahost, signParams.Caport)
+		signEnrollSecret, err := utils.ResolveSecretValue(client, namespace, signParams.Enrollsecret, signParams.EnrollsecretRef)
 		if err != nil {
 			return nil, err
 		}
+		if vaultParams != nil {
+			// the signing certificate is issued in the pod by the Vault agent
+			signCrypto, err := certs.GetVaultPKICryptoMaterial(vaultParams)
+			if err != nil {
+				return nil, err
+			}
+			signRootCert = signCrypto.RootCert
+			intCACert = signCrypto.EncodedIntermediateCerts()
+		} else {
+			signCert, signKey, signRootCert, err = getExistingSignCrypto(client, chartName, namespace)
+			if err == nil {
+				intCACert, err = certs.GetIntermediateCertsFromSecret(client, namespace, fmt.Sprintf("%s-intcacert", chartName))
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				cacert, err := base64.StdEncoding.DecodeString(signParams.Catls.Cacert)
+				if err != nil {
+					return nil, err
+				}
+				signCrypto, err := CreateSignCryptoMaterial(
+					conf,
+					signParams.Caname,
+					caUrl,
+					signParams.Enrollid,
+					signEnrollSecret,
+					string(cacert),
+					pkcs11Params,
+				)
+				if err != nil {
+					return nil, err
+				}
+				signCert, signKey, signRootCert = signCrypto.Cert, signCrypto.Key, signCrypto.RootCert
+				intCACert = signCrypto.EncodedIntermediateCerts()
+			}
+		}
 	}
 	tlsCRTEncoded := pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
@@ -572,17 +707,23 @@ func getConfig(conf *hlfv1alpha1.FabricOrdererNode, client *kubernetes.Clientset
 		return nil, err
 	}
 
-	signCRTEncoded := pem.EncodeToMemory(&pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: signCert.Raw,
-	})
+	var signCRTEncoded []byte
+	if signCert != nil {
+		signCRTEncoded = pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: signCert.Raw,
+		})
+	}
 	signRootCRTEncoded := pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: signRootCert.Raw,
 	})
-	signEncodedPK, err := utils.EncodePrivateKey(signKey)
-	if err != nil {
-		return nil, err
+	var signEncodedPK []byte
+	if signKey != nil {
+		signEncodedPK, err = utils.EncodePrivateKey(signKey)
+		if err != nil {
+			return nil, err
+		}
 	}
 	var hostAliases []HostAlias
 	for _, hostAlias := range spec.HostAliases {
@@ -653,7 +794,38 @@ func getConfig(conf *hlfv1alpha1.FabricOrdererNode, client *kubernetes.Clientset
 			Memory: spec.Resources.Limits.Memory().String(),
 		},
 	}
+	var hsmConf hsm
+	if pkcs11Conf := spec.BCCSP.PKCS11Config(); pkcs11Conf != nil {
+		hsmConf = hsm{
+			Enabled:  true,
+			Library:  pkcs11Params.Library,
+			Label:    pkcs11Params.Label,
+			Hash:     pkcs11Params.Hash,
+			Security: pkcs11Params.Security,
+			PinSecret: hsmPinSecret{
+				Name: pkcs11Conf.PinRef.Name,
+				Key:  pkcs11Conf.PinRef.Key,
+			},
+			LibraryImage: pkcs11Conf.LibraryImage,
+			Env:          pkcs11Conf.Env,
+		}
+		if pkcs11Conf.Volume != nil {
+			hsmConf.Volume = hsmVolume{
+				ClaimName: pkcs11Conf.Volume.ClaimName,
+				MountPath: pkcs11Conf.Volume.MountPath,
+			}
+		}
+	}
+	var vaultConf vault
+	if vaultParams != nil {
+		vaultConf = vault{
+			Enabled:     true,
+			Annotations: certs.VaultAgentAnnotations(vaultParams),
+		}
+	}
 	fabricOrdChart := fabricOrdChart{
+		HSM:                         hsmConf,
+		Vault:                       vaultConf,
 		Resources:                   resources,
 		Istio:                       istio,
 		AdminIstio:                  adminIstio,
@@ -668,12 +840,13 @@ func getConfig(conf *hlfv1alpha1.FabricOrdererNode, client *kubernetes.Clientset
 			ClientRootCAs: string(adminClientRootCRTEncoded),
 		},
 		Cacert:      string(signRootCRTEncoded),
+		IntCACert:   intCACert,
 		Tlsrootcert: string(tlsRootCRTEncoded),
 		AdminCert:   "",
 		Cert:        string(signCRTEncoded),
 		Key:         string(signEncodedPK),
 		TLS: tls{
-			Cert: string(tlsCRTEncoded),
+			Cert: string(tlsCRTEncoded) + tlsIntermediateCerts,
 			Key:  string(tlsEncodedPK),
 		},
 		FullnameOverride: conf.Name,
@@ -755,11 +928,12 @@ func GetOrdererState(conf *action.Configuration, config *rest.Config, releaseNam
 		Status:  hlfv1alpha1.RunningStatus,
 		Message: "",
 	}
-	tlsCrt, _, _, err := getExistingTLSCrypto(clientSet, releaseName, ns)
+	tlsCrt, _, tlsRootCrt, err := getExistingTLSCrypto(clientSet, releaseName, ns)
 	if err != nil {
 		return nil, err
 	}
 	r.TlsCert = string(utils.EncodeX509Certificate(tlsCrt))
+	r.TlsCACert = string(utils.EncodeX509Certificate(tlsRootCrt))
 	tlsAdminCrt, _, _, _, err := getExistingTLSAdminCrypto(clientSet, releaseName, ns)
 	if err != nil {
 		return nil, err
@@ -1,5 +1,7 @@
 package ordnode
 
+import corev1 "k8s.io/api/core/v1"
+
 type fabricOrdChart struct {
 	Istio                       Istio          `json:"istio"`
 	AdminIstio                  Istio          `json:"adminIstio"`
@@ -9,6 +11,7 @@ type fabricOrdChart struct {
 	BootstrapMethod             string         `json:"bootstrapMethod"`
 	Admin                       admin          `json:"admin"`
 	Cacert                      string         `json:"cacert"`
+	IntCACert                   string         `json:"intCACert"`
 	Tlsrootcert                 string         `json:"tlsrootcert"`
 	AdminCert                   string         `json:"adminCert"`
 	Cert                        string         `json:"cert"`
@@ -25,6 +28,8 @@ type fabricOrdChart struct {
 	Hosts                       []string       `json:"hosts"`
 	Logging                     Logging        `json:"logging"`
 	ServiceMonitor              ServiceMonitor `json:"serviceMonitor"`
+	HSM                         hsm            `json:"hsm"`
+	Vault                       vault          `json:"vault"`
 }
 type Resources struct {
 	Limits   Limits   `json:"limits"`
@@ -113,3 +118,27 @@ type Istio struct {
 	Hosts          []string `json:"hosts"`
 	IngressGateway string   `json:"ingressGateway"`
 }
+
+type hsm struct {
+	Enabled      bool            `json:"enabled"`
+	Library      string          `json:"library"`
+	Label        string          `json:"label"`
+	Hash         string          `json:"hash"`
+	Security     int             `json:"security"`
+	PinSecret    hsmPinSecret    `json:"pinSecret"`
+	LibraryImage string          `json:"libraryImage"`
+	Env          []corev1.EnvVar `json:"env"`
+	Volume       hsmVolume       `json:"volume"`
+}
+type hsmPinSecret struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+type hsmVolume struct {
+	ClaimName string `json:"claimName"`
+	MountPath string `json:"mountPath"`
+}
+type vault struct {
+	Enabled     bool              `json:"enabled"`
+	Annotations map[string]string `json:"annotations"`
+}
@@ -30,6 +30,7 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -38,6 +39,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // FabricOrderingServiceReconciler reconciles a FabricOrderingService object
@@ -138,25 +141,47 @@ func getConfig(conf *hlfv1alpha1.FabricOrderingService, client *kubernetes.Clien
 	if err != nil {
 		return nil, err
 	}
-	tlsCertStr, err := base64.StdEncoding.DecodeString(conf.Spec.Enrollment.TLS.Catls.Cacert)
+	certManager := conf.Spec.Enrollment.TLS.CertManager
+	var tlsCAPem, tlsIntermediateCerts string
+	if certManager == nil {
+		tlsCertStr, err := base64.StdEncoding.DecodeString(conf.Spec.Enrollment.TLS.Catls.Cacert)
+		if err != nil {
+			return nil, err
+		}
+		tlsCAInfo, err := certs.GetCAInfo(certs.GetCAInfoRequest{
+			TLSCert: string(tlsCertStr),
+			URL: fmt.Sprintf(
+				"https://%s:%d",
+				conf.Spec.Enrollment.TLS.Cahost,
+				conf.Spec.Enrollment.TLS.Caport,
+			),
+			Name:  conf.Spec.Enrollment.TLS.Caname,
+			MSPID: conf.Spec.MspID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tlsCAPem = string(tlsCAInfo.CAChain)
+	}
+	signCAPem := string(signCAInfo.CAChain)
+	tlsEnrollSecret, err := utils.ResolveSecretValue(
+		client,
+		conf.Namespace,
+		conf.Spec.Enrollment.TLS.Enrollsecret,
+		conf.Spec.Enrollment.TLS.EnrollsecretRef,
+	)
 	if err != nil {
 		return nil, err
 	}
-	tlsCAInfo, err := certs.GetCAInfo(certs.GetCAInfoRequest{
-		TLSCert: string(tlsCertStr),
-		URL: fmt.Sprintf(
-			"https://%s:%d",
-			conf.Spec.Enrollment.TLS.Cahost,
-			conf.Spec.Enrollment.TLS.Caport,
-		),
-		Name:  conf.Spec.Enrollment.TLS.Caname,
-		MSPID: conf.Spec.MspID,
-	})
+	signEnrollSecret, err := utils.ResolveSecretValue(
+		client,
+		conf.Namespace,
+		conf.Spec.Enrollment.Component.Enrollsecret,
+		conf.Spec.Enrollment.Component.EnrollsecretRef,
+	)
 	if err != nil {
 		return nil, err
 	}
-	tlsCAPem := string(tlsCAInfo.CAChain)
-	signCAPem := string(signCAInfo.CAChain)
 	ordererNodes := []testutils.OrdererNode{}
 	publicIP, err := utils.GetPublicIPKubernetes(client)
 	if err != nil {
@@ -170,36 +195,44 @@ func getConfig(conf *hlfv1alpha1.FabricOrderingService, client *kubernetes.Clien
 	}
 	nodes := []Node{}
 	for nodeIdx, node := range spec.Nodes {
-		tlsHosts := []string{}
-		for _, host := range node.Enrollment.TLS.Csr.Hosts {
-			tlsHosts = append(tlsHosts, host)
-		}
-		if !utils.Contains(tlsHosts, publicIP) {
-			tlsHosts = append(tlsHosts, publicIP)
-		}
-		tlsCertPEM, err := base64.StdEncoding.DecodeString(conf.Spec.Enrollment.TLS.Catls.Cacert)
-		if err != nil {
-			return nil, err
-		}
-
-		tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(certs.EnrollUserRequest{
-			TLSCert: string(tlsCertPEM),
-			URL: fmt.Sprintf(
-				"https://%s:%d",
-				conf.Spec.Enrollment.TLS.Cahost,
-				conf.Spec.Enrollment.TLS.Caport,
-			),
-			Name:       conf.Spec.Enrollment.TLS.Caname,
-			MSPID:      conf.Spec.MspID,
-			User:       conf.Spec.Enrollment.TLS.Enrollid,
-			Secret:     conf.Spec.Enrollment.TLS.Enrollsecret,
-			Hosts:      tlsHosts,
-			CN:         "",
-			Profile:    "tls",
-			Attributes: nil,
-		})
-		if err != nil {
-			return nil, err
+		var tlsCert, tlsRootCert *x509.Certificate
+		var tlsKey *ecdsa.PrivateKey
+		if certManager != nil {
+			tlsCrypto, err := certs.GetCertManagerCryptoMaterial(client, conf.Namespace, conf.NodeTLSCertificateName(node))
+			if err != nil {
+				return nil, err
+			}
+			tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+			// all the nodes are issued by the same issuer, which is the TLS CA of the organization
+			if tlsCAPem == "" {
+				tlsCAPem = string(utils.EncodeX509Certificate(tlsCrypto.RootCert))
+				tlsIntermediateCerts = tlsCrypto.EncodedIntermediateCerts()
+			}
+		} else {
+			tlsCertPEM, err := base64.StdEncoding.DecodeString(conf.Spec.Enrollment.TLS.Catls.Cacert)
+			if err != nil {
+				return nil, err
+			}
+			tlsCert, tlsKey, tlsRootCert, err = certs.EnrollUser(certs.EnrollUserRequest{
+				TLSCert: string(tlsCertPEM),
+				URL: fmt.Sprintf(
+					"https://%s:%d",
+					conf.Spec.Enrollment.TLS.Cahost,
+					conf.Spec.Enrollment.TLS.Caport,
+				),
+				Name:         conf.Spec.Enrollment.TLS.Caname,
+				MSPID:        conf.Spec.MspID,
+				User:         conf.Spec.Enrollment.TLS.Enrollid,
+				Secret:       tlsEnrollSecret,
+				Hosts:        getNodeTLSHosts(node, publicIP),
+				CN:           "",
+				Profile:      conf.Spec.Enrollment.TLS.EnrollProfile(),
+				KeyAlgorithm: string(conf.Spec.Enrollment.TLS.KeyAlgorithm),
+				Attributes:   nil,
+			})
+			if err != nil {
+				return nil, err
+			}
 		}
 		componentCertPEM, err := base64.StdEncoding.DecodeString(conf.Spec.Enrollment.Component.Catls.Cacert)
 		if err != nil {
@@ -212,12 +245,13 @@ func getConfig(conf *hlfv1alpha1.FabricOrderingService, client *kubernetes.Clien
 				conf.Spec.Enrollment.Component.Cahost,
 				conf.Spec.Enrollment.Component.Caport,
 			),
-			Name:       conf.Spec.Enrollment.Component.Caname,
-			MSPID:      conf.Spec.MspID,
-			User:       conf.Spec.Enrollment.Component.Enrollid,
-			Secret:     conf.Spec.Enrollment.Component.Enrollsecret,
-			Profile:    "",
-			Attributes: nil,
+			Name:         conf.Spec.Enrollment.Component.Caname,
+			MSPID:        conf.Spec.MspID,
+			User:         conf.Spec.Enrollment.Component.Enrollid,
+			Secret:       signEnrollSecret,
+			Profile:      conf.Spec.Enrollment.Component.Profile,
+			KeyAlgorithm: string(conf.Spec.Enrollment.Component.KeyAlgorithm),
+			Attributes:   nil,
 		})
 		if err != nil {
 			return nil, err
@@ -320,10 +354,11 @@ func getConfig(conf *hlfv1alpha1.FabricOrderingService, client *kubernetes.Clien
 	profileConfig, err := testutils.GetProfileConfig(
 		[]testutils.OrdererOrganization{
 			{
-				Nodes:        ordererNodes,
-				RootTLSCert:  tlsCAPem,
-				RootSignCert: signCAPem,
-				MspID:        conf.Spec.MspID,
+				Nodes:                ordererNodes,
+				RootTLSCert:          tlsCAPem,
+				RootSignCert:         signCAPem,
+				IntermediateTLSCerts: tlsIntermediateCerts,
+				MspID:                conf.Spec.MspID,
 			},
 		},
 		genesisConfig,
@@ -357,6 +392,38 @@ func getConfig(conf *hlfv1alpha1.FabricOrderingService, client *kubernetes.Clien
 	return &fabricOrdChart, nil
 }
 
+// getNodeTLSHosts returns the SANs of the TLS certificate of a node, reachable through the public IP of the cluster
+func getNodeTLSHosts(node hlfv1alpha1.OrdererNode, publicIP string) []string {
+	tlsHosts := []string{}
+	for _, host := range node.Enrollment.TLS.Csr.Hosts {
+		tlsHosts = append(tlsHosts, host)
+	}
+	if !utils.Contains(tlsHosts, publicIP) {
+		tlsHosts = append(tlsHosts, publicIP)
+	}
+	return tlsHosts
+}
+
+// ensureNodeCertificates requests the TLS certificates of the nodes of the ordering service to cert-manager
+func (r *FabricOrderingServiceReconciler) ensureNodeCertificates(ctx context.Context, conf *hlfv1alpha1.FabricOrderingService, certManager *hlfv1alpha1.CertManagerTLS) error {
+	clientSet, err := utils.GetClientKubeWithConf(r.Config)
+	if err != nil {
+		return err
+	}
+	publicIP, err := utils.GetPublicIPKubernetes(clientSet)
+	if err != nil {
+		return err
+	}
+	for idx, node := range conf.Spec.Nodes {
+		hosts := append(getNodeTLSHosts(node, publicIP), certs.ServiceHosts(getOrdererName(conf.Name, idx), conf.Namespace)...)
+		err = certs.EnsureCertManagerCertificate(ctx, r.Client, r.Scheme, conf, conf.NodeTLSCertificateName(node), certManager, hosts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 const ordererFinalizer = "finalizer.orderer.hlf.kungfusoftware.es"
 
 func (r *FabricOrderingServiceReconciler) finalizeOrderer(reqLogger logr.Logger, m *hlfv1alpha1.FabricOrderingService) error {
@@ -398,6 +465,8 @@ func (r *FabricOrderingServiceReconciler) addFinalizer(reqLogger logr.Logger, m
 // +kubebuilder:rbac:groups=hlf.kungfusoftware.es,resources=fabricorderingservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=hlf.kungfusoftware.es,resources=fabricorderingservices/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=hlf.kungfusoftware.es,resources=fabricorderingservices/finalizers,verbs=get;update;patch
+// +kubebuilder:rbac:groups=hlf.kungfusoftware.es,resources=fabricorderernodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services/proxy,verbs=get
 func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
 	reqLogger := r.Log.WithValues("hlf", req.NamespacedName)
@@ -437,6 +506,25 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 			return ctrl.Result{}, err
 		}
 	}
+	clientSet, err := utils.GetClientKubeWithConf(r.Config)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	resolvedOrderer := fabricOrderer.DeepCopy()
+	err = certs.ResolveComponentCA(ctx, r.Client, clientSet, ns, &resolvedOrderer.Spec.Enrollment.Component)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	err = certs.ResolveTLSCA(ctx, r.Client, clientSet, ns, &resolvedOrderer.Spec.Enrollment.TLS)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if certManager := resolvedOrderer.Spec.Enrollment.TLS.CertManager; certManager != nil {
+		err = r.ensureNodeCertificates(ctx, fabricOrderer, certManager)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 	cmdStatus := action.NewStatus(cfg)
 	exists := true
 	_, err = cmdStatus.Run(releaseName)
@@ -450,10 +538,6 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 		}
 	}
 	log.Debugf("Release %s exists=%v", releaseName, exists)
-	clientSet, err := utils.GetClientKubeWithConf(r.Config)
-	if err != nil {
-		return ctrl.Result{}, err
-	}
 	if exists {
 		// update
 		s, err := getOrdererState(r.Config, releaseName, ns)
@@ -462,6 +546,11 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 		}
 		fOrderer := fabricOrderer.DeepCopy()
 		fOrderer.Status.Status = s.Status
+		if fabricOrderer.Spec.SystemChannel.ConfigUpdate == nil {
+			fOrderer.Status.SystemChannel = nil
+		} else if s.Status == hlfv1alpha1.RunningStatus {
+			fOrderer.Status.SystemChannel = syncSystemChannel(ctx, r, fabricOrderer)
+		}
 		fOrderer.Status.Conditions.SetCondition(status.Condition{
 			Type:   status.ConditionType(s.Status),
 			Status: "True",
@@ -480,7 +569,7 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 			if err != nil {
 				return ctrl.Result{}, err
 			}
-			c, err := getConfig(fabricOrderer, clientSet)
+			c, err := getConfig(resolvedOrderer, clientSet)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
@@ -506,6 +595,10 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 		}
 
 		if s.Status == hlfv1alpha1.RunningStatus {
+			systemChannel := fOrderer.Status.SystemChannel
+			if systemChannel != nil && systemChannel.Status == hlfv1alpha1.ChannelConfigFailed {
+				return utils.RetryChannelUpdate(fmt.Sprintf("Update of the system channel of ordering service %s", fOrderer.Name)), nil
+			}
 			return ctrl.Result{
 				//RequeueAfter: 120 * time.Second,
 			}, nil
@@ -526,7 +619,7 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		c, err := getConfig(fabricOrderer, clientSet)
+		c, err := getConfig(resolvedOrderer, clientSet)
 		if err != nil {
 			reqLogger.Error(err, "Failed to get config for orderer %s/%s", req.Namespace, req.Name)
 			return ctrl.Result{}, err
@@ -563,9 +656,20 @@ func (r *FabricOrderingServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Resu
 }
 
 func (r *FabricOrderingServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	newList := func() runtime.Object {
+		return &hlfv1alpha1.FabricOrderingServiceList{}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hlfv1alpha1.FabricOrderingService{}).
 		Owns(&appsv1.Deployment{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.SecretReferrersMapper("ordering services", r.List, newList)},
+		).
+		Watches(
+			&source.Kind{Type: &hlfv1alpha1.FabricCA{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.CAReferrersMapper("ordering services", r.List, newList)},
+		).
 		Complete(r)
 }
 
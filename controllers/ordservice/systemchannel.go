@@ -0,0 +1,172 @@
+package ordservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxlator/update"
+	log "github.com/sirupsen/logrus"
+)
+
+// systemChannelSettings returns the settings of spec.systemChannel.config, the capabilities that are disabled in the
+// spec keep their current state since they can't be disabled in a live channel
+func systemChannelSettings(channelConfig hlfv1alpha1.ChannelConfig) (testutils.ChannelSettings, error) {
+	settings := testutils.ChannelSettings{
+		MaxMessageCount:      uint32(channelConfig.MaxMessageCount),
+		AbsoluteMaxBytes:     uint32(channelConfig.AbsoluteMaxBytes),
+		PreferredMaxBytes:    uint32(channelConfig.PreferredMaxBytes),
+		TickInterval:         channelConfig.TickInterval,
+		ElectionTick:         uint32(channelConfig.ElectionTick),
+		HeartbeatTick:        uint32(channelConfig.HeartbeatTick),
+		MaxInflightBlocks:    uint32(channelConfig.MaxInflightBlocks),
+		SnapshotIntervalSize: uint32(channelConfig.SnapshotIntervalSize),
+	}
+	if channelConfig.BatchTimeout != "" {
+		batchTimeout, err := time.ParseDuration(channelConfig.BatchTimeout)
+		if err != nil {
+			return settings, err
+		}
+		settings.BatchTimeout = batchTimeout
+	}
+	if channelConfig.OrdererCapabilities.V2_0 {
+		settings.OrdererCapabilities = []string{"V2_0"}
+	}
+	if channelConfig.ChannelCapabilities.V2_0 {
+		settings.ChannelCapabilities = []string{"V2_0"}
+	}
+	return settings, nil
+}
+
+// ordererNodeVersions returns the Fabric version reported by the orderer nodes in the cluster of the organizations of
+// the orderer group
+func ordererNodeVersions(ctx context.Context, r *FabricOrderingServiceReconciler, ordererGroup *common.ConfigGroup) ([]testutils.NodeVersion, error) {
+	clientSet, err := utils.GetClientKubeWithConf(r.Config)
+	if err != nil {
+		return nil, err
+	}
+	ordererNodes := &hlfv1alpha1.FabricOrdererNodeList{}
+	err = r.List(ctx, ordererNodes)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []testutils.NodeVersion
+	for _, ordererNode := range ordererNodes.Items {
+		if _, ok := ordererGroup.Groups[ordererNode.Spec.MspID]; !ok {
+			continue
+		}
+		version, err := utils.GetNodeVersion(ctx, clientSet, ordererNode.Namespace, ordererNode.Name)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, testutils.NodeVersion{
+			Name:    fmt.Sprintf("orderer %s", ordererNode.Name),
+			MSPID:   ordererNode.Spec.MspID,
+			Version: version,
+		})
+	}
+	return nodes, nil
+}
+
+// updateSystemChannel submits a config update when the system channel differs from the settings, it returns the ID
+// of the transaction or an empty string when the channel is up to date
+func updateSystemChannel(ctx context.Context, r *FabricOrderingServiceReconciler, resClient *resmgmt.Client, channelID string, settings testutils.ChannelSettings) (string, error) {
+	block, err := resClient.QueryConfigBlockFromOrderer(channelID)
+	if err != nil {
+		return "", err
+	}
+	channelConfig, err := resource.ExtractConfigFromBlock(block)
+	if err != nil {
+		return "", err
+	}
+	modifiedConfig := proto.Clone(channelConfig).(*common.Config)
+	err = testutils.ApplyChannelSettings(modifiedConfig, settings)
+	if err != nil {
+		return "", err
+	}
+	configUpdate, err := update.Compute(channelConfig, modifiedConfig)
+	if err != nil {
+		if strings.Contains(err.Error(), "no differences detected") {
+			// the system channel already has the settings
+			return "", nil
+		}
+		return "", err
+	}
+	ordererGroup := channelConfig.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	ordererCapabilities, err := testutils.AddedCapabilities(ordererGroup, settings.OrdererCapabilities)
+	if err != nil {
+		return "", err
+	}
+	channelCapabilities, err := testutils.AddedCapabilities(channelConfig.ChannelGroup, settings.ChannelCapabilities)
+	if err != nil {
+		return "", err
+	}
+	capabilities := append(ordererCapabilities, channelCapabilities...)
+	if len(capabilities) > 0 {
+		nodes, err := ordererNodeVersions(ctx, r, ordererGroup)
+		if err != nil {
+			return "", err
+		}
+		err = testutils.CheckNodeVersions(ordererGroup, capabilities, nodes)
+		if err != nil {
+			return "", err
+		}
+	}
+	configUpdate.ChannelId = channelID
+	configUpdateBytes, err := proto.Marshal(configUpdate)
+	if err != nil {
+		return "", err
+	}
+	return utils.SaveChannelConfigUpdate(resClient, channelID, configUpdateBytes)
+}
+
+// syncSystemChannel applies spec.systemChannel.config to the live system channel of the ordering service
+func syncSystemChannel(ctx context.Context, r *FabricOrderingServiceReconciler, ordService *hlfv1alpha1.FabricOrderingService) *hlfv1alpha1.FabricOrderingServiceChannelStatus {
+	spec := ordService.Spec
+	channelStatus := &hlfv1alpha1.FabricOrderingServiceChannelStatus{
+		Status: hlfv1alpha1.ChannelConfigSynced,
+	}
+	if ordService.Status.SystemChannel != nil {
+		channelStatus.TxID = ordService.Status.SystemChannel.TxID
+	}
+	fail := func(err error) *hlfv1alpha1.FabricOrderingServiceChannelStatus {
+		log.Errorf("Failed to update the system channel of ordering service %s: %v", ordService.Name, err)
+		channelStatus.Status = hlfv1alpha1.ChannelConfigFailed
+		channelStatus.Message = err.Error()
+		return channelStatus
+	}
+	settings, err := systemChannelSettings(spec.SystemChannel.Config)
+	if err != nil {
+		return fail(err)
+	}
+	sdk, resClient, err := utils.NewChannelAdminClient(
+		ctx,
+		r.Client,
+		ordService.Namespace,
+		spec.SystemChannel.ConfigUpdate.NetworkConfigRef,
+		spec.SystemChannel.ConfigUpdate.User,
+		spec.MspID,
+	)
+	if err != nil {
+		return fail(err)
+	}
+	defer sdk.Close()
+	txID, err := updateSystemChannel(ctx, r, resClient, spec.SystemChannel.Name, settings)
+	if err != nil {
+		return fail(err)
+	}
+	if txID != "" {
+		log.Infof("System channel %s of ordering service %s updated, txID=%s", spec.SystemChannel.Name, ordService.Name, txID)
+		channelStatus.TxID = txID
+	}
+	return channelStatus
+}
@@ -20,7 +20,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/kubernetes/pkg/api/v1/pod"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
@@ -197,6 +199,8 @@ const chartName = "hlf-peer"
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=extensions,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
 // +kubebuilder:rbac:groups=apps,resources=pods/log,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods/log,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=extensions,resources=pods/log,verbs=get;list;watch;create;update;patch;delete
@@ -262,6 +266,32 @@ func (r *FabricPeerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		}
 	}
 
+	clientSet, err := utils.GetClientKubeWithConf(r.Config)
+	if err != nil {
+		setConditionStatus(fabricPeer, hlfv1alpha1.FailedStatus, false, err, false)
+		return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
+	}
+	resolvedPeer := fabricPeer.DeepCopy()
+	err = certs.ResolveComponentCA(ctx, r.Client, clientSet, ns, &resolvedPeer.Spec.Secret.Enrollment.Component)
+	if err != nil {
+		setConditionStatus(fabricPeer, hlfv1alpha1.FailedStatus, false, err, false)
+		return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
+	}
+	err = certs.ResolveTLSCA(ctx, r.Client, clientSet, ns, &resolvedPeer.Spec.Secret.Enrollment.TLS)
+	if err != nil {
+		setConditionStatus(fabricPeer, hlfv1alpha1.FailedStatus, false, err, false)
+		return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
+	}
+	if tlsParams := resolvedPeer.Spec.Secret.Enrollment.TLS; tlsParams.CertManager != nil {
+		hosts := append(append([]string{}, tlsParams.Csr.Hosts...), resolvedPeer.Spec.Hosts...)
+		hosts = append(hosts, certs.ServiceHosts(releaseName, ns)...)
+		err = certs.EnsureCertManagerCertificate(ctx, r.Client, r.Scheme, fabricPeer, resolvedPeer.Name, tlsParams.CertManager, hosts)
+		if err != nil {
+			setConditionStatus(fabricPeer, hlfv1alpha1.FailedStatus, false, err, false)
+			return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
+		}
+	}
+
 	cmdStatus := action.NewStatus(cfg)
 	exists := true
 	_, err = cmdStatus.Run(releaseName)
@@ -275,11 +305,6 @@ func (r *FabricPeerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		}
 	}
 	log.Debugf("Release %s exists=%v", releaseName, exists)
-	clientSet, err := utils.GetClientKubeWithConf(r.Config)
-	if err != nil {
-		setConditionStatus(fabricPeer, hlfv1alpha1.FailedStatus, false, err, false)
-		return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
-	}
 	svc, err := createPeerService(
 		clientSet,
 		chartName,
@@ -315,7 +340,7 @@ func (r *FabricPeerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 			Type:   status.ConditionType(s.Status),
 			Status: "True",
 		})
-		c, err := GetConfig(fabricPeer, clientSet, releaseName, req.Namespace, svc)
+		c, err := GetConfig(resolvedPeer, clientSet, releaseName, req.Namespace, svc)
 		if err != nil {
 			setConditionStatus(fabricPeer, hlfv1alpha1.FailedStatus, false, err, false)
 			return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
@@ -386,7 +411,7 @@ func (r *FabricPeerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 			return r.updateCRStatusOrFailReconcile(ctx, r.Log, fabricPeer)
 		}
 		c, err := GetConfig(
-			fabricPeer,
+			resolvedPeer,
 			clientSet,
 			name,
 			req.Namespace,
@@ -565,6 +590,10 @@ func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, names
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if len(signKeyData) == 0 {
+		// the key is kept in the HSM
+		return crt, nil, rootCrt, nil
+	}
 	key, err := utils.ParseECDSAPrivateKey(signKeyData)
 	if err != nil {
 		return nil, nil, nil, err
@@ -572,38 +601,36 @@ func getExistingSignCrypto(client *kubernetes.Clientset, chartName string, names
 	return crt, key, rootCrt, nil
 }
 
-func CreateTLSCryptoMaterial(conf *hlfv1alpha1.FabricPeer, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, hosts []string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
-	tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(certs.EnrollUserRequest{
-		TLSCert:    tlsCertString,
-		URL:        caurl,
-		Name:       caName,
-		MSPID:      conf.Spec.MspID,
-		User:       enrollID,
-		Secret:     enrollSecret,
-		Hosts:      hosts,
-		CN:         "",
-		Profile:    "tls",
-		Attributes: nil,
+func CreateTLSCryptoMaterial(conf *hlfv1alpha1.FabricPeer, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, hosts []string) (*certs.CryptoMaterial, error) {
+	return certs.EnrollUserCrypto(certs.EnrollUserRequest{
+		TLSCert:      tlsCertString,
+		URL:          caurl,
+		Name:         caName,
+		MSPID:        conf.Spec.MspID,
+		User:         enrollID,
+		Secret:       enrollSecret,
+		Hosts:        hosts,
+		CN:           "",
+		Profile:      conf.Spec.Secret.Enrollment.TLS.EnrollProfile(),
+		KeyAlgorithm: string(conf.Spec.Secret.Enrollment.TLS.KeyAlgorithm),
+		Attributes:   nil,
 	})
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	return tlsCert, tlsKey, tlsRootCert, nil
 }
 
 func CreateTLSOPSCryptoMaterial(conf *hlfv1alpha1.FabricPeer, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, hosts []string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
 	tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(
 		certs.EnrollUserRequest{
-			TLSCert:    tlsCertString,
-			URL:        caurl,
-			Name:       caName,
-			MSPID:      conf.Spec.MspID,
-			User:       enrollID,
-			Secret:     enrollSecret,
-			Hosts:      hosts,
-			CN:         "",
-			Profile:    "tls",
-			Attributes: nil,
+			TLSCert:      tlsCertString,
+			URL:          caurl,
+			Name:         caName,
+			MSPID:        conf.Spec.MspID,
+			User:         enrollID,
+			Secret:       enrollSecret,
+			Hosts:        hosts,
+			CN:           "",
+			Profile:      conf.Spec.Secret.Enrollment.TLS.EnrollProfile(),
+			KeyAlgorithm: string(conf.Spec.Secret.Enrollment.TLS.KeyAlgorithm),
+			Attributes:   nil,
 		},
 	)
 	if err != nil {
@@ -612,19 +639,18 @@ func CreateTLSOPSCryptoMaterial(conf *hlfv1alpha1.FabricPeer, caName string, cau
 	return tlsCert, tlsKey, tlsRootCert, nil
 }
 
-func CreateSignCryptoMaterial(conf *hlfv1alpha1.FabricPeer, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string) (*x509.Certificate, *ecdsa.PrivateKey, *x509.Certificate, error) {
-	tlsCert, tlsKey, tlsRootCert, err := certs.EnrollUser(certs.EnrollUserRequest{
-		TLSCert: tlsCertString,
-		URL:     caurl,
-		Name:    caName,
-		MSPID:   conf.Spec.MspID,
-		User:    enrollID,
-		Secret:  enrollSecret,
+func CreateSignCryptoMaterial(conf *hlfv1alpha1.FabricPeer, caName string, caurl string, enrollID string, enrollSecret string, tlsCertString string, pkcs11Params *certs.PKCS11Params) (*certs.CryptoMaterial, error) {
+	return certs.EnrollUserCrypto(certs.EnrollUserRequest{
+		TLSCert:      tlsCertString,
+		URL:          caurl,
+		Name:         caName,
+		MSPID:        conf.Spec.MspID,
+		User:         enrollID,
+		Secret:       enrollSecret,
+		Profile:      conf.Spec.Secret.Enrollment.Component.Profile,
+		KeyAlgorithm: string(conf.Spec.Secret.Enrollment.Component.KeyAlgorithm),
+		PKCS11:       pkcs11Params,
 	})
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	return tlsCert, tlsKey, tlsRootCert, nil
 }
 
 func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chartName string, namespace string, svc *corev1.Service) (*FabricPeerChart, error) {
@@ -635,63 +661,147 @@ func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chart
 	var hosts []string
 	hosts = append(hosts, tlsParams.Csr.Hosts...)
 	hosts = append(hosts, ingressHosts...)
-	tlsCert, tlsKey, tlsRootCert, err := getExistingTLSCrypto(client, chartName, namespace)
+	var tlsCert, tlsRootCert, tlsOpsCert, signCert, signRootCert *x509.Certificate
+	var tlsKey, tlsOpsKey, signKey *ecdsa.PrivateKey
+	var intCACert, intTLSCACert string
+	pkcs11Params, err := certs.GetPKCS11Params(client, namespace, spec.BCCSP.PKCS11Config())
 	if err != nil {
-		cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
-		if err != nil {
-			return nil, err
-		}
-		tlsCert, tlsKey, tlsRootCert, err = CreateTLSCryptoMaterial(
-			conf,
-			tlsParams.Caname,
-			tlsCAUrl,
-			tlsParams.Enrollid,
-			tlsParams.Enrollsecret,
-			string(cacert),
-			hosts,
-		)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
-	tlsOpsCert, tlsOpsKey, _, err := getExistingTLSOPSCrypto(client, chartName, namespace)
+	vaultParams, err := certs.GetVaultParams(client, namespace, &spec.Secret.Enrollment.Component, "peer")
 	if err != nil {
-		cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
-		if err != nil {
-			return nil, err
+		return nil, err
+	}
+	if pkcs11Params != nil && vaultParams != nil {
+		return nil, errors.New("the signing key can't be kept both in the HSM and in Vault")
+	}
+	if !conf.Spec.Secret.EnrollsAgainstCA() {
+		if pkcs11Params != nil {
+			return nil, errors.New("the PKCS11 BCCSP requires enrolling the signing identity against a CA")
+		}
+		if vaultParams != nil {
+			return nil, errors.New("Vault PKI issues the signing identity, it can't be combined with provided or generated crypto material")
 		}
-		tlsOpsCert, tlsOpsKey, _, err = CreateTLSOPSCryptoMaterial(
-			conf,
-			tlsParams.Caname,
-			tlsCAUrl,
-			tlsParams.Enrollid,
-			tlsParams.Enrollsecret,
-			string(cacert),
+		signCrypto, tlsCrypto, err := certs.GetNodeCryptoMaterial(
+			client,
+			namespace,
+			*v1.NewControllerRef(conf, hlfv1alpha1.GroupVersion.WithKind("FabricPeer")),
+			chartName,
+			spec.MspID,
+			"peer",
+			&conf.Spec.Secret,
 			hosts,
 		)
 		if err != nil {
 			return nil, err
 		}
-	}
-	signParams := conf.Spec.Secret.Enrollment.Component
-	caUrl := fmt.Sprintf("https://%s:%d", signParams.Cahost, signParams.Caport)
-	signCert, signKey, signRootCert, err := getExistingSignCrypto(client, chartName, namespace)
-	if err != nil {
-		cacert, err := base64.StdEncoding.DecodeString(signParams.Catls.Cacert)
-		if err != nil {
-			return nil, err
+		tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+		tlsOpsCert, tlsOpsKey = tlsCrypto.Cert, tlsCrypto.Key
+		signCert, signKey, signRootCert = signCrypto.Cert, signCrypto.Key, signCrypto.RootCert
+		intCACert = signCrypto.EncodedIntermediateCerts()
+		intTLSCACert = tlsCrypto.EncodedIntermediateCerts()
+	} else {
+		if tlsParams.CertManager != nil {
+			tlsCrypto, err := certs.GetCertManagerCryptoMaterial(client, namespace, conf.Name)
+			if err != nil {
+				return nil, err
+			}
+			tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+			tlsOpsCert, tlsOpsKey = tlsCrypto.Cert, tlsCrypto.Key
+			intTLSCACert = tlsCrypto.EncodedIntermediateCerts()
+		} else {
+			tlsEnrollSecret, err := utils.ResolveSecretValue(client, namespace, tlsParams.Enrollsecret, tlsParams.EnrollsecretRef)
+			if err != nil {
+				return nil, err
+			}
+			tlsCert, tlsKey, tlsRootCert, err = getExistingTLSCrypto(client, chartName, namespace)
+			if err == nil {
+				intTLSCACert, err = certs.GetIntermediateCertsFromSecret(client, namespace, fmt.Sprintf("%s-tlsintcacert", chartName))
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
+				if err != nil {
+					return nil, err
+				}
+				tlsCrypto, err := CreateTLSCryptoMaterial(
+					conf,
+					tlsParams.Caname,
+					tlsCAUrl,
+					tlsParams.Enrollid,
+					tlsEnrollSecret,
+					string(cacert),
+					hosts,
+				)
+				if err != nil {
+					return nil, err
+				}
+				tlsCert, tlsKey, tlsRootCert = tlsCrypto.Cert, tlsCrypto.Key, tlsCrypto.RootCert
+				intTLSCACert = tlsCrypto.EncodedIntermediateCerts()
+			}
+			tlsOpsCert, tlsOpsKey, _, err = getExistingTLSOPSCrypto(client, chartName, namespace)
+			if err != nil {
+				cacert, err := base64.StdEncoding.DecodeString(tlsParams.Catls.Cacert)
+				if err != nil {
+					return nil, err
+				}
+				tlsOpsCert, tlsOpsKey, _, err = CreateTLSOPSCryptoMaterial(
+					conf,
+					tlsParams.Caname,
+					tlsCAUrl,
+					tlsParams.Enrollid,
+					tlsEnrollSecret,
+					string(cacert),
+					hosts,
+				)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
-		signCert, signKey, signRootCert, err = CreateSignCryptoMaterial(
-			conf,
-			signParams.Caname,
-			caUrl,
-			signParams.Enrollid,
-			signParams.Enrollsecret,
-			string(cacert),
-		)
+		signParams := conf.Spec.Secret.Enrollment.Component
+		caUrl := fmt.Sprintf("https://%s:%d", signParams.Cahost, signParams.Caport)
+		signEnrollSecret, err := utils.ResolveSecretValue(client, namespace, signParams.Enrollsecret, signParams.EnrollsecretRef)
 		if err != nil {
 			return nil, err
 		}
+		if vaultParams != nil {
+			// the signing certificate is issued in the pod by the Vault agent
+			signCrypto, err := certs.GetVaultPKICryptoMaterial(vaultParams)
+			if err != nil {
+				return nil, err
+			}
+			signRootCert = signCrypto.RootCert
+			intCACert = signCrypto.EncodedIntermediateCerts()
+		} else {
+			signCert, signKey, signRootCert, err = getExistingSignCrypto(client, chartName, namespace)
+			if err == nil {
+				intCACert, err = certs.GetIntermediateCertsFromSecret(client, namespace, fmt.Sprintf("%s-intcacert", chartName))
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				cacert, err := base64.StdEncoding.DecodeString(signParams.Catls.Cacert)
+				if err != nil {
+					return nil, err
+				}
+				signCrypto, err := CreateSignCryptoMaterial(
+					conf,
+					signParams.Caname,
+					caUrl,
+					signParams.Enrollid,
+					signEnrollSecret,
+					string(cacert),
+					pkcs11Params,
+				)
+				if err != nil {
+					return nil, err
+				}
+				signCert, signKey, signRootCert = signCrypto.Cert, signCrypto.Key, signCrypto.RootCert
+				intCACert = signCrypto.EncodedIntermediateCerts()
+			}
+		}
 	}
 	tlsCRTEncoded := pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
@@ -722,22 +832,28 @@ func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chart
 		Bytes: tlsOpsEncodedPK,
 	})
 
-	signCRTEncoded := pem.EncodeToMemory(&pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: signCert.Raw,
-	})
+	var signCRTEncoded []byte
+	if signCert != nil {
+		signCRTEncoded = pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: signCert.Raw,
+		})
+	}
 	signRootCRTEncoded := pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: signRootCert.Raw,
 	})
-	signEncodedPK, err := x509.MarshalPKCS8PrivateKey(signKey)
-	if err != nil {
-		return nil, err
+	var signPEMEncodedPK []byte
+	if signKey != nil {
+		signEncodedPK, err := x509.MarshalPKCS8PrivateKey(signKey)
+		if err != nil {
+			return nil, err
+		}
+		signPEMEncodedPK = pem.EncodeToMemory(&pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: signEncodedPK,
+		})
 	}
-	signPEMEncodedPK := pem.EncodeToMemory(&pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: signEncodedPK,
-	})
 	var externalEndpoint string
 	if spec.ExternalEndpoint != "" {
 		externalEndpoint = spec.ExternalEndpoint
@@ -823,7 +939,42 @@ func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chart
 			IngressGateway: "",
 		}
 	}
+	couchDBPassword, err := utils.ResolveSecretValue(client, namespace, spec.CouchDB.Password, spec.CouchDB.PasswordRef)
+	if err != nil {
+		return nil, err
+	}
+	var hsm HSM
+	if pkcs11Conf := spec.BCCSP.PKCS11Config(); pkcs11Conf != nil {
+		hsm = HSM{
+			Enabled:  true,
+			Library:  pkcs11Params.Library,
+			Label:    pkcs11Params.Label,
+			Hash:     pkcs11Params.Hash,
+			Security: pkcs11Params.Security,
+			PinSecret: HSMPinSecret{
+				Name: pkcs11Conf.PinRef.Name,
+				Key:  pkcs11Conf.PinRef.Key,
+			},
+			LibraryImage: pkcs11Conf.LibraryImage,
+			Env:          pkcs11Conf.Env,
+		}
+		if pkcs11Conf.Volume != nil {
+			hsm.Volume = HSMVolume{
+				ClaimName: pkcs11Conf.Volume.ClaimName,
+				MountPath: pkcs11Conf.Volume.MountPath,
+			}
+		}
+	}
+	var vault Vault
+	if vaultParams != nil {
+		vault = Vault{
+			Enabled:     true,
+			Annotations: certs.VaultAgentAnnotations(vaultParams),
+		}
+	}
 	var c = FabricPeerChart{
+		HSM:      hsm,
+		Vault:    vault,
 		Replicas: spec.Replicas,
 		Istio:    istio,
 		Image: Image{
@@ -850,8 +1001,8 @@ func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chart
 			},
 		},
 		ExternalChaincodeBuilder: conf.Spec.ExternalChaincodeBuilder,
-		CouchdbPassword:          conf.Spec.CouchDB.User,
-		CouchdbUsername:          conf.Spec.CouchDB.Password,
+		CouchdbPassword:          couchDBPassword,
+		CouchdbUsername:          conf.Spec.CouchDB.User,
 		Rbac:                     RBAC{Ns: namespace},
 		Cert:                     string(signCRTEncoded),
 		Key:                      string(signPEMEncodedPK),
@@ -864,9 +1015,10 @@ func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chart
 			Cert: string(tlsOpsCRTEncoded),
 			Key:  string(tlsOpsPEMEncodedPK),
 		},
-		Cacert:      string(signRootCRTEncoded),
-		IntCacert:   ``,
-		Tlsrootcert: string(tlsRootCRTEncoded),
+		Cacert:       string(signRootCRTEncoded),
+		IntCacert:    intCACert,
+		IntTLSCACert: intTLSCACert,
+		Tlsrootcert:  string(tlsRootCRTEncoded),
 		Resources: PeerResources{
 			Peer: Resources{
 				Requests: Requests{
@@ -946,11 +1098,23 @@ func GetConfig(conf *hlfv1alpha1.FabricPeer, client *kubernetes.Clientset, chart
 }
 
 func (r *FabricPeerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	newList := func() runtime.Object {
+		return &hlfv1alpha1.FabricPeerList{}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hlfv1alpha1.FabricPeer{}).
 		Owns(&appsv1.Deployment{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.SecretReferrersMapper("peers", r.List, newList)},
+		).
+		Watches(
+			&source.Kind{Type: &hlfv1alpha1.FabricCA{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: utils.CAReferrersMapper("peers", r.List, newList)},
+		).
 		Complete(r)
 }
+
 func getServiceName(peer *hlfv1alpha1.FabricPeer) string {
 	return peer.Name
 }
@@ -1,5 +1,7 @@
 package peer
 
+import corev1 "k8s.io/api/core/v1"
+
 type RBAC struct {
 	Ns string `json:"ns"`
 }
@@ -19,7 +21,8 @@ type FabricPeerChart struct {
 	TLS                      TLS               `json:"tls"`
 	OPSTLS                   TLS               `json:"opsTLS"`
 	Cacert                   string            `json:"cacert"`
-	IntCacert                string            `json:"intCAcert"`
+	IntCacert                string            `json:"intCACert"`
+	IntTLSCACert             string            `json:"intTLSCACert"`
 	Tlsrootcert              string            `json:"tlsrootcert"`
 	Resources                PeerResources     `json:"resources,omitempty"`
 	NodeSelector             NodeSelector      `json:"nodeSelector,omitempty"`
@@ -33,6 +36,8 @@ type FabricPeerChart struct {
 	Logging                  Logging           `json:"logging"`
 	ExternalBuilders         []ExternalBuilder `json:"externalBuilders"`
 	ServiceMonitor           ServiceMonitor    `json:"serviceMonitor"`
+	HSM                      HSM               `json:"hsm"`
+	Vault                    Vault             `json:"vault"`
 }
 
 type ServiceMonitor struct {
@@ -149,3 +154,27 @@ type Logging struct {
 	Msp      string `json:"msp"`
 	Policies string `json:"policies"`
 }
+
+type HSM struct {
+	Enabled      bool            `json:"enabled"`
+	Library      string          `json:"library"`
+	Label        string          `json:"label"`
+	Hash         string          `json:"hash"`
+	Security     int             `json:"security"`
+	PinSecret    HSMPinSecret    `json:"pinSecret"`
+	LibraryImage string          `json:"libraryImage"`
+	Env          []corev1.EnvVar `json:"env"`
+	Volume       HSMVolume       `json:"volume"`
+}
+type HSMPinSecret struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+type HSMVolume struct {
+	ClaimName string `json:"claimName"`
+	MountPath string `json:"mountPath"`
+}
+type Vault struct {
+	Enabled     bool              `json:"enabled"`
+	Annotations map[string]string `json:"annotations"`
+}
@@ -24,10 +24,12 @@ import (
 	"github.com/lithammer/shortuuid/v3"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	// +kubebuilder:scaffold:imports
 )
@@ -168,6 +170,10 @@ channels: {}
 	return ordClient
 }
 func randomFabricCA(releaseName string, namespace string) *hlfv1alpha1.FabricCA {
+	return createFabricCA(releaseName, namespace, nil)
+}
+
+func createFabricCA(releaseName string, namespace string, ldap *hlfv1alpha1.FabricCALDAP) *hlfv1alpha1.FabricCA {
 	fabricCARegistry := hlfv1alpha1.FabricCARegistry{
 		MaxEnrollments: -1,
 		Identities: []hlfv1alpha1.FabricCAIdentity{
@@ -271,6 +277,7 @@ func randomFabricCA(releaseName string, namespace string) *hlfv1alpha1.FabricCA
 				Registry:     fabricCARegistry,
 				Intermediate: hlfv1alpha1.FabricCAIntermediate{},
 				BCCSP:        cabccsp,
+				LDAP:         ldap,
 			},
 			TLSCA: hlfv1alpha1.FabricCAItemConf{
 				Name:    "tlsca",
@@ -683,6 +690,78 @@ func createOrdererNode(releaseName string, namespace string, params createOrdere
 	return fabricOrderer
 }
 
+const (
+	openLDAPBaseDN        = "dc=example,dc=org"
+	openLDAPAdminPassword = "adminpw"
+)
+
+// deleteOpenLDAP removes the OpenLDAP server created by createOpenLDAP
+func deleteOpenLDAP(namespace string) {
+	name := "openldap"
+	ctx := context.Background()
+	Expect(ClientSet.AppsV1().Deployments(namespace).Delete(ctx, name, v1.DeleteOptions{})).Should(Succeed())
+	Expect(ClientSet.CoreV1().Services(namespace).Delete(ctx, name, v1.DeleteOptions{})).Should(Succeed())
+}
+
+// createOpenLDAP deploys an OpenLDAP server with the cn=admin,dc=example,dc=org entry and returns its URL
+func createOpenLDAP(namespace string) string {
+	name := "openldap"
+	labels := map[string]string{"app": name}
+	ctx := context.Background()
+	replicas := int32(1)
+	_, err := ClientSet.AppsV1().Deployments(namespace).Create(ctx, &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &v1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  name,
+							Image: "osixia/openldap:1.5.0",
+							Env: []corev1.EnvVar{
+								{Name: "LDAP_ORGANISATION", Value: "Example"},
+								{Name: "LDAP_DOMAIN", Value: "example.org"},
+								{Name: "LDAP_ADMIN_PASSWORD", Value: openLDAPAdminPassword},
+								{Name: "LDAP_TLS", Value: "false"},
+							},
+							Ports: []corev1.ContainerPort{{Name: "ldap", ContainerPort: 389}},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(389)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, v1.CreateOptions{})
+	Expect(err).ToNot(HaveOccurred())
+	_, err = ClientSet.CoreV1().Services(namespace).Create(ctx, &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Name: "ldap", Port: 389, TargetPort: intstr.FromInt(389)}},
+		},
+	}, v1.CreateOptions{})
+	Expect(err).ToNot(HaveOccurred())
+	Eventually(
+		func() bool {
+			dep, err := ClientSet.AppsV1().Deployments(namespace).Get(ctx, name, v1.GetOptions{})
+			if err != nil {
+				return false
+			}
+			return dep.Status.ReadyReplicas == replicas
+		},
+		defTimeoutSecs,
+		defInterval,
+	).Should(BeTrue(), "openldap should be ready")
+	return fmt.Sprintf("ldap://%s.%s:389/%s", name, namespace, openLDAPBaseDN)
+}
+
 func verifyCADeployment(fabricCA *hlfv1alpha1.FabricCA) {
 	publicIP, err := utils.GetPublicIPKubernetes(ClientSet)
 	Expect(err).ToNot(HaveOccurred())
@@ -924,6 +1003,75 @@ var _ = Describe("Fabric Controllers", func() {
 		).Should(BeTrue(), "ca deployment should have been deleted")
 
 	})
+	Specify("create a Fabric CA with an LDAP registry", func() {
+		By("deploy an OpenLDAP server")
+		ldapURL := createOpenLDAP(FabricNamespace)
+		bindPasswordSecret := &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "ldap-bind",
+				Namespace: FabricNamespace,
+			},
+			StringData: map[string]string{"password": openLDAPAdminPassword},
+		}
+		Expect(K8sClient.Create(context.Background(), bindPasswordSecret)).Should(Succeed())
+		By("create the CA with the LDAP registry")
+		fabricCA := createFabricCA("org1-ca", FabricNamespace, &hlfv1alpha1.FabricCALDAP{
+			URL:    ldapURL,
+			BindDN: fmt.Sprintf("cn=admin,%s", openLDAPBaseDN),
+			BindPasswordRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: bindPasswordSecret.Name},
+				Key:                  "password",
+			},
+			UserFilter:  "(cn=%s)",
+			GroupFilter: "(memberUid=%s)",
+			Attribute: hlfv1alpha1.FabricCALDAPAttribute{
+				Names: []string{"cn"},
+				Converters: []hlfv1alpha1.FabricCALDAPConverter{
+					{Name: "hf.Revoker", Value: `attr("cn") =~ "admin"`},
+				},
+			},
+		})
+		By("enroll the LDAP admin entry")
+		publicIP, err := utils.GetPublicIPKubernetes(ClientSet)
+		Expect(err).ToNot(HaveOccurred())
+		crt, _, _, err := certs.EnrollUser(certs.EnrollUserRequest{
+			TLSCert: fabricCA.Status.TlsCert,
+			URL:     fmt.Sprintf("https://%s:%d", publicIP, fabricCA.Status.NodePort),
+			Name:    "ca",
+			MSPID:   "Org1MSP",
+			User:    "admin",
+			Secret:  openLDAPAdminPassword,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(crt.Subject.CommonName).To(Equal("admin"))
+		By("reject a wrong LDAP password")
+		_, _, _, err = certs.EnrollUser(certs.EnrollUserRequest{
+			TLSCert: fabricCA.Status.TlsCert,
+			URL:     fmt.Sprintf("https://%s:%d", publicIP, fabricCA.Status.NodePort),
+			Name:    "ca",
+			MSPID:   "Org1MSP",
+			User:    "admin",
+			Secret:  "wrongpw",
+		})
+		Expect(err).To(HaveOccurred())
+		By("delete the CA and the OpenLDAP server")
+		Expect(K8sClient.Delete(context.Background(), fabricCA)).Should(Succeed())
+		Eventually(
+			func() bool {
+				depName := ca.GetDeploymentName(fabricCA.Name)
+				_, err := ClientSet.AppsV1().Deployments(FabricNamespace).Get(context.Background(), depName, v1.GetOptions{})
+				if err != nil {
+					return apierrors.IsNotFound(err)
+				} else {
+					return false
+				}
+			},
+			defTimeoutSecs,
+			defInterval,
+		).Should(BeTrue(), "ca deployment should have been deleted")
+		deleteOpenLDAP(FabricNamespace)
+		Expect(K8sClient.Delete(context.Background(), bindPasswordSecret)).Should(Succeed())
+	})
 	Specify("create a new Fabric Orderer with channel participation", func() {
 		releaseNameOrdCA := "org1-ca"
 		releaseNameOrd := "org1-orderer"
@@ -13,15 +13,19 @@ import (
 )
 
 type OrdererOrg struct {
-	mspID        string
-	tlsRootCert  *x509.Certificate
-	signRootCert *x509.Certificate
-	ordererUrls  []string
+	mspID                 string
+	tlsRootCert           *x509.Certificate
+	signRootCert          *x509.Certificate
+	tlsIntermediateCerts  []*x509.Certificate
+	signIntermediateCerts []*x509.Certificate
+	ordererUrls           []string
 }
 type PeerOrg struct {
-	mspID        string
-	tlsRootCert  *x509.Certificate
-	signRootCert *x509.Certificate
+	mspID                 string
+	tlsRootCert           *x509.Certificate
+	signRootCert          *x509.Certificate
+	tlsIntermediateCerts  []*x509.Certificate
+	signIntermediateCerts []*x509.Certificate
 }
 
 type Consenter struct {
@@ -35,7 +39,11 @@ type CreateChannelOptions struct {
 	consenters  []Consenter
 	peerOrgs    []PeerOrg
 	ordererOrgs []OrdererOrg
+	idemixOrgs  []IdemixOrg
+	profile     ChannelProfile
 	name        string
+	// replace the endorsement policies counting the Idemix orgs by signature policies
+	endorsementSignaturePolicies bool
 }
 
 func (o CreateChannelOptions) validate() error {
@@ -82,19 +90,23 @@ func CreateConsenter(host string, port int, tlsCert *x509.Certificate) Consenter
 		tlsCert: tlsCert,
 	}
 }
-func CreateOrdererOrg(mspID string, tlsRootCert *x509.Certificate, signRootCert *x509.Certificate, ordererUrls []string) OrdererOrg {
+func CreateOrdererOrg(mspID string, tlsRootCert *x509.Certificate, signRootCert *x509.Certificate, tlsIntermediateCerts []*x509.Certificate, signIntermediateCerts []*x509.Certificate, ordererUrls []string) OrdererOrg {
 	return OrdererOrg{
-		mspID:        mspID,
-		tlsRootCert:  tlsRootCert,
-		signRootCert: signRootCert,
-		ordererUrls:  ordererUrls,
+		mspID:                 mspID,
+		tlsRootCert:           tlsRootCert,
+		signRootCert:          signRootCert,
+		tlsIntermediateCerts:  tlsIntermediateCerts,
+		signIntermediateCerts: signIntermediateCerts,
+		ordererUrls:           ordererUrls,
 	}
 }
-func CreatePeerOrg(mspID string, tlsRootCert *x509.Certificate, signRootCert *x509.Certificate) PeerOrg {
+func CreatePeerOrg(mspID string, tlsRootCert *x509.Certificate, signRootCert *x509.Certificate, tlsIntermediateCerts []*x509.Certificate, signIntermediateCerts []*x509.Certificate) PeerOrg {
 	return PeerOrg{
-		mspID:        mspID,
-		tlsRootCert:  tlsRootCert,
-		signRootCert: signRootCert,
+		mspID:                 mspID,
+		tlsRootCert:           tlsRootCert,
+		signRootCert:          signRootCert,
+		tlsIntermediateCerts:  tlsIntermediateCerts,
+		signIntermediateCerts: signIntermediateCerts,
 	}
 }
 
@@ -118,7 +130,7 @@ func (s channelStore) GetApplicationChannelBlock(ctx context.Context, opts ...Ch
 
 	var ordererOrgs []configtx.Organization
 	for _, ordOrg := range o.ordererOrgs {
-		genesisOrdererOrg, err := memberToConfigtxOrg(ordOrg.mspID, ordOrg.tlsRootCert, ordOrg.signRootCert, ordOrg.ordererUrls, []configtx.Address{})
+		genesisOrdererOrg, err := memberToConfigtxOrg(ordOrg.mspID, ordOrg.tlsRootCert, ordOrg.signRootCert, ordOrg.tlsIntermediateCerts, ordOrg.signIntermediateCerts, ordOrg.ordererUrls, []configtx.Address{})
 		if err != nil {
 			return nil, err
 		}
@@ -127,7 +139,7 @@ func (s channelStore) GetApplicationChannelBlock(ctx context.Context, opts ...Ch
 	var peerOrgs []configtx.Organization
 	for _, peerOrg := range o.peerOrgs {
 		anchorPeers := []configtx.Address{}
-		genesisOrdererOrg, err := memberToConfigtxOrg(peerOrg.mspID, peerOrg.tlsRootCert, peerOrg.signRootCert, []string{}, anchorPeers)
+		genesisOrdererOrg, err := memberToConfigtxOrg(peerOrg.mspID, peerOrg.tlsRootCert, peerOrg.signRootCert, peerOrg.tlsIntermediateCerts, peerOrg.signIntermediateCerts, []string{}, anchorPeers)
 		if err != nil {
 			return nil, err
 		}
@@ -229,11 +241,19 @@ func (s channelStore) GetApplicationChannelBlock(ctx context.Context, opts ...Ch
 			},
 		},
 	}
+	o.profile.apply(&channelConfig)
 	channelID := o.name
 	genesisBlock, err := configtx.NewApplicationChannelGenesisBlock(channelConfig, channelID)
 	if err != nil {
 		return nil, err
 	}
+	genesisBlock, err = o.profile.addAnchorPeers(genesisBlock)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.idemixOrgs) > 0 {
+		return addIdemixOrgs(genesisBlock, o.idemixOrgs, o.endorsementSignaturePolicies)
+	}
 	return genesisBlock, nil
 }
 func defaultACLs() map[string]string {
@@ -305,15 +325,16 @@ func defaultACLs() map[string]string {
 		"event/FilteredBlock": "/Channel/Application/Readers",
 	}
 }
-func memberToConfigtxOrg(mspID string, rootTlsCert *x509.Certificate, signTlsCert *x509.Certificate, ordererUrls []string, anchorPeers []configtx.Address) (configtx.Organization, error) {
+func memberToConfigtxOrg(mspID string, rootTlsCert *x509.Certificate, signTlsCert *x509.Certificate, tlsIntermediateCerts []*x509.Certificate, signIntermediateCerts []*x509.Certificate, ordererUrls []string, anchorPeers []configtx.Address) (configtx.Organization, error) {
 	genesisOrg := configtx.Organization{
 		Name: mspID,
 		MSP: configtx.MSP{
 			Name:                 mspID,
 			RootCerts:            []*x509.Certificate{signTlsCert},
+			IntermediateCerts:    signIntermediateCerts,
 			CryptoConfig:         membership.CryptoConfig{},
 			TLSRootCerts:         []*x509.Certificate{rootTlsCert},
-			TLSIntermediateCerts: nil,
+			TLSIntermediateCerts: tlsIntermediateCerts,
 			NodeOUs: membership.NodeOUs{
 				Enable: true,
 				ClientOUIdentifier: membership.OUIdentifier{
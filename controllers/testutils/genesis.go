@@ -2,6 +2,7 @@ package testutils
 
 import (
 	"bytes"
+	"encoding/pem"
 	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-config/protolator"
@@ -12,6 +13,7 @@ import (
 	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/encoder"
 
 	genesisconfig2 "github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/genesisconfig"
+	"github.com/pkg/errors"
 	"io/ioutil"
 	"log"
 	"os"
@@ -41,8 +43,39 @@ type PeerNode struct {
 type PeerOrganization struct {
 	RootCert    string
 	TLSRootCert string
-	MspID       string
-	Peers       []PeerNode
+	// PEM bundles of the intermediate certificates, empty when the CAs are root CAs
+	IntermediateCerts    string
+	TLSIntermediateCerts string
+	MspID                string
+	Peers                []PeerNode
+}
+
+// writeIntermediateCerts writes the intermediate certificates of the organization to the MSP folder, one certificate
+// per file as the MSP only reads the first certificate of each file
+func writeIntermediateCerts(mspDir string, intermediateCerts string, tlsIntermediateCerts string) error {
+	for folder, contents := range map[string]string{
+		"intermediatecerts":    intermediateCerts,
+		"tlsintermediatecerts": tlsIntermediateCerts,
+	} {
+		rest := []byte(contents)
+		for i := 0; ; i++ {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			folderPath := path.Join(mspDir, folder)
+			err := os.MkdirAll(folderPath, os.ModePerm)
+			if err != nil {
+				return err
+			}
+			err = ioutil.WriteFile(path.Join(folderPath, fmt.Sprintf("cert-%d.pem", i)), pem.EncodeToMemory(block), os.ModePerm)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func memberToOrg(member PeerOrganization) (*genesisconfig.Organization, error) {
@@ -71,6 +104,10 @@ func memberToOrg(member PeerOrganization) (*genesisconfig.Organization, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = writeIntermediateCerts(mspDir, member.IntermediateCerts, member.TLSIntermediateCerts)
+	if err != nil {
+		return nil, err
+	}
 	configNodeOU := `
 NodeOUs:
   Enable: true
@@ -134,7 +171,10 @@ type OrdererOrganization struct {
 	Nodes        []OrdererNode
 	RootTLSCert  string
 	RootSignCert string
-	MspID        string
+	// PEM bundles of the intermediate certificates, empty when the CAs are root CAs
+	IntermediateTLSCerts  string
+	IntermediateSignCerts string
+	MspID                 string
 }
 
 func memberToOrgUpdate(member PeerOrganization) (*genesisconfig2.Organization, error) {
@@ -163,6 +203,10 @@ func memberToOrgUpdate(member PeerOrganization) (*genesisconfig2.Organization, e
 	if err != nil {
 		return nil, err
 	}
+	err = writeIntermediateCerts(mspDir, member.IntermediateCerts, member.TLSIntermediateCerts)
+	if err != nil {
+		return nil, err
+	}
 	configNodeOU := `
 NodeOUs:
   Enable: true
@@ -192,34 +236,48 @@ NodeOUs:
 		})
 	}
 	genesisOrg := &genesisconfig2.Organization{
-		Name:    mspID,
-		ID:      mspID,
-		MSPDir:  mspDir,
-		MSPType: "bccsp",
-		Policies: map[string]*genesisconfig2.Policy{
-			"Admins": {
-				Type: "Signature",
-				Rule: fmt.Sprintf("OR('%s.admin')", mspID),
-			},
-			"Endorsement": {
-				Type: "Signature",
-				Rule: fmt.Sprintf("OR('%s.peer')", mspID),
-			},
-			"Readers": {
-				Type: "Signature",
-				Rule: fmt.Sprintf("OR('%s.admin', '%s.peer', '%s.client')", mspID, mspID, mspID),
-			},
-			"Writers": {
-				Type: "Signature",
-				Rule: fmt.Sprintf("OR('%s.admin', '%s.client')", mspID, mspID),
-			},
-		},
+		Name:          mspID,
+		ID:            mspID,
+		MSPDir:        mspDir,
+		MSPType:       "bccsp",
+		Policies:      DefaultPeerOrgPolicies(mspID),
 		AnchorPeers:   anchorPeers,
 		SkipAsForeign: false,
 	}
 	return genesisOrg, nil
 }
 
+// DefaultPeerOrgPolicies returns the policies of a peer organization whose MSP has NodeOUs enabled
+func DefaultPeerOrgPolicies(mspID string) map[string]*genesisconfig2.Policy {
+	return map[string]*genesisconfig2.Policy{
+		"Admins": {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.admin')", mspID),
+		},
+		"Endorsement": {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.peer')", mspID),
+		},
+		"Readers": {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.admin', '%s.peer', '%s.client')", mspID, mspID, mspID),
+		},
+		"Writers": {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.admin', '%s.client')", mspID, mspID),
+		},
+	}
+}
+
+// NewApplicationOrgGroup returns the application org group of the peer organization, with NodeOUs enabled in its MSP
+func NewApplicationOrgGroup(member PeerOrganization) (*cb.ConfigGroup, error) {
+	org, err := memberToOrgUpdate(member)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.NewApplicationOrgGroup(org)
+}
+
 type AddConsortiumRequest struct {
 	Name          string
 	Organizations []PeerOrganization
@@ -276,6 +334,32 @@ func AddConsortiumToConfig(channelConfig *cb.Config, request AddConsortiumReques
 	return modifiedConfig, nil
 }
 
+// RemoveOrgFromConsortium returns a copy of the system channel config without the organization in the consortium
+func RemoveOrgFromConsortium(channelConfig *cb.Config, consortium string, mspID string) (*cb.Config, error) {
+	modifiedConfig := &cb.Config{}
+	modifiedConfigBytes, err := proto.Marshal(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = proto.Unmarshal(modifiedConfigBytes, modifiedConfig)
+	if err != nil {
+		return nil, err
+	}
+	consortiums, ok := modifiedConfig.ChannelGroup.Groups[channelconfig.ConsortiumsGroupKey]
+	if !ok {
+		return nil, errors.New("the channel doesn't have consortiums, it's not a system channel")
+	}
+	consortiumGroup, ok := consortiums.Groups[consortium]
+	if !ok {
+		return nil, errors.Errorf("consortium %s not found", consortium)
+	}
+	if _, ok := consortiumGroup.Groups[mspID]; !ok {
+		return nil, errors.Errorf("msp ID %s not found in consortium %s", mspID, consortium)
+	}
+	delete(consortiumGroup.Groups, mspID)
+	return modifiedConfig, nil
+}
+
 type OrdererCapabilities struct {
 	V2_0 bool
 }
@@ -400,6 +484,10 @@ func GetProfileConfig(
 		if err != nil {
 			return nil, err
 		}
+		err = writeIntermediateCerts(ordererOrgMspDir, org.IntermediateSignCerts, org.IntermediateTLSCerts)
+		if err != nil {
+			return nil, err
+		}
 		configNodeOU := `
 NodeOUs:
   Enable: true
@@ -636,6 +724,10 @@ func GetChannelProfileConfig(
 	if err != nil {
 		return nil, err
 	}
+	err = writeIntermediateCerts(ordererOrgMspDir, ordService.IntermediateSignCerts, ordService.IntermediateTLSCerts)
+	if err != nil {
+		return nil, err
+	}
 	configNodeOU := `
 NodeOUs:
   Enable: true
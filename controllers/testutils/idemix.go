@@ -0,0 +1,149 @@
+package testutils
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/encoder"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/genesisconfig"
+	"github.com/pkg/errors"
+)
+
+type IdemixOrg struct {
+	mspID               string
+	issuerPublicKey     []byte
+	revocationPublicKey []byte
+}
+
+func CreateIdemixOrg(mspID string, issuerPublicKey []byte, revocationPublicKey []byte) IdemixOrg {
+	return IdemixOrg{
+		mspID:               mspID,
+		issuerPublicKey:     issuerPublicKey,
+		revocationPublicKey: revocationPublicKey,
+	}
+}
+
+func WithIdemixOrgs(idemixOrgs ...IdemixOrg) ChannelOption {
+	return func(o *CreateChannelOptions) {
+		o.idemixOrgs = idemixOrgs
+	}
+}
+
+// WithEndorsementSignaturePolicies replaces the implicit meta Endorsement and LifecycleEndorsement policies of a
+// channel with Idemix orgs by signature policies over the orgs able to endorse, see
+// utils.UpdateIdemixEndorsementPolicies
+func WithEndorsementSignaturePolicies() ChannelOption {
+	return func(o *CreateChannelOptions) {
+		o.endorsementSignaturePolicies = true
+	}
+}
+
+// NewIdemixOrgGroup returns the application org group of an Idemix MSP, the org has no Endorsement policy since
+// Idemix identities can't endorse
+func NewIdemixOrgGroup(mspID string, issuerPublicKey []byte, revocationPublicKey []byte) (*cb.ConfigGroup, error) {
+	if len(issuerPublicKey) == 0 {
+		return nil, errors.Errorf("the issuer public key of the Idemix organization %s is required", mspID)
+	}
+	idemixConfig, err := proto.Marshal(&mb.IdemixMSPConfig{
+		Name:         mspID,
+		Ipk:          issuerPublicKey,
+		RevocationPk: revocationPublicKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mspValue := channelconfig.MSPValue(&mb.MSPConfig{
+		Type:   utils.IdemixMSPType,
+		Config: idemixConfig,
+	})
+	mspValueBytes, err := proto.Marshal(mspValue.Value())
+	if err != nil {
+		return nil, err
+	}
+	group := protoutil.NewConfigGroup()
+	group.ModPolicy = channelconfig.AdminsPolicyKey
+	group.Values[mspValue.Key()] = &cb.ConfigValue{
+		Value:     mspValueBytes,
+		ModPolicy: channelconfig.AdminsPolicyKey,
+	}
+	err = encoder.AddPolicies(group, map[string]*genesisconfig.Policy{
+		channelconfig.AdminsPolicyKey: {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.admin')", mspID),
+		},
+		channelconfig.ReadersPolicyKey: {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.member')", mspID),
+		},
+		channelconfig.WritersPolicyKey: {
+			Type: "Signature",
+			Rule: fmt.Sprintf("OR('%s.member')", mspID),
+		},
+	}, channelconfig.AdminsPolicyKey)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// addIdemixOrgs adds the Idemix orgs to the application group of the genesis block, configtx only supports X.509 MSPs.
+// The endorsement policies are replaced by signature policies when endorsementSignaturePolicies is set
+func addIdemixOrgs(block *cb.Block, idemixOrgs []IdemixOrg, endorsementSignaturePolicies bool) (*cb.Block, error) {
+	return updateGenesisConfig(block, func(config *cb.Config) error {
+		application, ok := config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+		if !ok {
+			return errors.New("application group not found in the genesis block")
+		}
+		for _, idemixOrg := range idemixOrgs {
+			group, err := NewIdemixOrgGroup(idemixOrg.mspID, idemixOrg.issuerPublicKey, idemixOrg.revocationPublicKey)
+			if err != nil {
+				return err
+			}
+			application.Groups[idemixOrg.mspID] = group
+		}
+		if !endorsementSignaturePolicies {
+			return nil
+		}
+		return utils.UpdateIdemixEndorsementPolicies(nil, application)
+	})
+}
+
+// updateGenesisConfig modifies the config of the genesis block and recomputes the hash of its data
+func updateGenesisConfig(block *cb.Block, update func(config *cb.Config) error) (*cb.Block, error) {
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+	configEnvelope := &cb.ConfigEnvelope{}
+	err = proto.Unmarshal(payload.Data, configEnvelope)
+	if err != nil {
+		return nil, err
+	}
+	err = update(configEnvelope.Config)
+	if err != nil {
+		return nil, err
+	}
+	payload.Data, err = proto.Marshal(configEnvelope)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Payload, err = proto.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	block.Data.Data[0], err = proto.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	return block, nil
+}
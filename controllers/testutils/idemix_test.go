@@ -0,0 +1,144 @@
+package testutils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+	bccsputils "github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	. "github.com/onsi/gomega"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCA returns a CA whose certificate has a low-S signature, the MSP sanitizes the signatures of the CA
+// certificates to low-S and fails to verify the chain of a self-signed one that isn't
+func newTestCA(t *testing.T, name string) testCA {
+	for {
+		cert, key, err := certs.CreateCA(pkix.Name{CommonName: name, Organization: []string{name}}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, s, err := bccsputils.UnmarshalECDSASignature(cert.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lowS, err := bccsputils.IsLowS(&key.PublicKey, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lowS {
+			return testCA{cert: cert, key: key}
+		}
+	}
+}
+
+// signedByPeer returns data signed by a peer identity issued by the CA
+func (ca testCA) signedByPeer(t *testing.T, mspID string, data []byte) *protoutil.SignedData {
+	g := NewWithT(t)
+	cert, key, err := certs.CreateCertificate(ca.cert, ca.key, pkix.Name{CommonName: "peer0", OrganizationalUnit: []string{"peer"}}, nil, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	identity, err := proto.Marshal(&mb.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	digest := sha256.Sum256(data)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	g.Expect(err).NotTo(HaveOccurred())
+	signature, err = bccsputils.SignatureToLowS(&key.PublicKey, signature)
+	g.Expect(err).NotTo(HaveOccurred())
+	return &protoutil.SignedData{Data: data, Identity: identity, Signature: signature}
+}
+
+func (ca testCA) peerOrganization(mspID string) PeerOrganization {
+	certPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))
+	return PeerOrganization{MspID: mspID, RootCert: certPem, TLSRootCert: certPem}
+}
+
+func genesisConfig(t *testing.T, block *cb.Block) *cb.Config {
+	g := NewWithT(t)
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	g.Expect(err).NotTo(HaveOccurred())
+	configEnvelope := &cb.ConfigEnvelope{}
+	g.Expect(proto.Unmarshal(payload.Data, configEnvelope)).To(Succeed())
+	return configEnvelope.Config
+}
+
+// evaluateApplicationPolicy evaluates the policy of the application group of the channel as the peers do
+func evaluateApplicationPolicy(t *testing.T, config *cb.Config, policyName string, signedData ...*protoutil.SignedData) error {
+	g := NewWithT(t)
+	cryptoProvider, err := sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore())
+	g.Expect(err).NotTo(HaveOccurred())
+	bundle, err := channelconfig.NewBundle("mychannel", config, cryptoProvider)
+	g.Expect(err).NotTo(HaveOccurred())
+	policy, ok := bundle.PolicyManager().GetPolicy("/Channel/Application/" + policyName)
+	g.Expect(ok).To(BeTrue())
+	return policy.EvaluateSignedData(signedData)
+}
+
+func TestIdemixOrgChaincodeApproval(t *testing.T) {
+	g := NewWithT(t)
+	org1CA := newTestCA(t, "org1-ca")
+	org2CA := newTestCA(t, "org2-ca")
+	ordererCA := newTestCA(t, "orderer-ca")
+	idemixKeys, err := certs.GenerateIdemixIssuerKeys()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	options := []ChannelOption{
+		WithName("mychannel"),
+		WithOrdererOrgs(CreateOrdererOrg("OrdererMSP", ordererCA.cert, ordererCA.cert, nil, nil, []string{"orderer0:7050"})),
+		WithConsenters(CreateConsenter("orderer0", 7050, ordererCA.cert)),
+		WithPeerOrgs(CreatePeerOrg("Org1MSP", org1CA.cert, org1CA.cert, nil, nil)),
+		WithIdemixOrgs(CreateIdemixOrg("IdemixMSP", idemixKeys[certs.IdemixIssuerPublicKey], idemixKeys[certs.IdemixRevocationPublicKey])),
+	}
+	policyNames := []string{"Endorsement", "LifecycleEndorsement"}
+	data := []byte("approve chaincode")
+	org1Peer := org1CA.signedByPeer(t, "Org1MSP", data)
+
+	// the implicit meta policies are kept unless asked for, MAJORITY counts the Idemix org
+	block, err := NewChannelStore().GetApplicationChannelBlock(context.Background(), options...)
+	g.Expect(err).NotTo(HaveOccurred())
+	config := genesisConfig(t, block)
+	for _, policyName := range policyNames {
+		g.Expect(evaluateApplicationPolicy(t, config, policyName, org1Peer)).NotTo(Succeed(), policyName)
+	}
+
+	// the peer of the only X.509 org approves and endorses alone, the Idemix org can't endorse
+	block, err = NewChannelStore().GetApplicationChannelBlock(context.Background(), append(options, WithEndorsementSignaturePolicies())...)
+	g.Expect(err).NotTo(HaveOccurred())
+	config = genesisConfig(t, block)
+	for _, policyName := range policyNames {
+		g.Expect(evaluateApplicationPolicy(t, config, policyName, org1Peer)).To(Succeed(), policyName)
+	}
+
+	// a new X.509 org is required by MAJORITY once it joins the channel
+	previous := proto.Clone(config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]).(*cb.ConfigGroup)
+	application := config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+	application.Groups["Org2MSP"], err = NewApplicationOrgGroup(org2CA.peerOrganization("Org2MSP"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(utils.UpdateIdemixEndorsementPolicies(previous, application)).To(Succeed())
+	org2Peer := org2CA.signedByPeer(t, "Org2MSP", data)
+	for _, policyName := range policyNames {
+		g.Expect(evaluateApplicationPolicy(t, config, policyName, org1Peer)).NotTo(Succeed(), policyName)
+		g.Expect(evaluateApplicationPolicy(t, config, policyName, org1Peer, org2Peer)).To(Succeed(), policyName)
+	}
+}
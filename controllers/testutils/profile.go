@@ -0,0 +1,304 @@
+package testutils
+
+import (
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/hyperledger/fabric-config/configtx/orderer"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/genesisconfig"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ChannelProfile overrides the defaults of the application channel genesis block, the zero values and the missing
+// policies and ACLs keep the defaults
+type ChannelProfile struct {
+	BatchTimeout    time.Duration
+	BatchSize       orderer.BatchSize
+	EtcdRaftOptions orderer.EtcdRaftOptions
+
+	OrdererCapabilities     []string
+	ApplicationCapabilities []string
+	ChannelCapabilities     []string
+
+	ChannelPolicies     map[string]configtx.Policy
+	OrdererPolicies     map[string]configtx.Policy
+	ApplicationPolicies map[string]configtx.Policy
+	// Policies of the organizations by MSP ID
+	OrganizationPolicies map[string]map[string]configtx.Policy
+	ACLs                 map[string]string
+	// Anchor peers of the application organizations by MSP ID
+	AnchorPeers map[string][]configtx.Address
+
+	// MSP IDs of the organizations of a configtx.yaml profile
+	ApplicationOrganizations []string
+	OrdererOrganizations     []string
+}
+
+func WithProfile(profile ChannelProfile) ChannelOption {
+	return func(o *CreateChannelOptions) {
+		o.profile = profile
+	}
+}
+
+// apply overrides the defaults of the channel config with the profile
+func (p ChannelProfile) apply(channelConfig *configtx.Channel) {
+	ordererConfig := &channelConfig.Orderer
+	if p.BatchTimeout != 0 {
+		ordererConfig.BatchTimeout = p.BatchTimeout
+	}
+	if p.BatchSize.MaxMessageCount != 0 {
+		ordererConfig.BatchSize.MaxMessageCount = p.BatchSize.MaxMessageCount
+	}
+	if p.BatchSize.AbsoluteMaxBytes != 0 {
+		ordererConfig.BatchSize.AbsoluteMaxBytes = p.BatchSize.AbsoluteMaxBytes
+	}
+	if p.BatchSize.PreferredMaxBytes != 0 {
+		ordererConfig.BatchSize.PreferredMaxBytes = p.BatchSize.PreferredMaxBytes
+	}
+	raftOptions := &ordererConfig.EtcdRaft.Options
+	if p.EtcdRaftOptions.TickInterval != "" {
+		raftOptions.TickInterval = p.EtcdRaftOptions.TickInterval
+	}
+	if p.EtcdRaftOptions.ElectionTick != 0 {
+		raftOptions.ElectionTick = p.EtcdRaftOptions.ElectionTick
+	}
+	if p.EtcdRaftOptions.HeartbeatTick != 0 {
+		raftOptions.HeartbeatTick = p.EtcdRaftOptions.HeartbeatTick
+	}
+	if p.EtcdRaftOptions.MaxInflightBlocks != 0 {
+		raftOptions.MaxInflightBlocks = p.EtcdRaftOptions.MaxInflightBlocks
+	}
+	if p.EtcdRaftOptions.SnapshotIntervalSize != 0 {
+		raftOptions.SnapshotIntervalSize = p.EtcdRaftOptions.SnapshotIntervalSize
+	}
+	if p.OrdererCapabilities != nil {
+		ordererConfig.Capabilities = p.OrdererCapabilities
+	}
+	if p.ApplicationCapabilities != nil {
+		channelConfig.Application.Capabilities = p.ApplicationCapabilities
+	}
+	if p.ChannelCapabilities != nil {
+		channelConfig.Capabilities = p.ChannelCapabilities
+	}
+	mergePolicies(channelConfig.Policies, p.ChannelPolicies)
+	mergePolicies(ordererConfig.Policies, p.OrdererPolicies)
+	mergePolicies(channelConfig.Application.Policies, p.ApplicationPolicies)
+	for key, policyRef := range p.ACLs {
+		channelConfig.Application.ACLs[key] = policyRef
+	}
+	for _, org := range ordererConfig.Organizations {
+		mergePolicies(org.Policies, p.OrganizationPolicies[org.Name])
+	}
+	for _, org := range channelConfig.Application.Organizations {
+		mergePolicies(org.Policies, p.OrganizationPolicies[org.Name])
+	}
+}
+
+// addAnchorPeers sets the anchor peers of the profile in the genesis block, the genesis block of configtx ignores
+// the anchor peers of the organizations
+func (p ChannelProfile) addAnchorPeers(block *cb.Block) (*cb.Block, error) {
+	if len(p.AnchorPeers) == 0 {
+		return block, nil
+	}
+	return updateGenesisConfig(block, func(config *cb.Config) error {
+		channelConfig := configtx.New(config)
+		for mspID, anchorPeers := range p.AnchorPeers {
+			org := channelConfig.Application().Organization(mspID)
+			if org == nil {
+				continue
+			}
+			for _, anchorPeer := range anchorPeers {
+				err := org.AddAnchorPeer(anchorPeer)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		config.ChannelGroup = channelConfig.UpdatedConfig().ChannelGroup
+		return nil
+	})
+}
+
+func mergePolicies(policies map[string]configtx.Policy, overrides map[string]configtx.Policy) {
+	for name, policy := range overrides {
+		policies[name] = policy
+	}
+}
+
+// configtxFile is a configtx.yaml, only the profiles are read since the sections of the top level are referenced
+// by them with YAML anchors
+type configtxFile struct {
+	Profiles map[string]*configtxProfile `yaml:"Profiles"`
+}
+
+type configtxProfile struct {
+	Capabilities map[string]bool                  `yaml:"Capabilities"`
+	Policies     map[string]*genesisconfig.Policy `yaml:"Policies"`
+	Orderer      *configtxOrderer                 `yaml:"Orderer"`
+	Application  *configtxApplication             `yaml:"Application"`
+}
+
+type configtxOrderer struct {
+	OrdererType  string `yaml:"OrdererType"`
+	BatchTimeout string `yaml:"BatchTimeout"`
+	BatchSize    struct {
+		MaxMessageCount   uint32 `yaml:"MaxMessageCount"`
+		AbsoluteMaxBytes  string `yaml:"AbsoluteMaxBytes"`
+		PreferredMaxBytes string `yaml:"PreferredMaxBytes"`
+	} `yaml:"BatchSize"`
+	EtcdRaft struct {
+		Options struct {
+			TickInterval         string `yaml:"TickInterval"`
+			ElectionTick         uint32 `yaml:"ElectionTick"`
+			HeartbeatTick        uint32 `yaml:"HeartbeatTick"`
+			MaxInflightBlocks    uint32 `yaml:"MaxInflightBlocks"`
+			SnapshotIntervalSize string `yaml:"SnapshotIntervalSize"`
+		} `yaml:"Options"`
+	} `yaml:"EtcdRaft"`
+	Organizations []*genesisconfig.Organization    `yaml:"Organizations"`
+	Capabilities  map[string]bool                  `yaml:"Capabilities"`
+	Policies      map[string]*genesisconfig.Policy `yaml:"Policies"`
+}
+
+type configtxApplication struct {
+	Organizations []*genesisconfig.Organization    `yaml:"Organizations"`
+	Capabilities  map[string]bool                  `yaml:"Capabilities"`
+	Policies      map[string]*genesisconfig.Policy `yaml:"Policies"`
+	ACLs          map[string]string                `yaml:"ACLs"`
+}
+
+// LoadConfigtxProfile reads a profile of a configtx.yaml as used by configtxgen, the MSP directories and the
+// consenters of the profile are ignored since they come from the organizations of the cluster
+func LoadConfigtxProfile(path string, profileName string) (*ChannelProfile, error) {
+	configtxBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &configtxFile{}
+	err = yaml.Unmarshal(configtxBytes, file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	configtxProfile, ok := file.Profiles[profileName]
+	if !ok {
+		return nil, errors.Errorf("profile %s not found in %s", profileName, path)
+	}
+	profile := &ChannelProfile{
+		ChannelCapabilities:  enabledCapabilities(configtxProfile.Capabilities),
+		ChannelPolicies:      convertPolicies(configtxProfile.Policies),
+		OrganizationPolicies: map[string]map[string]configtx.Policy{},
+		AnchorPeers:          map[string][]configtx.Address{},
+	}
+	if ord := configtxProfile.Orderer; ord != nil {
+		if ord.OrdererType != "" && ord.OrdererType != genesisconfig.EtcdRaft {
+			return nil, errors.Errorf("orderer type %s of profile %s is not supported, only %s", ord.OrdererType, profileName, genesisconfig.EtcdRaft)
+		}
+		if ord.BatchTimeout != "" {
+			profile.BatchTimeout, err = time.ParseDuration(ord.BatchTimeout)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid batch timeout %s", ord.BatchTimeout)
+			}
+		}
+		profile.BatchSize.MaxMessageCount = ord.BatchSize.MaxMessageCount
+		profile.BatchSize.AbsoluteMaxBytes, err = ParseByteSize(ord.BatchSize.AbsoluteMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		profile.BatchSize.PreferredMaxBytes, err = ParseByteSize(ord.BatchSize.PreferredMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		options := ord.EtcdRaft.Options
+		profile.EtcdRaftOptions = orderer.EtcdRaftOptions{
+			TickInterval:      options.TickInterval,
+			ElectionTick:      options.ElectionTick,
+			HeartbeatTick:     options.HeartbeatTick,
+			MaxInflightBlocks: options.MaxInflightBlocks,
+		}
+		profile.EtcdRaftOptions.SnapshotIntervalSize, err = ParseByteSize(options.SnapshotIntervalSize)
+		if err != nil {
+			return nil, err
+		}
+		profile.OrdererCapabilities = enabledCapabilities(ord.Capabilities)
+		profile.OrdererPolicies = convertPolicies(ord.Policies)
+		for _, org := range ord.Organizations {
+			profile.OrdererOrganizations = append(profile.OrdererOrganizations, org.ID)
+			profile.OrganizationPolicies[org.ID] = convertPolicies(org.Policies)
+		}
+	}
+	if app := configtxProfile.Application; app != nil {
+		profile.ApplicationCapabilities = enabledCapabilities(app.Capabilities)
+		profile.ApplicationPolicies = convertPolicies(app.Policies)
+		profile.ACLs = app.ACLs
+		for _, org := range app.Organizations {
+			profile.ApplicationOrganizations = append(profile.ApplicationOrganizations, org.ID)
+			profile.OrganizationPolicies[org.ID] = convertPolicies(org.Policies)
+			if len(org.AnchorPeers) == 0 {
+				continue
+			}
+			var anchorPeers []configtx.Address
+			for _, anchorPeer := range org.AnchorPeers {
+				anchorPeers = append(anchorPeers, configtx.Address{Host: anchorPeer.Host, Port: anchorPeer.Port})
+			}
+			profile.AnchorPeers[org.ID] = anchorPeers
+		}
+	}
+	return profile, nil
+}
+
+// enabledCapabilities returns nil when there are no capabilities, so that the defaults are kept
+func enabledCapabilities(capabilities map[string]bool) []string {
+	var enabled []string
+	for capability, ok := range capabilities {
+		if ok {
+			enabled = append(enabled, capability)
+		}
+	}
+	sort.Strings(enabled)
+	return enabled
+}
+
+func convertPolicies(policies map[string]*genesisconfig.Policy) map[string]configtx.Policy {
+	converted := map[string]configtx.Policy{}
+	for name, policy := range policies {
+		converted[name] = configtx.Policy{Type: policy.Type, Rule: policy.Rule}
+	}
+	return converted
+}
+
+var byteSizeRegexp = regexp.MustCompile(`^(\d+)\s*([KMG]B)?$`)
+
+// ParseByteSize parses a size of configtx.yaml like 99 MB or 512 KB, a number without unit is a number of bytes
+func ParseByteSize(size string) (uint32, error) {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, nil
+	}
+	match := byteSizeRegexp.FindStringSubmatch(strings.ToUpper(size))
+	if match == nil {
+		return 0, errors.Errorf("invalid size %s", size)
+	}
+	n, err := strconv.ParseUint(match[1], 10, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid size %s", size)
+	}
+	switch match[2] {
+	case "KB":
+		n *= 1024
+	case "MB":
+		n *= 1024 * 1024
+	case "GB":
+		n *= 1024 * 1024 * 1024
+	}
+	if n > 1<<32-1 {
+		return 0, errors.Errorf("size %s is too large", size)
+	}
+	return uint32(n), nil
+}
@@ -0,0 +1,37 @@
+package testutils
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    uint32
+		wantErr bool
+	}{
+		{size: "", want: 0},
+		{size: "512", want: 512},
+		{size: "512 KB", want: 512 * 1024},
+		{size: "99 MB", want: 99 * 1024 * 1024},
+		{size: "10mb", want: 10 * 1024 * 1024},
+		{size: "3 GB", want: 3 * 1024 * 1024 * 1024},
+		{size: "4 GB", wantErr: true},
+		{size: "1 TB", wantErr: true},
+		{size: "-1 MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			g := NewWithT(t)
+			size, err := ParseByteSize(tt.size)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(size).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package testutils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	ob "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/orderer/etcdraft"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/pkg/errors"
+)
+
+const etcdRaftConsensusType = "etcdraft"
+
+// ChannelSettings are the batch, consensus and capability parameters of an existing channel, the zero values keep
+// the current parameters of the channel
+type ChannelSettings struct {
+	BatchTimeout      time.Duration
+	MaxMessageCount   uint32
+	AbsoluteMaxBytes  uint32
+	PreferredMaxBytes uint32
+
+	TickInterval         string
+	ElectionTick         uint32
+	HeartbeatTick        uint32
+	MaxInflightBlocks    uint32
+	SnapshotIntervalSize uint32
+
+	// Capabilities enabled in each section of the channel, they replace the current ones when not nil
+	OrdererCapabilities     []string
+	ApplicationCapabilities []string
+	ChannelCapabilities     []string
+}
+
+// ApplyChannelSettings sets the settings in the config of the channel, the values that don't change keep their bytes
+func ApplyChannelSettings(config *cb.Config, settings ChannelSettings) error {
+	ordererGroup, ok := config.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	if !ok {
+		return errors.Errorf("channel doesn't have an orderer section")
+	}
+	batchSize := &ob.BatchSize{}
+	err := updateConfigValue(ordererGroup, channelconfig.BatchSizeKey, batchSize, func() {
+		if settings.MaxMessageCount != 0 {
+			batchSize.MaxMessageCount = settings.MaxMessageCount
+		}
+		if settings.AbsoluteMaxBytes != 0 {
+			batchSize.AbsoluteMaxBytes = settings.AbsoluteMaxBytes
+		}
+		if settings.PreferredMaxBytes != 0 {
+			batchSize.PreferredMaxBytes = settings.PreferredMaxBytes
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if batchSize.PreferredMaxBytes > batchSize.AbsoluteMaxBytes {
+		return errors.Errorf(
+			"preferred max bytes %d is greater than absolute max bytes %d",
+			batchSize.PreferredMaxBytes,
+			batchSize.AbsoluteMaxBytes,
+		)
+	}
+	if settings.BatchTimeout != 0 {
+		batchTimeout := &ob.BatchTimeout{}
+		err = updateConfigValue(ordererGroup, channelconfig.BatchTimeoutKey, batchTimeout, func() {
+			batchTimeout.Timeout = settings.BatchTimeout.String()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if settings.raftOptionsChanged() {
+		err = applyEtcdRaftOptions(ordererGroup, settings)
+		if err != nil {
+			return err
+		}
+	}
+	if settings.OrdererCapabilities != nil {
+		err = setCapabilities(ordererGroup, settings.OrdererCapabilities)
+		if err != nil {
+			return err
+		}
+	}
+	if settings.ApplicationCapabilities != nil {
+		applicationGroup, ok := config.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+		if !ok {
+			return errors.Errorf("channel doesn't have an application section")
+		}
+		err = setCapabilities(applicationGroup, settings.ApplicationCapabilities)
+		if err != nil {
+			return err
+		}
+	}
+	if settings.ChannelCapabilities != nil {
+		err = setCapabilities(config.ChannelGroup, settings.ChannelCapabilities)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s ChannelSettings) raftOptionsChanged() bool {
+	return s.TickInterval != "" || s.ElectionTick != 0 || s.HeartbeatTick != 0 || s.MaxInflightBlocks != 0 || s.SnapshotIntervalSize != 0
+}
+
+func applyEtcdRaftOptions(ordererGroup *cb.ConfigGroup, settings ChannelSettings) error {
+	if settings.TickInterval != "" {
+		if _, err := time.ParseDuration(settings.TickInterval); err != nil {
+			return errors.Wrapf(err, "invalid tick interval %s", settings.TickInterval)
+		}
+	}
+	consensusType := &ob.ConsensusType{}
+	value, ok := ordererGroup.Values[channelconfig.ConsensusTypeKey]
+	if !ok {
+		return errors.Errorf("channel doesn't have a consensus type")
+	}
+	err := proto.Unmarshal(value.Value, consensusType)
+	if err != nil {
+		return err
+	}
+	if consensusType.Type != etcdRaftConsensusType {
+		return errors.Errorf("raft options can't be set on a channel with consensus type %s", consensusType.Type)
+	}
+	metadata := &etcdraft.ConfigMetadata{}
+	err = proto.Unmarshal(consensusType.Metadata, metadata)
+	if err != nil {
+		return err
+	}
+	options := proto.Clone(metadata).(*etcdraft.ConfigMetadata).Options
+	if options == nil {
+		options = &etcdraft.Options{}
+	}
+	if settings.TickInterval != "" {
+		options.TickInterval = settings.TickInterval
+	}
+	if settings.ElectionTick != 0 {
+		options.ElectionTick = settings.ElectionTick
+	}
+	if settings.HeartbeatTick != 0 {
+		options.HeartbeatTick = settings.HeartbeatTick
+	}
+	if settings.MaxInflightBlocks != 0 {
+		options.MaxInflightBlocks = settings.MaxInflightBlocks
+	}
+	if settings.SnapshotIntervalSize != 0 {
+		options.SnapshotIntervalSize = settings.SnapshotIntervalSize
+	}
+	if options.ElectionTick <= options.HeartbeatTick {
+		return errors.Errorf("election tick %d must be greater than heartbeat tick %d", options.ElectionTick, options.HeartbeatTick)
+	}
+	if proto.Equal(options, metadata.Options) {
+		return nil
+	}
+	metadata.Options = options
+	consensusType.Metadata, err = proto.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	value.Value, err = proto.Marshal(consensusType)
+	return err
+}
+
+func setCapabilities(group *cb.ConfigGroup, capabilityNames []string) error {
+	capabilities := &cb.Capabilities{}
+	return updateConfigValue(group, channelconfig.CapabilitiesKey, capabilities, func() {
+		capabilities.Capabilities = map[string]*cb.Capability{}
+		for _, capability := range capabilityNames {
+			capabilities.Capabilities[capability] = &cb.Capability{}
+		}
+	})
+}
+
+// updateConfigValue unmarshals the value of the group in msg, applies the update and marshals it back when it changes,
+// the value is created with the Admins mod policy when it doesn't exist
+func updateConfigValue(group *cb.ConfigGroup, key string, msg proto.Message, update func()) error {
+	value, ok := group.Values[key]
+	if ok {
+		err := proto.Unmarshal(value.Value, msg)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unmarshal %s", key)
+		}
+	}
+	previous := proto.Clone(msg)
+	update()
+	if ok && proto.Equal(previous, msg) {
+		return nil
+	}
+	valueBytes, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if group.Values == nil {
+			group.Values = map[string]*cb.ConfigValue{}
+		}
+		value = &cb.ConfigValue{ModPolicy: channelconfig.AdminsPolicyKey}
+		group.Values[key] = value
+	}
+	value.Value = valueBytes
+	return nil
+}
+
+// GroupCapabilities returns the capabilities enabled in the group of the channel config
+func GroupCapabilities(group *cb.ConfigGroup) ([]string, error) {
+	value, ok := group.Values[channelconfig.CapabilitiesKey]
+	if !ok {
+		return nil, nil
+	}
+	capabilities := &cb.Capabilities{}
+	err := proto.Unmarshal(value.Value, capabilities)
+	if err != nil {
+		return nil, err
+	}
+	var capabilityNames []string
+	for capability := range capabilities.Capabilities {
+		capabilityNames = append(capabilityNames, capability)
+	}
+	sort.Strings(capabilityNames)
+	return capabilityNames, nil
+}
+
+var fabricVersionRegexp = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseFabricVersion returns the major, minor and patch numbers of a version like 2.3.0 or an image tag like amd64-2.2.1
+func parseFabricVersion(version string) ([3]int, error) {
+	var numbers [3]int
+	match := fabricVersionRegexp.FindStringSubmatch(version)
+	if match == nil {
+		return numbers, errors.Errorf("can't find a Fabric version in %s", version)
+	}
+	for i, number := range match[1:] {
+		if number == "" {
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return numbers, err
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// capabilityFabricVersion returns the first Fabric version supporting a capability, V1_4_2 requires Fabric 1.4.2
+func capabilityFabricVersion(capability string) ([3]int, error) {
+	if !strings.HasPrefix(capability, "V") {
+		return [3]int{}, errors.Errorf("invalid capability %s", capability)
+	}
+	return parseFabricVersion(strings.ReplaceAll(strings.TrimPrefix(capability, "V"), "_", "."))
+}
+
+// CheckCapabilitiesSupported returns an error when the Fabric version, or an image tag with it, doesn't support the
+// capabilities
+func CheckCapabilitiesSupported(version string, capabilities []string) error {
+	current, err := parseFabricVersion(version)
+	if err != nil {
+		return err
+	}
+	for _, capability := range capabilities {
+		required, err := capabilityFabricVersion(capability)
+		if err != nil {
+			return err
+		}
+		for i := range required {
+			if current[i] > required[i] {
+				break
+			}
+			if current[i] < required[i] {
+				return errors.Errorf(
+					"capability %s requires Fabric %s, found %s",
+					capability,
+					fmt.Sprintf("%d.%d.%d", required[0], required[1], required[2]),
+					version,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// AddedCapabilities returns the capabilities that aren't enabled yet in the group
+func AddedCapabilities(group *cb.ConfigGroup, capabilities []string) ([]string, error) {
+	if len(capabilities) == 0 || group == nil {
+		return nil, nil
+	}
+	current, err := GroupCapabilities(group)
+	if err != nil {
+		return nil, err
+	}
+	var added []string
+	for _, capability := range capabilities {
+		found := false
+		for _, currentCapability := range current {
+			if currentCapability == capability {
+				found = true
+				break
+			}
+		}
+		if !found {
+			added = append(added, capability)
+		}
+	}
+	return added, nil
+}
+
+// NodeVersion is the Fabric version reported by a peer or an orderer of an organization
+type NodeVersion struct {
+	Name    string
+	MSPID   string
+	Version string
+}
+
+// CheckNodeVersions returns an error when a node of the organizations of the group reports a Fabric version that
+// doesn't support the capabilities, or when an organization of the group has no node to check
+func CheckNodeVersions(group *cb.ConfigGroup, capabilities []string, nodes []NodeVersion) error {
+	if len(capabilities) == 0 {
+		return nil
+	}
+	checked := map[string]bool{}
+	for _, node := range nodes {
+		if _, ok := group.Groups[node.MSPID]; !ok {
+			continue
+		}
+		err := CheckCapabilitiesSupported(node.Version, capabilities)
+		if err != nil {
+			return errors.Wrapf(err, "%s doesn't support the capabilities", node.Name)
+		}
+		checked[node.MSPID] = true
+	}
+	var unchecked []string
+	for mspID := range group.Groups {
+		if !checked[mspID] {
+			unchecked = append(unchecked, mspID)
+		}
+	}
+	if len(unchecked) > 0 {
+		sort.Strings(unchecked)
+		return errors.Errorf(
+			"the Fabric version of the nodes of %s can't be checked, they have no nodes in the cluster",
+			strings.Join(unchecked, ", "),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package testutils
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protoutil"
+	. "github.com/onsi/gomega"
+)
+
+func TestCheckCapabilitiesSupported(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      string
+		capabilities []string
+		wantErr      string
+	}{
+		{name: "same version", version: "2.0.0", capabilities: []string{"V2_0"}},
+		{name: "newer version", version: "2.3.0", capabilities: []string{"V1_4_2", "V2_0"}},
+		{name: "image tag", version: "amd64-2.2.0", capabilities: []string{"V2_0"}},
+		{name: "older version", version: "1.4.9", capabilities: []string{"V2_0"}, wantErr: "capability V2_0 requires Fabric 2.0.0, found 1.4.9"},
+		{name: "older patch version", version: "1.4.1", capabilities: []string{"V1_4_2"}, wantErr: "requires Fabric 1.4.2"},
+		{name: "invalid capability", version: "2.0.0", capabilities: []string{"2_0"}, wantErr: "invalid capability"},
+		{name: "no version", version: "latest", capabilities: []string{"V2_0"}, wantErr: "can't find a Fabric version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := CheckCapabilitiesSupported(tt.version, tt.capabilities)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestAddedCapabilities(t *testing.T) {
+	g := NewWithT(t)
+	group := protoutil.NewConfigGroup()
+	g.Expect(setCapabilities(group, []string{"V1_4_2"})).To(Succeed())
+	added, err := AddedCapabilities(group, []string{"V1_4_2", "V2_0"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(added).To(Equal([]string{"V2_0"}))
+	added, err = AddedCapabilities(group, []string{"V1_4_2"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(added).To(BeEmpty())
+	added, err = AddedCapabilities(nil, []string{"V2_0"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(added).To(BeEmpty())
+}
+
+func TestCheckNodeVersions(t *testing.T) {
+	group := protoutil.NewConfigGroup()
+	group.Groups["Org1MSP"] = protoutil.NewConfigGroup()
+	group.Groups["Org2MSP"] = protoutil.NewConfigGroup()
+	tests := []struct {
+		name         string
+		capabilities []string
+		nodes        []NodeVersion
+		wantErr      string
+	}{
+		{
+			name:         "every org has a node supporting the capabilities",
+			capabilities: []string{"V2_0"},
+			nodes: []NodeVersion{
+				{Name: "peer0-org1", MSPID: "Org1MSP", Version: "2.2.0"},
+				{Name: "peer0-org2", MSPID: "Org2MSP", Version: "2.3.0"},
+				{Name: "peer0-org3", MSPID: "Org3MSP", Version: "1.4.9"},
+			},
+		},
+		{
+			name:         "a node doesn't support the capabilities",
+			capabilities: []string{"V2_0"},
+			nodes: []NodeVersion{
+				{Name: "peer0-org1", MSPID: "Org1MSP", Version: "2.2.0"},
+				{Name: "peer0-org2", MSPID: "Org2MSP", Version: "2.3.0"},
+				{Name: "peer1-org2", MSPID: "Org2MSP", Version: "1.4.9"},
+			},
+			wantErr: "peer1-org2 doesn't support the capabilities",
+		},
+		{
+			name:         "an org has no nodes in the cluster",
+			capabilities: []string{"V2_0"},
+			nodes:        []NodeVersion{{Name: "peer0-org1", MSPID: "Org1MSP", Version: "2.2.0"}},
+			wantErr:      "the Fabric version of the nodes of Org2MSP can't be checked",
+		},
+		{name: "no capabilities to check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := CheckNodeVersions(group, tt.capabilities, tt.nodes)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
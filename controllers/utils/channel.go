@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ChannelUpdateRetryInterval is how long a failed update of the config of a channel waits to be retried
+const ChannelUpdateRetryInterval = 1 * time.Minute
+
+// GetNetworkConfig returns the network config of the Fabric SDK in the key of the secret
+func GetNetworkConfig(ctx context.Context, c client.Client, ns string, ref corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: ref.Name}, secret)
+	if err != nil {
+		return nil, err
+	}
+	networkConfig, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, errors.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+	}
+	return networkConfig, nil
+}
+
+// NewChannelAdminClient returns the SDK of the network config in the secret and a resource management client of the
+// user of the organization, the SDK has to be closed by the caller
+func NewChannelAdminClient(ctx context.Context, c client.Client, ns string, ref corev1.SecretKeySelector, user string, mspID string) (*fabsdk.FabricSDK, *resmgmt.Client, error) {
+	networkConfig, err := GetNetworkConfig(ctx, c, ns, ref)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get the network config")
+	}
+	sdk, err := fabsdk.New(config.FromRaw(networkConfig, "yaml"))
+	if err != nil {
+		return nil, nil, err
+	}
+	resClient, err := resmgmt.New(sdk.Context(
+		fabsdk.WithUser(user),
+		fabsdk.WithOrg(mspID),
+	))
+	if err != nil {
+		sdk.Close()
+		return nil, nil, err
+	}
+	return sdk, resClient, nil
+}
+
+// SaveChannelConfigUpdate submits the marshaled config update to the channel, it returns the ID of the transaction
+func SaveChannelConfigUpdate(resClient *resmgmt.Client, channelID string, configUpdate []byte) (string, error) {
+	envelope, err := protoutil.CreateSignedEnvelope(
+		common.HeaderType_CONFIG_UPDATE,
+		channelID,
+		nil,
+		&common.ConfigUpdateEnvelope{ConfigUpdate: configUpdate},
+		0,
+		0,
+	)
+	if err != nil {
+		return "", err
+	}
+	envelopeBytes, err := proto.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	// the update is signed by the user of the client context, an admin of the organization
+	response, err := resClient.SaveChannel(resmgmt.SaveChannelRequest{
+		ChannelID:     channelID,
+		ChannelConfig: bytes.NewReader(envelopeBytes),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(response.TransactionID), nil
+}
+
+// RetryChannelUpdate returns the result requeuing a resource whose update of the config of a channel failed
+func RetryChannelUpdate(update string) ctrl.Result {
+	log.Infof("%s failed, retrying in %s", update, ChannelUpdateRetryInterval)
+	return ctrl.Result{
+		RequeueAfter: ChannelUpdateRetryInterval,
+	}
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/policydsl"
+	"github.com/pkg/errors"
+)
+
+// IdemixMSPType is the type of the MSPConfig of an Idemix MSP
+const IdemixMSPType = 1
+
+// endorsementPolicyKey is the policy of the orgs able to endorse, Idemix orgs don't have it
+const endorsementPolicyKey = "Endorsement"
+
+// endorsementPolicyKeys are the policies of the application group that require the Endorsement policy of its orgs
+var endorsementPolicyKeys = []string{endorsementPolicyKey, "LifecycleEndorsement"}
+
+// IsIdemixOrgGroup returns whether the org group has the MSP of an Idemix organization
+func IsIdemixOrgGroup(group *cb.ConfigGroup) bool {
+	mspValue, ok := group.Values[channelconfig.MSPKey]
+	if !ok {
+		return false
+	}
+	mspConfig := &mb.MSPConfig{}
+	if err := proto.Unmarshal(mspValue.Value, mspConfig); err != nil {
+		return false
+	}
+	return mspConfig.Type == IdemixMSPType
+}
+
+// endorsingOrgs returns the sorted MSP IDs of the orgs of the application group with an Endorsement policy
+func endorsingOrgs(application *cb.ConfigGroup) []string {
+	var mspIDs []string
+	for mspID, org := range application.Groups {
+		if _, ok := org.Policies[endorsementPolicyKey]; ok {
+			mspIDs = append(mspIDs, mspID)
+		}
+	}
+	sort.Strings(mspIDs)
+	return mspIDs
+}
+
+// endorsementSignaturePolicy returns the signature policy requiring the peers of the orgs as the implicit meta rule
+// would require their Endorsement policies
+func endorsementSignaturePolicy(rule cb.ImplicitMetaPolicy_Rule, mspIDs []string) *cb.SignaturePolicyEnvelope {
+	n := 1
+	switch rule {
+	case cb.ImplicitMetaPolicy_MAJORITY:
+		n = len(mspIDs)/2 + 1
+	case cb.ImplicitMetaPolicy_ALL:
+		n = len(mspIDs)
+	}
+	return policydsl.SignedByNOutOfGivenRole(int32(n), mb.MSPRole_PEER, mspIDs)
+}
+
+// endorsementPolicyRule returns the rule of the implicit meta policy over the Endorsement policies of the orgs, or of
+// the signature policy written by UpdateIdemixEndorsementPolicies for the orgs of the previous application group. The
+// rules requiring as many orgs can't be told apart, MAJORITY is assumed first as the default of the channels
+func endorsementPolicyRule(policy *cb.Policy, previous *cb.ConfigGroup) (cb.ImplicitMetaPolicy_Rule, bool) {
+	switch cb.Policy_PolicyType(policy.Type) {
+	case cb.Policy_IMPLICIT_META:
+		implicitMetaPolicy := &cb.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(policy.Value, implicitMetaPolicy); err != nil {
+			return 0, false
+		}
+		return implicitMetaPolicy.Rule, implicitMetaPolicy.SubPolicy == endorsementPolicyKey
+	case cb.Policy_SIGNATURE:
+		if previous == nil {
+			return 0, false
+		}
+		signaturePolicy := &cb.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(policy.Value, signaturePolicy); err != nil {
+			return 0, false
+		}
+		previousOrgs := endorsingOrgs(previous)
+		for _, rule := range []cb.ImplicitMetaPolicy_Rule{cb.ImplicitMetaPolicy_MAJORITY, cb.ImplicitMetaPolicy_ANY, cb.ImplicitMetaPolicy_ALL} {
+			if proto.Equal(signaturePolicy, endorsementSignaturePolicy(rule, previousOrgs)) {
+				return rule, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// idemixEndorsementPolicyRules returns the rules of the endorsement policies of an application group with Idemix orgs
+// that UpdateIdemixEndorsementPolicies replaces, by policy name
+func idemixEndorsementPolicyRules(previous *cb.ConfigGroup, application *cb.ConfigGroup) map[string]cb.ImplicitMetaPolicy_Rule {
+	hasIdemixOrgs := false
+	for _, org := range application.Groups {
+		if IsIdemixOrgGroup(org) {
+			hasIdemixOrgs = true
+			break
+		}
+	}
+	if !hasIdemixOrgs {
+		return nil
+	}
+	rules := map[string]cb.ImplicitMetaPolicy_Rule{}
+	for _, key := range endorsementPolicyKeys {
+		configPolicy, ok := application.Policies[key]
+		if !ok || configPolicy.Policy == nil {
+			continue
+		}
+		rule, ok := endorsementPolicyRule(configPolicy.Policy, previous)
+		if ok {
+			rules[key] = rule
+		}
+	}
+	return rules
+}
+
+// IdemixEndorsementPolicies returns the names of the Endorsement and LifecycleEndorsement policies of an application
+// group with Idemix orgs that count them, which can't endorse, or that were written by
+// UpdateIdemixEndorsementPolicies for the orgs of the previous application group
+func IdemixEndorsementPolicies(previous *cb.ConfigGroup, application *cb.ConfigGroup) []string {
+	var keys []string
+	rules := idemixEndorsementPolicyRules(previous, application)
+	for _, key := range endorsementPolicyKeys {
+		if _, ok := rules[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// UpdateIdemixEndorsementPolicies replaces the Endorsement and LifecycleEndorsement policies of an application group
+// with Idemix orgs by signature policies over the peers of the orgs able to endorse. An implicit meta policy counts
+// the Idemix orgs, which have no Endorsement policy, so MAJORITY Endorsement can't be satisfied with as many Idemix
+// orgs as X.509 ones. previous is the application group before its orgs changed, so that the signature policies
+// written for its orgs are updated, nil for a new channel. The policies set by the user are kept.
+func UpdateIdemixEndorsementPolicies(previous *cb.ConfigGroup, application *cb.ConfigGroup) error {
+	mspIDs := endorsingOrgs(application)
+	rules := idemixEndorsementPolicyRules(previous, application)
+	for _, key := range endorsementPolicyKeys {
+		rule, ok := rules[key]
+		if !ok {
+			continue
+		}
+		if len(mspIDs) == 0 {
+			return errors.Errorf("the %s policy can't be satisfied, no organization of the channel can endorse", key)
+		}
+		signaturePolicy, err := proto.Marshal(endorsementSignaturePolicy(rule, mspIDs))
+		if err != nil {
+			return err
+		}
+		configPolicy := application.Policies[key]
+		application.Policies[key] = &cb.ConfigPolicy{
+			ModPolicy: configPolicy.ModPolicy,
+			Policy: &cb.Policy{
+				Type:  int32(cb.Policy_SIGNATURE),
+				Value: signaturePolicy,
+			},
+		}
+	}
+	return nil
+}
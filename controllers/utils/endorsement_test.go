@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	mb "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	. "github.com/onsi/gomega"
+)
+
+func TestUpdateIdemixEndorsementPolicies(t *testing.T) {
+	g := NewWithT(t)
+	idemixMSP, err := proto.Marshal(&mb.MSPConfig{Type: IdemixMSPType})
+	g.Expect(err).NotTo(HaveOccurred())
+	idemixOrg := protoutil.NewConfigGroup()
+	idemixOrg.Values[channelconfig.MSPKey] = &cb.ConfigValue{Value: idemixMSP}
+	implicitMeta := func(rule cb.ImplicitMetaPolicy_Rule) *cb.ConfigPolicy {
+		value, err := proto.Marshal(&cb.ImplicitMetaPolicy{Rule: rule, SubPolicy: endorsementPolicyKey})
+		g.Expect(err).NotTo(HaveOccurred())
+		return &cb.ConfigPolicy{ModPolicy: "Admins", Policy: &cb.Policy{Type: int32(cb.Policy_IMPLICIT_META), Value: value}}
+	}
+	x509Org := func() *cb.ConfigGroup {
+		group := protoutil.NewConfigGroup()
+		group.Policies[endorsementPolicyKey] = &cb.ConfigPolicy{}
+		return group
+	}
+	signaturePolicy := func(policy *cb.ConfigPolicy) *cb.SignaturePolicyEnvelope {
+		g.Expect(cb.Policy_PolicyType(policy.Policy.Type)).To(Equal(cb.Policy_SIGNATURE))
+		envelope := &cb.SignaturePolicyEnvelope{}
+		g.Expect(proto.Unmarshal(policy.Policy.Value, envelope)).To(Succeed())
+		return envelope
+	}
+
+	// without Idemix orgs the implicit meta policies are kept
+	application := protoutil.NewConfigGroup()
+	application.Groups["Org1MSP"] = x509Org()
+	application.Policies["Endorsement"] = implicitMeta(cb.ImplicitMetaPolicy_MAJORITY)
+	g.Expect(IdemixEndorsementPolicies(nil, application)).To(BeEmpty())
+	g.Expect(UpdateIdemixEndorsementPolicies(nil, application)).To(Succeed())
+	g.Expect(cb.Policy_PolicyType(application.Policies["Endorsement"].Policy.Type)).To(Equal(cb.Policy_IMPLICIT_META))
+
+	application.Groups["IdemixMSP"] = idemixOrg
+	application.Groups["Org2MSP"] = x509Org()
+	application.Groups["Org3MSP"] = x509Org()
+	application.Policies["LifecycleEndorsement"] = implicitMeta(cb.ImplicitMetaPolicy_ANY)
+	g.Expect(IdemixEndorsementPolicies(nil, application)).To(Equal([]string{"Endorsement", "LifecycleEndorsement"}))
+	g.Expect(UpdateIdemixEndorsementPolicies(nil, application)).To(Succeed())
+	endorsement := signaturePolicy(application.Policies["Endorsement"])
+	g.Expect(endorsement.Rule.GetNOutOf().N).To(BeEquivalentTo(2))
+	g.Expect(endorsement.Identities).To(HaveLen(3))
+	g.Expect(application.Policies["Endorsement"].ModPolicy).To(Equal("Admins"))
+	g.Expect(signaturePolicy(application.Policies["LifecycleEndorsement"]).Rule.GetNOutOf().N).To(BeEquivalentTo(1))
+
+	// the signature policies follow the orgs removed from the channel
+	previous := proto.Clone(application).(*cb.ConfigGroup)
+	delete(application.Groups, "Org3MSP")
+	g.Expect(IdemixEndorsementPolicies(previous, application)).To(Equal([]string{"Endorsement", "LifecycleEndorsement"}))
+	g.Expect(UpdateIdemixEndorsementPolicies(previous, application)).To(Succeed())
+	endorsement = signaturePolicy(application.Policies["Endorsement"])
+	g.Expect(endorsement.Rule.GetNOutOf().N).To(BeEquivalentTo(2))
+	g.Expect(endorsement.Identities).To(HaveLen(2))
+
+	// the policies set by the user are kept
+	custom := &cb.ConfigPolicy{Policy: &cb.Policy{Type: int32(cb.Policy_SIGNATURE), Value: []byte{}}}
+	application.Policies["Endorsement"] = custom
+	previous = proto.Clone(application).(*cb.ConfigGroup)
+	delete(application.Groups, "Org2MSP")
+	g.Expect(UpdateIdemixEndorsementPolicies(previous, application)).To(Succeed())
+	g.Expect(application.Policies["Endorsement"]).To(Equal(custom))
+
+	// an application group where only Idemix orgs are left can't endorse
+	previous = proto.Clone(application).(*cb.ConfigGroup)
+	delete(application.Groups, "Org1MSP")
+	g.Expect(UpdateIdemixEndorsementPolicies(previous, application)).To(MatchError(ContainSubstring("no organization of the channel can endorse")))
+}
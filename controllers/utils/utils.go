@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"net"
 	"strconv"
@@ -14,6 +15,7 @@ import (
 
 	v12 "k8s.io/api/core/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -58,6 +60,9 @@ func ParseK8sYaml(fileR []byte) []runtime.Object {
 
 func ParseECDSAPrivateKey(contents []byte) (*ecdsa.PrivateKey, error) {
 	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
 	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 	if err != nil {
 		return nil, err
@@ -70,6 +75,9 @@ func ParseECDSAPrivateKey(contents []byte) (*ecdsa.PrivateKey, error) {
 }
 func ParseX509Certificate(contents []byte) (*x509.Certificate, error) {
 	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
 	crt, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
 		return nil, err
@@ -98,7 +106,7 @@ func EncodePrivateKey(key interface{}) ([]byte, error) {
 	return pemPk, nil
 }
 
-func GetPublicIPKubernetes(clientSet *kubernetes.Clientset) (string, error) {
+func GetPublicIPKubernetes(clientSet kubernetes.Interface) (string, error) {
 	ctx := context.Background()
 	resp, err := clientSet.CoreV1().Nodes().List(ctx, v1.ListOptions{})
 	if err != nil {
@@ -197,3 +205,31 @@ func GetFreeNodeports(host string, n int) ([]int, error) {
 	}
 	return []int{}, errors.New("no ports are free")
 }
+
+// GetSecretKeyRef returns the value stored under the key referenced by ref in a Secret of the given namespace
+func GetSecretKeyRef(clientSet *kubernetes.Clientset, namespace string, ref *v12.SecretKeySelector) (string, error) {
+	ctx := context.Background()
+	secret, err := clientSet.CoreV1().Secrets(namespace).Get(ctx, ref.Name, v1.GetOptions{})
+	if err != nil {
+		if ref.Optional != nil && *ref.Optional && apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		if ref.Optional != nil && *ref.Optional {
+			return "", nil
+		}
+		return "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+	return string(value), nil
+}
+
+// ResolveSecretValue returns the value referenced by ref when it's set, otherwise the inline value
+func ResolveSecretValue(clientSet *kubernetes.Clientset, namespace string, value string, ref *v12.SecretKeySelector) (string, error) {
+	if ref == nil {
+		return value, nil
+	}
+	return GetSecretKeyRef(clientSet, namespace, ref)
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// operationsPortName is the port of the services of the peers and orderers with their operations endpoints
+const operationsPortName = "operations"
+
+// GetNodeVersion returns the Fabric version reported by the /version endpoint of the operations service of the peer
+// or orderer released as releaseName, reached through the proxy of the Kubernetes API
+func GetNodeVersion(ctx context.Context, clientSet kubernetes.Interface, ns string, releaseName string) (string, error) {
+	services, err := clientSet.CoreV1().Services(ns).List(ctx, v1.ListOptions{
+		LabelSelector: fmt.Sprintf("release=%s", releaseName),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, svc := range services.Items {
+		for _, port := range svc.Spec.Ports {
+			if port.Name != operationsPortName {
+				continue
+			}
+			body, err := clientSet.CoreV1().Services(ns).ProxyGet("http", svc.Name, port.Name, "/version", nil).DoRaw(ctx)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to get the version of %s", releaseName)
+			}
+			versionInfo := struct {
+				Version string `json:"Version"`
+			}{}
+			err = json.Unmarshal(body, &versionInfo)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid version of %s", releaseName)
+			}
+			if versionInfo.Version == "" {
+				return "", errors.Errorf("%s doesn't report its version", releaseName)
+			}
+			return versionInfo.Version, nil
+		}
+	}
+	return "", errors.Errorf("no operations service found for %s in namespace %s", releaseName, ns)
+}
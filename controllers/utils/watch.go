@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ListFunc lists the resources of a controller into the list, as client.Reader.List does
+type ListFunc func(ctx context.Context, list runtime.Object, opts ...client.ListOption) error
+
+// SecretReferrer is a resource whose spec references Secrets of its namespace
+type SecretReferrer interface {
+	v1.Object
+	SecretNames() []string
+}
+
+// CAReferrer is a resource enrolling against FabricCAs through caRef
+type CAReferrer interface {
+	v1.Object
+	ReferencesCA(caNamespace string, caName string) bool
+}
+
+// SecretReferrersMapper returns the map function of a Secret watch, it enqueues the resources in the namespace of the
+// Secret whose SecretNames contain it so that credential changes are applied
+func SecretReferrersMapper(kind string, list ListFunc, newList func() runtime.Object) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		return referrerRequests(kind, "secret", o, list, newList(), []client.ListOption{client.InNamespace(o.Meta.GetNamespace())}, func(item runtime.Object) bool {
+			referrer, ok := item.(SecretReferrer)
+			return ok && Contains(referrer.SecretNames(), o.Meta.GetName())
+		})
+	}
+}
+
+// CAReferrersMapper returns the map function of a FabricCA watch, it enqueues the resources enrolled against the
+// FabricCA through caRef so that changes of its endpoint or TLS certificate are applied
+func CAReferrersMapper(kind string, list ListFunc, newList func() runtime.Object) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		return referrerRequests(kind, "CA", o, list, newList(), nil, func(item runtime.Object) bool {
+			referrer, ok := item.(CAReferrer)
+			return ok && referrer.ReferencesCA(o.Meta.GetNamespace(), o.Meta.GetName())
+		})
+	}
+}
+
+func referrerRequests(
+	kind string,
+	referenced string,
+	o handler.MapObject,
+	list ListFunc,
+	objList runtime.Object,
+	opts []client.ListOption,
+	references func(item runtime.Object) bool,
+) []reconcile.Request {
+	err := list(context.Background(), objList, opts...)
+	if err != nil {
+		log.Errorf("Failed to list %s for %s %s: %v", kind, referenced, o.Meta.GetName(), err)
+		return nil
+	}
+	items, err := meta.ExtractList(objList)
+	if err != nil {
+		log.Errorf("Failed to list %s for %s %s: %v", kind, referenced, o.Meta.GetName(), err)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, item := range items {
+		if !references(item) {
+			continue
+		}
+		itemMeta, err := meta.Accessor(item)
+		if err != nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      itemMeta.GetName(),
+				Namespace: itemMeta.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
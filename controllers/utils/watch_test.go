@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReferrersMappers(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(hlfv1alpha1.AddToScheme(scheme)).To(Succeed())
+	newPeer := func(name string, ns string, couchDBSecret string, caRef *hlfv1alpha1.CARef) *hlfv1alpha1.FabricPeer {
+		peer := &hlfv1alpha1.FabricPeer{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: ns}}
+		if couchDBSecret != "" {
+			peer.Spec.CouchDB.PasswordRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: couchDBSecret},
+				Key:                  "password",
+			}
+		}
+		peer.Spec.Secret.Enrollment.Component.CARef = caRef
+		return peer
+	}
+	c := fake.NewFakeClientWithScheme(
+		scheme,
+		newPeer("org1-peer0", "default", "couchdb", &hlfv1alpha1.CARef{Name: "org1-ca"}),
+		newPeer("org1-peer1", "default", "other", &hlfv1alpha1.CARef{Name: "org1-ca", Namespace: "cas"}),
+		newPeer("org2-peer0", "org2", "couchdb", nil),
+	)
+	newList := func() runtime.Object {
+		return &hlfv1alpha1.FabricPeerList{}
+	}
+	mapObject := func(name string, ns string) handler.MapObject {
+		return handler.MapObject{Meta: &v1.ObjectMeta{Name: name, Namespace: ns}}
+	}
+	requestNames := func(requests []reconcile.Request) []string {
+		var names []string
+		for _, request := range requests {
+			names = append(names, request.String())
+		}
+		return names
+	}
+
+	// only the peers of the namespace of the Secret reference it
+	requests := SecretReferrersMapper("peers", c.List, newList)(mapObject("couchdb", "default"))
+	g.Expect(requestNames(requests)).To(ConsistOf("default/org1-peer0"))
+	requests = CAReferrersMapper("peers", c.List, newList)(mapObject("org1-ca", "cas"))
+	g.Expect(requestNames(requests)).To(ConsistOf("default/org1-peer1"))
+	g.Expect(CAReferrersMapper("peers", c.List, newList)(mapObject("org3-ca", "default"))).To(BeEmpty())
+}
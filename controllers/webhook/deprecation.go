@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const DeprecationPath = "/validate-hlf-kungfusoftware-es-v1alpha1-deprecations"
+
+// the admission types vendored with k8s.io/api don't carry warnings yet, so the review is decoded by hand
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID    types.UID               `json:"uid"`
+	Kind   metav1.GroupVersionKind `json:"kind"`
+	Object runtime.RawExtension    `json:"object"`
+}
+
+type admissionResponse struct {
+	UID      types.UID `json:"uid"`
+	Allowed  bool      `json:"allowed"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+type deprecatable interface {
+	DeprecatedFields() []hlfv1alpha1.DeprecatedField
+}
+
+// DeprecationHandler admits every request and warns about the inline credentials set in the resource
+type DeprecationHandler struct{}
+
+func (h *DeprecationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := &admissionReview{}
+	err := json.NewDecoder(r.Body).Decode(review)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review without request", http.StatusBadRequest)
+		return
+	}
+	warnings, err := getWarnings(review.Request)
+	if err != nil {
+		log.Warnf("Failed to check deprecated fields: %v", err)
+	}
+	review.Response = &admissionResponse{
+		UID:      review.Request.UID,
+		Allowed:  true,
+		Warnings: warnings,
+	}
+	review.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(review)
+	if err != nil {
+		log.Errorf("Failed to write admission response: %v", err)
+	}
+}
+
+func getWarnings(req *admissionRequest) ([]string, error) {
+	var obj deprecatable
+	switch req.Kind.Kind {
+	case "FabricPeer":
+		obj = &hlfv1alpha1.FabricPeer{}
+	case "FabricOrdererNode":
+		obj = &hlfv1alpha1.FabricOrdererNode{}
+	case "FabricOrderingService":
+		obj = &hlfv1alpha1.FabricOrderingService{}
+	case "FabricCA":
+		obj = &hlfv1alpha1.FabricCA{}
+	default:
+		return nil, nil
+	}
+	if len(req.Object.Raw) == 0 {
+		return nil, nil
+	}
+	err := json.Unmarshal(req.Object.Raw, obj)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, field := range obj.DeprecatedFields() {
+		warnings = append(warnings, field.String())
+	}
+	return warnings, nil
+}
@@ -0,0 +1,179 @@
+package ca
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newAffiliationCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "affiliation",
+		Short: "Manage the affiliations of a Fabric Certificate authority",
+	}
+	cmd.AddCommand(newAffiliationListCmd(out, errOut))
+	cmd.AddCommand(newAffiliationAddCmd(out, errOut))
+	cmd.AddCommand(newAffiliationRemoveCmd(out, errOut))
+	return cmd
+}
+
+// affiliationRows flattens the affiliation tree, the CA already returns the full name of every affiliation
+func affiliationRows(affiliation api.AffiliationInfo) [][]string {
+	var rows [][]string
+	if affiliation.Name != "" {
+		rows = append(rows, []string{affiliation.Name, strconv.Itoa(len(affiliation.Identities))})
+	}
+	for _, child := range affiliation.Affiliations {
+		rows = append(rows, affiliationRows(child)...)
+	}
+	return rows
+}
+
+type affiliationListCmd struct {
+	out    io.Writer
+	errOut io.Writer
+	opts   registrarOptions
+	output string
+}
+
+func (c *affiliationListCmd) validate() error {
+	if err := helpers.ValidateOutputFormat(c.output); err != nil {
+		return err
+	}
+	return c.opts.Validate()
+}
+
+func (c *affiliationListCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	affiliations, err := certs.GetAffiliations(params)
+	if err != nil {
+		return err
+	}
+	return helpers.PrintOutput(
+		c.out,
+		c.output,
+		affiliations,
+		[]string{"Affiliation", "Identities"},
+		affiliationRows(affiliations.AffiliationInfo),
+	)
+}
+
+func newAffiliationListCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := affiliationListCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the affiliations of a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	cmd.Flags().StringVarP(&c.output, "output", "o", helpers.OutputTable, "output format, table, json or yaml")
+	return cmd
+}
+
+type affiliationAddCmd struct {
+	out         io.Writer
+	errOut      io.Writer
+	opts        registrarOptions
+	affiliation string
+	force       bool
+}
+
+func (c *affiliationAddCmd) validate() error {
+	if c.affiliation == "" {
+		return errors.New("--affiliation is required")
+	}
+	return c.opts.Validate()
+}
+
+func (c *affiliationAddCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	affiliation, err := certs.AddAffiliation(params, c.affiliation, c.force)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Affiliation %s added\n", affiliation.Name)
+	return nil
+}
+
+func newAffiliationAddCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := affiliationAddCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add an affiliation to a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.affiliation, "affiliation", "", "full name of the affiliation, e.g org1.department1")
+	f.BoolVar(&c.force, "force", false, "create the parent affiliations that don't exist")
+	return cmd
+}
+
+type affiliationRemoveCmd struct {
+	out         io.Writer
+	errOut      io.Writer
+	opts        registrarOptions
+	affiliation string
+	force       bool
+}
+
+func (c *affiliationRemoveCmd) validate() error {
+	if c.affiliation == "" {
+		return errors.New("--affiliation is required")
+	}
+	return c.opts.Validate()
+}
+
+func (c *affiliationRemoveCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	affiliation, err := certs.RemoveAffiliation(params, c.affiliation, c.force)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Affiliation %s removed\n", affiliation.Name)
+	return nil
+}
+
+func newAffiliationRemoveCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := affiliationRemoveCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove an affiliation from a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.affiliation, "affiliation", "", "full name of the affiliation, e.g org1.department1")
+	f.BoolVar(&c.force, "force", false, "remove the child affiliations and identities as well")
+	return cmd
+}
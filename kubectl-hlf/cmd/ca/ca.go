@@ -13,5 +13,9 @@ func NewCACmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(newCADeleteCmd(out, errOut))
 	cmd.AddCommand(newCARegisterCmd(out, errOut))
 	cmd.AddCommand(newCAEnrollCmd(out, errOut))
+	cmd.AddCommand(newIdentityCmd(out, errOut))
+	cmd.AddCommand(newAffiliationCmd(out, errOut))
+	cmd.AddCommand(newCARevokeCmd(out, errOut))
+	cmd.AddCommand(newCAGenCRLCmd(out, errOut))
 	return cmd
 }
@@ -24,9 +24,16 @@ type Options struct {
 	EnrollID     string
 	EnrollSecret string
 	Output       bool
+	Replicas     int
+	DBType       string
+	DBSecretName string
+	DBSecretKey  string
 }
 
 func (o Options) Validate() error {
+	if o.Replicas > 1 && (o.DBType == "sqlite3" || o.DBSecretName == "") {
+		return fmt.Errorf("--replicas greater than 1 requires a postgres or mysql --db-type and --db-secret-name")
+	}
 	return nil
 }
 
@@ -62,6 +69,19 @@ func (c *createCmd) run(args []string) error {
 			},
 		},
 	}
+	database := v1alpha1.FabricCADatabase{
+		Type:       "sqlite3",
+		Datasource: "fabric-ca-server.db",
+	}
+	if c.caOpts.DBSecretName != "" {
+		database = v1alpha1.FabricCADatabase{
+			Type: c.caOpts.DBType,
+			DatasourceRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: c.caOpts.DBSecretName},
+				Key:                  c.caOpts.DBSecretKey,
+			},
+		}
+	}
 	fabricCA := &v1alpha1.FabricCA{
 		TypeMeta: v1.TypeMeta{
 			Kind:       "FabricCA",
@@ -72,10 +92,8 @@ func (c *createCmd) run(args []string) error {
 			Namespace: c.caOpts.NS,
 		},
 		Spec: v1alpha1.FabricCASpec{
-			Database: v1alpha1.FabricCADatabase{
-				Type:       "sqlite3",
-				Datasource: "fabric-ca-server.db",
-			},
+			Database: database,
+			Replicas: c.caOpts.Replicas,
 			Hosts: []string{
 				"localhost",
 				c.caOpts.Name,
@@ -268,6 +286,10 @@ func newCreateCACmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	f.StringVarP(&c.caOpts.EnrollID, "enroll-id", "", "enroll", "username to register new users")
 	f.StringVarP(&c.caOpts.EnrollSecret, "enroll-pw", "", "enrollpw", "password to register new users")
 	f.BoolVarP(&c.caOpts.Output, "output", "o", false, "output in yaml")
+	f.IntVar(&c.caOpts.Replicas, "replicas", 1, "number of pods of the Certificate Authority")
+	f.StringVar(&c.caOpts.DBType, "db-type", "sqlite3", "type of the database, sqlite3, postgres or mysql")
+	f.StringVar(&c.caOpts.DBSecretName, "db-secret-name", "", "secret holding the datasource of a postgres or mysql database")
+	f.StringVar(&c.caOpts.DBSecretKey, "db-secret-key", "datasource", "key of the datasource in the secret")
 
 	return cmd
 }
@@ -0,0 +1,163 @@
+package ca
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	cacontroller "github.com/kfsoftware/hlf-operator/controllers/ca"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CRLConfigMapKey is the key of the ConfigMaps the CRLs are written to
+const CRLConfigMapKey = "crl.pem"
+
+// crlOutputOptions are the destinations of a CRL, it's printed when none is set
+type crlOutputOptions struct {
+	File      string
+	ConfigMap string
+}
+
+func (o *crlOutputOptions) addFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&o.File, "output", "", "file to write the PEM encoded CRL to")
+	f.StringVar(&o.ConfigMap, "crl-configmap", "", "ConfigMap in the namespace of the CA to write the CRL to, under the "+CRLConfigMapKey+" key, labeled with the CA so its CRL is propagated to the channels of the organization")
+}
+
+func (o crlOutputOptions) write(out io.Writer, ns string, caName string, crl []byte) error {
+	if o.File != "" {
+		err := ioutil.WriteFile(o.File, crl, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	if o.ConfigMap != "" {
+		err := writeCRLConfigMap(ns, o.ConfigMap, caName, crl)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "CRL written to ConfigMap %s/%s\n", ns, o.ConfigMap)
+	}
+	if o.File == "" && o.ConfigMap == "" {
+		_, err := out.Write(crl)
+		return err
+	}
+	return nil
+}
+
+func writeCRLConfigMap(ns string, name string, caName string, crl []byte) error {
+	client, err := helpers.GetKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	configMap, err := client.CoreV1().ConfigMaps(ns).Get(ctx, name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.CoreV1().ConfigMaps(ns).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Labels: map[string]string{
+					cacontroller.CRLConfigMapLabel: caName,
+				},
+			},
+			Data: map[string]string{
+				CRLConfigMapKey: string(crl),
+			},
+		}, v1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[CRLConfigMapKey] = string(crl)
+	if configMap.Labels == nil {
+		configMap.Labels = map[string]string{}
+	}
+	configMap.Labels[cacontroller.CRLConfigMapLabel] = caName
+	_, err = client.CoreV1().ConfigMaps(ns).Update(ctx, configMap, v1.UpdateOptions{})
+	return err
+}
+
+type genCRLCmd struct {
+	out           io.Writer
+	errOut        io.Writer
+	opts          registrarOptions
+	crlOpts       crlOutputOptions
+	revokedAfter  string
+	revokedBefore string
+	expireAfter   string
+	expireBefore  string
+}
+
+func (c *genCRLCmd) validate() error {
+	return c.opts.Validate()
+}
+
+func parseTimeFlag(name string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %s, expected a RFC3339 timestamp", name, value)
+	}
+	return t, nil
+}
+
+func (c *genCRLCmd) run() error {
+	var request certs.GenCRLRequest
+	var err error
+	if request.RevokedAfter, err = parseTimeFlag("revoked-after", c.revokedAfter); err != nil {
+		return err
+	}
+	if request.RevokedBefore, err = parseTimeFlag("revoked-before", c.revokedBefore); err != nil {
+		return err
+	}
+	if request.ExpireAfter, err = parseTimeFlag("expire-after", c.expireAfter); err != nil {
+		return err
+	}
+	if request.ExpireBefore, err = parseTimeFlag("expire-before", c.expireBefore); err != nil {
+		return err
+	}
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	crl, err := certs.GenCRL(params, request)
+	if err != nil {
+		return err
+	}
+	return c.crlOpts.write(c.out, c.opts.NS, c.opts.Name, crl)
+}
+
+func newCAGenCRLCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := genCRLCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "gencrl",
+		Short: "Generate the CRL of a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	c.crlOpts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.revokedAfter, "revoked-after", "", "only include the certificates revoked after this RFC3339 timestamp")
+	f.StringVar(&c.revokedBefore, "revoked-before", "", "only include the certificates revoked before this RFC3339 timestamp")
+	f.StringVar(&c.expireAfter, "expire-after", "", "only include the certificates expiring after this RFC3339 timestamp")
+	f.StringVar(&c.expireBefore, "expire-before", "", "only include the certificates expiring before this RFC3339 timestamp")
+	return cmd
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
 	"github.com/kfsoftware/hlf-operator/controllers/certs"
 	"github.com/kfsoftware/hlf-operator/controllers/utils"
 	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
@@ -23,12 +26,18 @@ type EnrollOptions struct {
 	Profile string
 	Hosts   []string
 	CN      string
+	// ECDSA_P256 or ECDSA_P384
+	KeyAlgorithm string
 }
 
 func (o EnrollOptions) Validate() error {
-	return nil
+	_, err := certs.GetKeyAlgorithm(o.KeyAlgorithm)
+	return err
 }
 
+// enrollTypeIdemix requests an Idemix credential instead of an X.509 certificate
+const enrollTypeIdemix = "idemix"
+
 type enrollCmd struct {
 	out        io.Writer
 	errOut     io.Writer
@@ -57,17 +66,21 @@ func (c *enrollCmd) run(args []string) error {
 		return err
 	}
 	url := fmt.Sprintf("https://%s:%d", ip, certAuth.Status.NodePort)
+	if c.enrollOpts.Type == enrollTypeIdemix {
+		return c.enrollIdemix(certAuth.Status.TlsCert, url)
+	}
 	crt, pk, _, err := certs.EnrollUser(certs.EnrollUserRequest{
-		TLSCert:    certAuth.Status.TlsCert,
-		URL:        url,
-		Name:       c.enrollOpts.CAName,
-		MSPID:      c.enrollOpts.MspID,
-		User:       c.enrollOpts.User,
-		Secret:     c.enrollOpts.Secret,
-		Hosts:      c.enrollOpts.Hosts,
-		CN:         c.enrollOpts.CN,
-		Profile:    c.enrollOpts.Profile,
-		Attributes: nil,
+		TLSCert:      certAuth.Status.TlsCert,
+		URL:          url,
+		Name:         c.enrollOpts.CAName,
+		MSPID:        c.enrollOpts.MspID,
+		User:         c.enrollOpts.User,
+		Secret:       c.enrollOpts.Secret,
+		Hosts:        c.enrollOpts.Hosts,
+		CN:           c.enrollOpts.CN,
+		Profile:      c.enrollOpts.Profile,
+		KeyAlgorithm: c.enrollOpts.KeyAlgorithm,
+		Attributes:   nil,
 	})
 	if err != nil {
 		return err
@@ -95,6 +108,43 @@ func (c *enrollCmd) run(args []string) error {
 
 	return nil
 }
+
+// enrollIdemix writes the credential in the layout of an Idemix MSP folder
+func (c *enrollCmd) enrollIdemix(tlsCert string, url string) error {
+	credential, err := certs.EnrollIdemix(certs.EnrollIdemixRequest{
+		TLSCert: tlsCert,
+		URL:     url,
+		Name:    c.enrollOpts.CAName,
+		MSPID:   c.enrollOpts.MspID,
+		User:    c.enrollOpts.User,
+		Secret:  c.enrollOpts.Secret,
+	})
+	if err != nil {
+		return err
+	}
+	signerConfig, err := proto.Marshal(credential.SignerConfig)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		filepath.Join("msp", "IssuerPublicKey"):     credential.IssuerPublicKey,
+		filepath.Join("msp", "RevocationPublicKey"): credential.RevocationPublicKey,
+		filepath.Join("user", "SignerConfig"):       signerConfig,
+	}
+	for name, content := range files {
+		path := filepath.Join(c.fileOutput, name)
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path, content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func newCAEnrollCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	c := enrollCmd{out: out, errOut: errOut}
 	cmd := &cobra.Command{
@@ -113,13 +163,14 @@ func newCAEnrollCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	f.StringVarP(&c.enrollOpts.CAName, "ca-name", "", "", "ca name to enroll this user")
 	f.StringVarP(&c.enrollOpts.User, "user", "", "", "namespace scope for this request")
 	f.StringVarP(&c.enrollOpts.Secret, "secret", "", "", "namespace scope for this request")
-	f.StringVarP(&c.enrollOpts.Type, "type", "", "", "namespace scope for this request")
+	f.StringVarP(&c.enrollOpts.Type, "type", "", "", "type of the enrollment, idemix for an Idemix credential written to the --output folder")
 	f.StringVarP(&c.enrollOpts.MspID, "mspid", "", "", "namespace scope for this request")
 	f.StringVarP(&c.enrollOpts.Profile, "profile", "", "", "profile")
 	f.StringVarP(&c.enrollOpts.CN, "cn", "", "", "cn")
+	f.StringVar(&c.enrollOpts.KeyAlgorithm, "key-algorithm", "ECDSA_P256", "algorithm of the private key, ECDSA_P256 or ECDSA_P384")
 	f.StringSliceVarP(&c.enrollOpts.Hosts, "hosts", "", []string{}, "hosts")
 
-	f.StringVar(&c.fileOutput, "output", "", "output file")
+	f.StringVar(&c.fileOutput, "output", "", "output file, or the Idemix MSP folder with --type idemix")
 
 	return cmd
 }
@@ -0,0 +1,249 @@
+package ca
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var identityHeader = []string{"ID", "Type", "Affiliation", "Max enrollments", "Attributes"}
+
+func identityRow(identity *api.IdentityResponse) []string {
+	return []string{
+		identity.ID,
+		identity.Type,
+		identity.Affiliation,
+		formatMaxEnrollments(identity.MaxEnrollments),
+		formatAttributes(identity.Attributes),
+	}
+}
+
+func newIdentityCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "identity",
+		Short: "Manage the identities of a Fabric Certificate authority",
+	}
+	cmd.AddCommand(newIdentityListCmd(out, errOut))
+	cmd.AddCommand(newIdentityGetCmd(out, errOut))
+	cmd.AddCommand(newIdentityModifyCmd(out, errOut))
+	cmd.AddCommand(newIdentityRemoveCmd(out, errOut))
+	return cmd
+}
+
+type identityListCmd struct {
+	out    io.Writer
+	errOut io.Writer
+	opts   registrarOptions
+	output string
+}
+
+func (c *identityListCmd) validate() error {
+	if err := helpers.ValidateOutputFormat(c.output); err != nil {
+		return err
+	}
+	return c.opts.Validate()
+}
+
+func (c *identityListCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	identities, err := certs.GetIdentities(params)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, identity := range identities {
+		rows = append(rows, identityRow(identity))
+	}
+	return helpers.PrintOutput(c.out, c.output, identities, identityHeader, rows)
+}
+
+func newIdentityListCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := identityListCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the identities of a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	cmd.Flags().StringVarP(&c.output, "output", "o", helpers.OutputTable, "output format, table, json or yaml")
+	return cmd
+}
+
+type identityGetCmd struct {
+	out    io.Writer
+	errOut io.Writer
+	opts   registrarOptions
+	id     string
+	output string
+}
+
+func (c *identityGetCmd) validate() error {
+	if c.id == "" {
+		return errors.New("--id is required")
+	}
+	if err := helpers.ValidateOutputFormat(c.output); err != nil {
+		return err
+	}
+	return c.opts.Validate()
+}
+
+func (c *identityGetCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	identity, err := certs.GetIdentity(params, c.id)
+	if err != nil {
+		return err
+	}
+	return helpers.PrintOutput(c.out, c.output, identity, identityHeader, [][]string{identityRow(identity)})
+}
+
+func newIdentityGetCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := identityGetCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get an identity of a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.id, "id", "", "ID of the identity")
+	f.StringVarP(&c.output, "output", "o", helpers.OutputTable, "output format, table, json or yaml")
+	return cmd
+}
+
+type identityModifyCmd struct {
+	out            io.Writer
+	errOut         io.Writer
+	opts           registrarOptions
+	id             string
+	identityType   string
+	affiliation    string
+	attributes     []string
+	maxEnrollments int
+	secret         string
+}
+
+func (c *identityModifyCmd) validate() error {
+	if c.id == "" {
+		return errors.New("--id is required")
+	}
+	return c.opts.Validate()
+}
+
+func (c *identityModifyCmd) run(cmd *cobra.Command) error {
+	attributes, err := parseAttributes(c.attributes)
+	if err != nil {
+		return err
+	}
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	request := api.IdentityRequest{
+		ID:          c.id,
+		Type:        c.identityType,
+		Affiliation: c.affiliation,
+		Attributes:  attributes,
+		Secret:      c.secret,
+	}
+	// the CA keeps the current value when the max enrollments are zero
+	if cmd.Flags().Changed("max-enrollments") {
+		request.MaxEnrollments = c.maxEnrollments
+	}
+	identity, err := certs.ModifyIdentity(params, request)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Identity %s modified\n", identity.ID)
+	return nil
+}
+
+func newIdentityModifyCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := identityModifyCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "modify",
+		Short: "Modify an identity of a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(cmd)
+		},
+	}
+	c.opts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.id, "id", "", "ID of the identity")
+	f.StringVar(&c.identityType, "type", "", "new type of the identity, e.g client, peer, orderer or admin")
+	f.StringVar(&c.affiliation, "affiliation", "", "new affiliation of the identity")
+	f.StringSliceVar(&c.attributes, "attributes", []string{}, "attributes to add or update in the name=value[:ecert] format")
+	f.IntVar(&c.maxEnrollments, "max-enrollments", 0, "new maximum number of enrollments, -1 for unlimited")
+	f.StringVar(&c.secret, "secret", "", "new enrollment secret of the identity")
+	return cmd
+}
+
+type identityRemoveCmd struct {
+	out    io.Writer
+	errOut io.Writer
+	opts   registrarOptions
+	id     string
+	force  bool
+}
+
+func (c *identityRemoveCmd) validate() error {
+	if c.id == "" {
+		return errors.New("--id is required")
+	}
+	return c.opts.Validate()
+}
+
+func (c *identityRemoveCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	identity, err := certs.RemoveIdentity(params, c.id, c.force)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Identity %s removed\n", identity.ID)
+	return nil
+}
+
+func newIdentityRemoveCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := identityRemoveCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove an identity of a Fabric Certificate authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.id, "id", "", "ID of the identity")
+	f.BoolVar(&c.force, "force", false, "remove the identity even if it's the registrar itself")
+	return cmd
+}
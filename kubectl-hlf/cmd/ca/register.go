@@ -11,14 +11,18 @@ import (
 )
 
 type RegisterOptions struct {
-	Name         string
-	NS           string
-	User         string
-	Secret       string
-	Type         string
-	MspID        string
-	EnrollID     string
-	EnrollSecret string
+	Name           string
+	NS             string
+	User           string
+	Secret         string
+	Type           string
+	MspID          string
+	EnrollID       string
+	EnrollSecret   string
+	CAName         string
+	Attributes     []string
+	Affiliation    string
+	MaxEnrollments int
 }
 
 func (o RegisterOptions) Validate() error {
@@ -34,7 +38,7 @@ type registerCmd struct {
 func (c *registerCmd) validate() error {
 	return c.caOpts.Validate()
 }
-func (c *registerCmd) run(args []string) error {
+func (c *registerCmd) run(cmd *cobra.Command) error {
 	oclient, err := helpers.GetKubeOperatorClient()
 	if err != nil {
 		return err
@@ -51,19 +55,29 @@ func (c *registerCmd) run(args []string) error {
 	if err != nil {
 		return err
 	}
+	attributes, err := parseAttributes(c.caOpts.Attributes)
+	if err != nil {
+		return err
+	}
 	url := fmt.Sprintf("https://%s:%d", ip, certAuth.Status.NodePort)
-	_, err = certs.RegisterUser(certs.RegisterUserRequest{
+	request := certs.RegisterUserRequest{
 		TLSCert:      certAuth.Status.TlsCert,
 		URL:          url,
-		Name:         "",
+		Name:         c.caOpts.CAName,
 		MSPID:        c.caOpts.MspID,
 		EnrollID:     c.caOpts.EnrollID,
 		EnrollSecret: c.caOpts.EnrollSecret,
 		User:         c.caOpts.User,
 		Secret:       c.caOpts.Secret,
 		Type:         c.caOpts.Type,
-		Attributes:   nil,
-	})
+		Attributes:   attributes,
+		Affiliation:  c.caOpts.Affiliation,
+	}
+	// the identity can enroll without limit unless the max enrollments are set, zero takes the default of the CA
+	if cmd.Flags().Changed("max-enrollments") {
+		request.MaxEnrollments = &c.caOpts.MaxEnrollments
+	}
+	_, err = certs.RegisterUser(request)
 	if err != nil {
 		return err
 	}
@@ -78,7 +92,7 @@ func newCARegisterCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 			if err := c.validate(); err != nil {
 				return err
 			}
-			return c.run(args)
+			return c.run(cmd)
 		},
 	}
 	f := cmd.Flags()
@@ -90,6 +104,10 @@ func newCARegisterCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	f.StringVarP(&c.caOpts.Secret, "secret", "", "", "namespace scope for this request")
 	f.StringVarP(&c.caOpts.Type, "type", "", "", "namespace scope for this request")
 	f.StringVarP(&c.caOpts.MspID, "mspid", "", "", "namespace scope for this request")
+	f.StringVar(&c.caOpts.CAName, "ca-name", "", "name of the CA in the server, ca or tlsca")
+	f.StringSliceVar(&c.caOpts.Attributes, "attributes", []string{}, "attributes of the identity in the name=value[:ecert] format")
+	f.StringVar(&c.caOpts.Affiliation, "affiliation", "", "affiliation of the identity, e.g org1.department1")
+	f.IntVar(&c.caOpts.MaxEnrollments, "max-enrollments", -1, "maximum number of enrollments of the identity, -1 for unlimited and 0 for the default of the CA")
 
 	return cmd
 }
@@ -0,0 +1,108 @@
+package ca
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// registrarOptions are the flags to reach a CA of the cluster with an identity allowed to manage it
+type registrarOptions struct {
+	Name         string
+	NS           string
+	CAName       string
+	MspID        string
+	EnrollID     string
+	EnrollSecret string
+}
+
+func (o registrarOptions) Validate() error {
+	if o.Name == "" {
+		return errors.New("--name is required")
+	}
+	if o.EnrollID == "" || o.EnrollSecret == "" {
+		return errors.New("--enroll-id and --enroll-secret are required")
+	}
+	return nil
+}
+
+func (o *registrarOptions) addFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&o.Name, "name", "", "name of the Certificate Authority in the cluster, e.g ca.default")
+	f.StringVarP(&o.NS, "namespace", "n", helpers.DefaultNamespace, "namespace scope for this request")
+	f.StringVar(&o.CAName, "ca-name", "ca", "name of the CA in the server, ca or tlsca")
+	f.StringVar(&o.MspID, "mspid", "Org1MSP", "MSP ID of the registrar")
+	f.StringVar(&o.EnrollID, "enroll-id", "", "registrar enrollment ID")
+	f.StringVar(&o.EnrollSecret, "enroll-secret", "", "registrar enrollment secret")
+}
+
+func (o registrarOptions) getParams() (certs.RegistrarParams, error) {
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return certs.RegistrarParams{}, err
+	}
+	certAuth, err := helpers.GetCertAuthByName(oclient, o.Name, o.NS)
+	if err != nil {
+		return certs.RegistrarParams{}, err
+	}
+	client, err := helpers.GetKubeClient()
+	if err != nil {
+		return certs.RegistrarParams{}, err
+	}
+	ip, err := utils.GetPublicIPKubernetes(client)
+	if err != nil {
+		return certs.RegistrarParams{}, err
+	}
+	return certs.RegistrarParams{
+		TLSCert:      certAuth.Status.TlsCert,
+		URL:          fmt.Sprintf("https://%s:%d", ip, certAuth.Status.NodePort),
+		Name:         o.CAName,
+		MSPID:        o.MspID,
+		EnrollID:     o.EnrollID,
+		EnrollSecret: o.EnrollSecret,
+	}, nil
+}
+
+// parseAttributes parses attributes in the name=value[:ecert] format of fabric-ca-client
+func parseAttributes(values []string) ([]api.Attribute, error) {
+	var attributes []api.Attribute
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid attribute %s, expected name=value[:ecert]", value)
+		}
+		attribute := api.Attribute{Name: parts[0], Value: parts[1]}
+		if strings.HasSuffix(attribute.Value, ":ecert") {
+			attribute.Value = strings.TrimSuffix(attribute.Value, ":ecert")
+			attribute.ECert = true
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, nil
+}
+
+func formatAttributes(attributes []api.Attribute) string {
+	var values []string
+	for _, attribute := range attributes {
+		value := fmt.Sprintf("%s=%s", attribute.Name, attribute.Value)
+		if attribute.ECert {
+			value += ":ecert"
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, ",")
+}
+
+func formatMaxEnrollments(maxEnrollments int) string {
+	if maxEnrollments < 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(maxEnrollments)
+}
@@ -0,0 +1,68 @@
+package ca
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type revokeCmd struct {
+	out     io.Writer
+	errOut  io.Writer
+	opts    registrarOptions
+	crlOpts crlOutputOptions
+	request certs.RevokeRequest
+}
+
+func (c *revokeCmd) validate() error {
+	if c.request.ID == "" && (c.request.Serial == "" || c.request.AKI == "") {
+		return errors.New("--id or --serial and --aki are required")
+	}
+	if err := certs.ValidateRevocationReason(c.request.Reason); err != nil {
+		return err
+	}
+	return c.opts.Validate()
+}
+
+func (c *revokeCmd) run() error {
+	params, err := c.opts.getParams()
+	if err != nil {
+		return err
+	}
+	response, err := certs.Revoke(params, c.request)
+	if err != nil {
+		return err
+	}
+	for _, cert := range response.RevokedCerts {
+		fmt.Fprintf(c.out, "Revoked certificate serial=%s aki=%s\n", cert.Serial, cert.AKI)
+	}
+	if c.crlOpts.File == "" && c.crlOpts.ConfigMap == "" {
+		return nil
+	}
+	return c.crlOpts.write(c.out, c.opts.NS, c.opts.Name, response.CRL)
+}
+
+func newCARevokeCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := revokeCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke the certificates of an identity or a single certificate",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	c.opts.addFlags(cmd)
+	c.crlOpts.addFlags(cmd)
+	f := cmd.Flags()
+	f.StringVar(&c.request.ID, "id", "", "enrollment ID of the identity whose certificates are revoked")
+	f.StringVar(&c.request.Serial, "serial", "", "serial number of the certificate to revoke, in hex")
+	f.StringVar(&c.request.AKI, "aki", "", "authority key identifier of the certificate to revoke, in hex")
+	f.StringVar(&c.request.Reason, "reason", "", "reason of the revocation, e.g keycompromise or superseded")
+	return cmd
+}
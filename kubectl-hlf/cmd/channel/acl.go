@@ -0,0 +1,142 @@
+package channel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type setACLCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	resource    string
+	policyRef   string
+	output      string
+	dryRun      bool
+}
+
+func (c *setACLCmd) validate() error {
+	if c.dryRun && c.output != "" {
+		return errors.Errorf("--dry-run and --output can't be used together")
+	}
+	return nil
+}
+
+func (c *setACLCmd) run(out io.Writer) error {
+	sdk, resClient, err := newAdminResClient(c.configPath, c.peer, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	channelID := c.channelName
+	channelConfig, err := GetCurrentConfigFromPeer(resClient, channelID)
+	if err != nil {
+		return err
+	}
+	modifiedConfig := proto.Clone(channelConfig).(*common.Config)
+	application, ok := modifiedConfig.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+	if !ok {
+		return errors.Errorf("channel %s doesn't have an application section", channelID)
+	}
+	acls := &peer.ACLs{}
+	aclsValue, ok := application.Values[channelconfig.ACLsKey]
+	if ok {
+		err = proto.Unmarshal(aclsValue.Value, acls)
+		if err != nil {
+			return err
+		}
+	} else {
+		aclsValue = &common.ConfigValue{ModPolicy: channelconfig.AdminsPolicyKey}
+		application.Values[channelconfig.ACLsKey] = aclsValue
+	}
+	if acls.Acls == nil {
+		acls.Acls = map[string]*peer.APIResource{}
+	}
+	policyRef := c.policyRef
+	if !strings.HasPrefix(policyRef, "/") {
+		// relative references are resolved against the application group by the peer
+		policyRef = fmt.Sprintf("/Channel/%s/%s", channelconfig.ApplicationGroupKey, policyRef)
+	}
+	if !policyExists(modifiedConfig, policyRef) {
+		return errors.Errorf("policy %s not found in channel %s", policyRef, channelID)
+	}
+	before := "<default>"
+	if apiResource, ok := acls.Acls[c.resource]; ok {
+		before = apiResource.PolicyRef
+	}
+	_, err = fmt.Fprintf(out, "Before: %s -> %s\nAfter: %s -> %s\n", c.resource, before, c.resource, policyRef)
+	if err != nil {
+		return err
+	}
+	acls.Acls[c.resource] = &peer.APIResource{PolicyRef: policyRef}
+	aclsValue.Value, err = proto.Marshal(acls)
+	if err != nil {
+		return err
+	}
+	return applyConfigUpdate(out, resClient, channelID, channelConfig, modifiedConfig, c.dryRun, c.output)
+}
+
+// policyExists returns true when the policy of the path is in the config
+func policyExists(channelConfig *common.Config, path string) bool {
+	groupPath, policyName, err := splitPolicyPath(path)
+	if err != nil {
+		return false
+	}
+	group := channelConfig.ChannelGroup
+	for _, groupName := range groupPath {
+		var ok bool
+		group, ok = group.Groups[groupName]
+		if !ok {
+			return false
+		}
+	}
+	_, ok := group.Policies[policyName]
+	return ok
+}
+
+func newACLCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "acl",
+		Short: "Manage the resource ACLs of a channel",
+	}
+	cmd.AddCommand(newSetACLCMD(out, errOut))
+	return cmd
+}
+
+func newSetACLCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &setACLCmd{}
+	cmd := &cobra.Command{
+		Use:   "set <resource> <policy>",
+		Short: "Set the policy of a resource, e.g. set cscc/GetConfigBlock /Channel/Application/Readers",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.resource = args[0]
+			c.policyRef = args[1]
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "", "", "Admin org to invoke the updates")
+	persistentFlags.StringVarP(&c.channelName, "channel", "", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
+	persistentFlags.StringVarP(&c.output, "output", "o", "", "Save the config update envelope in this file instead of submitting it")
+	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Print the diff of the config and not update")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	return cmd
+}
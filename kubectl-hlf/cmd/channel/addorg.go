@@ -5,17 +5,21 @@ import (
 	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
 	"io"
 	"io/ioutil"
+	"path/filepath"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-config/protolator/protoext/peerext"
 	"github.com/hyperledger/fabric-protos-go/common"
 	cb "github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
 	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/encoder"
 	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/genesisconfig"
 	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	operatorv1 "github.com/kfsoftware/hlf-operator/pkg/client/clientset/versioned"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -23,16 +27,28 @@ import (
 )
 
 type addOrgCmd struct {
-	configPath  string
-	orgPath     string
-	peer        string
-	channelName string
-	userName    string
-	mspID       string
-	dryRun      bool
+	configPath        string
+	orgPath           string
+	peer              string
+	channelName       string
+	userName          string
+	mspID             string
+	idemixCA          string
+	caName            string
+	dryRun            bool
+	signaturePolicies bool
 }
 
 func (c *addOrgCmd) validate() error {
+	sources := 0
+	for _, source := range []string{c.orgPath, c.idemixCA, c.caName} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.Errorf("only one of --org-config, --idemix-ca and --ca-name can be used")
+	}
 	return nil
 }
 func (c *addOrgCmd) run(out io.Writer) error {
@@ -73,29 +89,33 @@ func (c *addOrgCmd) run(out io.Writer) error {
 	if err != nil {
 		return err
 	}
-	orgBytes, err := ioutil.ReadFile(c.orgPath)
-	if err != nil {
-		return err
-	}
-	topLevel := &genesisconfig.TopLevel{}
-	err = yaml.Unmarshal(orgBytes, topLevel)
-	if err != nil {
-		return err
-	}
 	var orgConfig *cb.ConfigGroup
-	for _, org := range topLevel.Organizations {
-		if org.Name == c.mspID {
-			orgConfig, err = encoder.NewOrdererOrgGroup(org)
-			if err != nil {
-				return err
-			}
-
+	if c.idemixCA != "" {
+		ipk, revocationPk, err := getIdemixIssuerKeys(oclient, c.idemixCA)
+		if err != nil {
+			return err
+		}
+		orgConfig, err = testutils.NewIdemixOrgGroup(c.mspID, ipk, revocationPk)
+		if err != nil {
+			return err
+		}
+	} else if c.orgPath != "" {
+		orgConfig, err = c.getOrgConfig()
+		if err != nil {
+			return err
+		}
+	} else {
+		orgConfig, err = c.getClusterOrgConfig(oclient)
+		if err != nil {
+			return err
 		}
 	}
-	if orgConfig == nil {
-		return errors.Errorf("msp ID %s not found", c.mspID)
+	application := modifiedConfig.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+	application.Groups[c.mspID] = orgConfig
+	err = updateIdemixEndorsementPolicies(channelConfig.ChannelGroup.Groups[channelconfig.ApplicationGroupKey], application, c.signaturePolicies)
+	if err != nil {
+		return err
 	}
-	modifiedConfig.ChannelGroup.Groups[channelconfig.ApplicationGroupKey].Groups[c.mspID] = orgConfig
 	confUpdate, err := resmgmt.CalculateConfigUpdate(channelID, channelConfig, modifiedConfig)
 	if err != nil {
 		return err
@@ -122,6 +142,59 @@ func (c *addOrgCmd) run(out io.Writer) error {
 	}
 	return nil
 }
+func (c *addOrgCmd) getOrgConfig() (*cb.ConfigGroup, error) {
+	orgBytes, err := ioutil.ReadFile(c.orgPath)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(c.orgPath) == ".json" {
+		// configtxlator JSON of the org group, as written by kubectl hlf org export --format json
+		orgGroup := &cb.ConfigGroup{}
+		err = protolator.DeepUnmarshalJSON(bytes.NewReader(orgBytes), &peerext.DynamicApplicationOrgGroup{ConfigGroup: orgGroup})
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid org group in %s", c.orgPath)
+		}
+		return orgGroup, nil
+	}
+	topLevel := &genesisconfig.TopLevel{}
+	err = yaml.Unmarshal(orgBytes, topLevel)
+	if err != nil {
+		return nil, err
+	}
+	for _, org := range topLevel.Organizations {
+		if org.Name == c.mspID {
+			// as configtxgen, the MSP folder is relative to the configtx file
+			if !filepath.IsAbs(org.MSPDir) {
+				org.MSPDir = filepath.Join(filepath.Dir(c.orgPath), org.MSPDir)
+			}
+			if org.MSPType == "" {
+				org.MSPType = "bccsp"
+			}
+			defaultPolicies := testutils.DefaultPeerOrgPolicies(org.ID)
+			if org.Policies == nil {
+				org.Policies = map[string]*genesisconfig.Policy{}
+			}
+			for name, policy := range defaultPolicies {
+				if _, ok := org.Policies[name]; !ok {
+					org.Policies[name] = policy
+				}
+			}
+			return encoder.NewApplicationOrgGroup(org)
+		}
+	}
+	return nil, errors.Errorf("msp ID %s not found", c.mspID)
+}
+
+// getClusterOrgConfig builds the org from the FabricCA and the FabricPeers of the MSP ID in the cluster, the peers
+// are added as anchor peers
+func (c *addOrgCmd) getClusterOrgConfig(oclient *operatorv1.Clientset) (*cb.ConfigGroup, error) {
+	peerOrg, err := helpers.GetClusterPeerOrganization(oclient, c.mspID, c.caName)
+	if err != nil {
+		return nil, errors.Wrap(err, "use --ca-name or --org-config")
+	}
+	return testutils.NewApplicationOrgGroup(*peerOrg)
+}
+
 func newAddOrgToChannelCMD(out io.Writer, errOut io.Writer) *cobra.Command {
 	c := &addOrgCmd{}
 	cmd := &cobra.Command{
@@ -139,12 +212,14 @@ func newAddOrgToChannelCMD(out io.Writer, errOut io.Writer) *cobra.Command {
 	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
 	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
 	persistentFlags.StringVarP(&c.mspID, "msp-id", "", "", "MSP ID for the new organization")
-	persistentFlags.StringVarP(&c.orgPath, "org-config", "", "", "JSON with the crypto material for the new org")
+	persistentFlags.StringVarP(&c.orgPath, "org-config", "", "", "configtx YAML or configtxlator JSON (.json) with the new org, as written by kubectl hlf org export, the org is built from the CA and peers of the MSP ID in the cluster when empty")
+	persistentFlags.StringVarP(&c.caName, "ca-name", "", "", "FabricCA of the new organization, in the format CA_NAME.CA_NAMESPACE, the CA of its peers when empty")
+	persistentFlags.StringVarP(&c.idemixCA, "idemix-ca", "", "", "FabricCA issuing the Idemix credentials of the new organization, in the format CA_NAME.CA_NAMESPACE")
 	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Output configuration as JSON and not update")
+	persistentFlags.BoolVarP(&c.signaturePolicies, endorsementSignaturePoliciesFlag, "", false, "Replace the endorsement policies of a channel with Idemix organizations, which count them, by signature policies over the organizations able to endorse")
 	cmd.MarkPersistentFlagRequired("name")
 	cmd.MarkPersistentFlagRequired("config")
 	cmd.MarkPersistentFlagRequired("peer")
-	cmd.MarkPersistentFlagRequired("org-config")
 	cmd.MarkPersistentFlagRequired("msp-id")
 	cmd.MarkPersistentFlagRequired("user")
 	return cmd
@@ -0,0 +1,344 @@
+package channel
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+)
+
+// blockJSON is the decoded block printed by channel blocks, the error is set when the last config index can't be
+// decoded
+type blockJSON struct {
+	Number       uint64    `json:"number"`
+	DataHash     string    `json:"dataHash"`
+	PreviousHash string    `json:"previousHash"`
+	LastConfig   uint64    `json:"lastConfig"`
+	Transactions []*txJSON `json:"transactions"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// txJSON is the decoded transaction of a block, the actions are only set for endorser transactions and the error
+// when the transaction can't be decoded, with the fields decoded until then
+type txJSON struct {
+	TxID           string          `json:"txId"`
+	BlockNumber    uint64          `json:"blockNumber"`
+	Index          int             `json:"index"`
+	Type           string          `json:"type"`
+	ChannelID      string          `json:"channelId"`
+	Timestamp      *time.Time      `json:"timestamp,omitempty"`
+	CreatorMSPID   string          `json:"creatorMspId"`
+	ValidationCode string          `json:"validationCode"`
+	Actions        []*txActionJSON `json:"actions,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// bytesJSON is a byte value of a transaction, the UTF-8 text of the value when it's valid UTF-8 and base64 otherwise
+type bytesJSON struct {
+	Encoding string `json:"encoding"`
+	Data     string `json:"data"`
+}
+
+func newBytesJSON(value []byte) *bytesJSON {
+	if utf8.Valid(value) {
+		return &bytesJSON{Encoding: "utf8", Data: string(value)}
+	}
+	return &bytesJSON{Encoding: "base64", Data: base64.StdEncoding.EncodeToString(value)}
+}
+
+type txActionJSON struct {
+	Chaincode        string         `json:"chaincode"`
+	ChaincodeVersion string         `json:"chaincodeVersion,omitempty"`
+	Args             []*bytesJSON   `json:"args"`
+	ResponseStatus   int32          `json:"responseStatus"`
+	ResponseMessage  string         `json:"responseMessage,omitempty"`
+	Endorsers        []string       `json:"endorsers"`
+	RwSets           []*nsRwSetJSON `json:"rwsets"`
+}
+
+type nsRwSetJSON struct {
+	Namespace    string                 `json:"namespace"`
+	Reads        []*kvReadJSON          `json:"reads"`
+	Writes       []*kvWriteJSON         `json:"writes"`
+	RangeQueries []*rangeQueryJSON      `json:"rangeQueries,omitempty"`
+	Collections  []*collHashedRwSetJSON `json:"collections,omitempty"`
+}
+
+type kvReadJSON struct {
+	Key     string       `json:"key"`
+	Version *versionJSON `json:"version"`
+}
+
+type versionJSON struct {
+	BlockNum uint64 `json:"blockNum"`
+	TxNum    uint64 `json:"txNum"`
+}
+
+type kvWriteJSON struct {
+	Key      string     `json:"key"`
+	IsDelete bool       `json:"isDelete"`
+	Value    *bytesJSON `json:"value"`
+}
+
+type rangeQueryJSON struct {
+	StartKey     string `json:"startKey"`
+	EndKey       string `json:"endKey"`
+	ItrExhausted bool   `json:"itrExhausted"`
+}
+
+// collHashedRwSetJSON is the hashed read/write set of a private data collection, the keys are hex encoded hashes
+type collHashedRwSetJSON struct {
+	Name   string   `json:"name"`
+	Reads  []string `json:"reads"`
+	Writes []string `json:"writes"`
+}
+
+// newLedgerClient returns the ledger client of the channel for the organization of the peer and the name of the peer
+// to target the queries
+func newLedgerClient(configPath string, peerName string, channelName string, userName string) (*fabsdk.FabricSDK, *ledger.Client, string, error) {
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	peer, err := helpers.GetPeerByFullName(oclient, peerName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	sdk, err := fabsdk.New(config.FromFile(configPath))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	ledgerClient, err := ledger.New(sdk.ChannelContext(
+		channelName,
+		fabsdk.WithUser(userName),
+		fabsdk.WithOrg(peer.Spec.MspID),
+	))
+	if err != nil {
+		sdk.Close()
+		return nil, nil, "", err
+	}
+	return sdk, ledgerClient, peer.Name, nil
+}
+
+// decodeBlock decodes the header, the last config index and the transactions of a block, the transactions that can't
+// be decoded are kept with their error
+func decodeBlock(block *cb.Block) *blockJSON {
+	decoded := &blockJSON{
+		Number:       block.Header.Number,
+		DataHash:     hex.EncodeToString(block.Header.DataHash),
+		PreviousHash: hex.EncodeToString(block.Header.PreviousHash),
+		Transactions: []*txJSON{},
+	}
+	lastConfig, err := protoutil.GetLastConfigIndexFromBlock(block)
+	if err != nil {
+		decoded.Error = errors.Wrapf(err, "failed to get the last config of block %d", block.Header.Number).Error()
+	}
+	decoded.LastConfig = lastConfig
+	for i := range block.Data.Data {
+		decoded.Transactions = append(decoded.Transactions, decodeTransaction(block, i))
+	}
+	return decoded
+}
+
+// transactionsFilter returns the validation codes of the transactions stored in the metadata of the block
+func transactionsFilter(block *cb.Block) []byte {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return nil
+	}
+	return block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER]
+}
+
+// decodeTransaction decodes the envelope at the index of the block, the validation code is taken from the
+// transactions filter of the block metadata
+func decodeTransaction(block *cb.Block, index int) *txJSON {
+	tx := &txJSON{
+		BlockNumber: block.Header.Number,
+		Index:       index,
+	}
+	// blocks of the orderer, like the genesis block, don't have the transactions filter
+	txFilter := transactionsFilter(block)
+	if index < len(txFilter) {
+		tx.ValidationCode = pb.TxValidationCode(txFilter[index]).String()
+	} else {
+		tx.ValidationCode = pb.TxValidationCode_VALID.String()
+	}
+	err := decodeEnvelope(tx, block, index)
+	if err != nil {
+		tx.Error = errors.Wrapf(err, "failed to decode transaction %d of block %d", index, block.Header.Number).Error()
+	}
+	return tx
+}
+
+// decodeEnvelope sets the header, the creator and the actions of the envelope at the index of the block in the
+// transaction
+func decodeEnvelope(tx *txJSON, block *cb.Block, index int) error {
+	envelope, err := protoutil.ExtractEnvelope(block, index)
+	if err != nil {
+		return err
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return err
+	}
+	if payload.Header == nil {
+		return errors.New("envelope has no header")
+	}
+	channelHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return err
+	}
+	tx.TxID = channelHeader.TxId
+	tx.Type = cb.HeaderType(channelHeader.Type).String()
+	tx.ChannelID = channelHeader.ChannelId
+	if channelHeader.Timestamp != nil {
+		timestamp := time.Unix(channelHeader.Timestamp.Seconds, int64(channelHeader.Timestamp.Nanos)).UTC()
+		tx.Timestamp = &timestamp
+	}
+	signatureHeader, err := protoutil.UnmarshalSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return err
+	}
+	creator, err := protoutil.UnmarshalSerializedIdentity(signatureHeader.Creator)
+	if err != nil {
+		return err
+	}
+	tx.CreatorMSPID = creator.Mspid
+	if channelHeader.Type != int32(cb.HeaderType_ENDORSER_TRANSACTION) {
+		return nil
+	}
+	transaction, err := protoutil.UnmarshalTransaction(payload.Data)
+	if err != nil {
+		return err
+	}
+	for _, action := range transaction.Actions {
+		txAction, err := decodeTransactionAction(action)
+		if err != nil {
+			return err
+		}
+		tx.Actions = append(tx.Actions, txAction)
+	}
+	return nil
+}
+
+// decodeTransactionAction decodes the chaincode invocation, the endorsements and the read/write sets of an action
+func decodeTransactionAction(action *pb.TransactionAction) (*txActionJSON, error) {
+	actionPayload, chaincodeAction, err := protoutil.GetPayloads(action)
+	if err != nil {
+		return nil, err
+	}
+	proposalPayload, err := protoutil.UnmarshalChaincodeProposalPayload(actionPayload.ChaincodeProposalPayload)
+	if err != nil {
+		return nil, err
+	}
+	invocationSpec, err := protoutil.UnmarshalChaincodeInvocationSpec(proposalPayload.Input)
+	if err != nil {
+		return nil, err
+	}
+	txAction := &txActionJSON{
+		Args:      []*bytesJSON{},
+		Endorsers: []string{},
+		RwSets:    []*nsRwSetJSON{},
+	}
+	if spec := invocationSpec.ChaincodeSpec; spec != nil {
+		if spec.ChaincodeId != nil {
+			txAction.Chaincode = spec.ChaincodeId.Name
+		}
+		if spec.Input != nil {
+			for _, arg := range spec.Input.Args {
+				txAction.Args = append(txAction.Args, newBytesJSON(arg))
+			}
+		}
+	}
+	if chaincodeAction.ChaincodeId != nil {
+		txAction.ChaincodeVersion = chaincodeAction.ChaincodeId.Version
+	}
+	if chaincodeAction.Response != nil {
+		txAction.ResponseStatus = chaincodeAction.Response.Status
+		txAction.ResponseMessage = chaincodeAction.Response.Message
+	}
+	for _, endorsement := range actionPayload.Action.Endorsements {
+		endorser := &msp.SerializedIdentity{}
+		err = proto.Unmarshal(endorsement.Endorser, endorser)
+		if err != nil {
+			return nil, err
+		}
+		txAction.Endorsers = append(txAction.Endorsers, endorser.Mspid)
+	}
+	txRwSet := &rwsetutil.TxRwSet{}
+	err = txRwSet.FromProtoBytes(chaincodeAction.Results)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the read/write set")
+	}
+	for _, nsRwSet := range txRwSet.NsRwSets {
+		txAction.RwSets = append(txAction.RwSets, decodeNsRwSet(nsRwSet))
+	}
+	return txAction, nil
+}
+
+func decodeNsRwSet(nsRwSet *rwsetutil.NsRwSet) *nsRwSetJSON {
+	decoded := &nsRwSetJSON{
+		Namespace: nsRwSet.NameSpace,
+		Reads:     []*kvReadJSON{},
+		Writes:    []*kvWriteJSON{},
+	}
+	if kvRwSet := nsRwSet.KvRwSet; kvRwSet != nil {
+		for _, read := range kvRwSet.Reads {
+			decoded.Reads = append(decoded.Reads, &kvReadJSON{
+				Key:     read.Key,
+				Version: decodeVersion(read.Version),
+			})
+		}
+		for _, write := range kvRwSet.Writes {
+			decoded.Writes = append(decoded.Writes, &kvWriteJSON{
+				Key:      write.Key,
+				IsDelete: write.IsDelete,
+				Value:    newBytesJSON(write.Value),
+			})
+		}
+		for _, rangeQuery := range kvRwSet.RangeQueriesInfo {
+			decoded.RangeQueries = append(decoded.RangeQueries, &rangeQueryJSON{
+				StartKey:     rangeQuery.StartKey,
+				EndKey:       rangeQuery.EndKey,
+				ItrExhausted: rangeQuery.ItrExhausted,
+			})
+		}
+	}
+	for _, collection := range nsRwSet.CollHashedRwSets {
+		decodedCollection := &collHashedRwSetJSON{
+			Name:   collection.CollectionName,
+			Reads:  []string{},
+			Writes: []string{},
+		}
+		if collection.HashedRwSet != nil {
+			for _, read := range collection.HashedRwSet.HashedReads {
+				decodedCollection.Reads = append(decodedCollection.Reads, hex.EncodeToString(read.KeyHash))
+			}
+			for _, write := range collection.HashedRwSet.HashedWrites {
+				decodedCollection.Writes = append(decodedCollection.Writes, hex.EncodeToString(write.KeyHash))
+			}
+		}
+		decoded.Collections = append(decoded.Collections, decodedCollection)
+	}
+	return decoded
+}
+
+// decodeVersion returns nil for the reads of keys that didn't exist
+func decodeVersion(version *kvrwset.Version) *versionJSON {
+	if version == nil {
+		return nil
+	}
+	return &versionJSON{BlockNum: version.BlockNum, TxNum: version.TxNum}
+}
@@ -0,0 +1,53 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/protoutil"
+	. "github.com/onsi/gomega"
+)
+
+func TestNewBytesJSON(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(newBytesJSON([]byte(`{"owner":"Tomás"}`))).To(Equal(&bytesJSON{Encoding: "utf8", Data: `{"owner":"Tomás"}`}))
+	g.Expect(newBytesJSON([]byte{0xff, 0x00, 0x01})).To(Equal(&bytesJSON{Encoding: "base64", Data: "/wAB"}))
+	g.Expect(newBytesJSON(nil)).To(Equal(&bytesJSON{Encoding: "utf8", Data: ""}))
+}
+
+func TestDecodeBlockWithUndecodableTransaction(t *testing.T) {
+	g := NewWithT(t)
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: "Org1MSP"})
+	g.Expect(err).NotTo(HaveOccurred())
+	channelHeader, err := proto.Marshal(&cb.ChannelHeader{Type: int32(cb.HeaderType_MESSAGE), ChannelId: "demo", TxId: "tx1"})
+	g.Expect(err).NotTo(HaveOccurred())
+	signatureHeader, err := proto.Marshal(&cb.SignatureHeader{Creator: creator})
+	g.Expect(err).NotTo(HaveOccurred())
+	payload, err := proto.Marshal(&cb.Payload{Header: &cb.Header{ChannelHeader: channelHeader, SignatureHeader: signatureHeader}})
+	g.Expect(err).NotTo(HaveOccurred())
+	envelope, err := proto.Marshal(&cb.Envelope{Payload: payload})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	block := protoutil.NewBlock(3, nil)
+	block.Data.Data = [][]byte{{0xff, 0xff}, envelope}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{
+		byte(pb.TxValidationCode_BAD_PAYLOAD),
+		byte(pb.TxValidationCode_VALID),
+	}
+
+	decoded := decodeBlock(block)
+	g.Expect(decoded.Error).To(BeEmpty())
+	g.Expect(decoded.Transactions).To(HaveLen(2))
+	undecodable := decoded.Transactions[0]
+	g.Expect(undecodable.Error).To(ContainSubstring("failed to decode transaction 0 of block 3"))
+	g.Expect(undecodable.ValidationCode).To(Equal(pb.TxValidationCode_BAD_PAYLOAD.String()))
+	tx := decoded.Transactions[1]
+	g.Expect(tx.Error).To(BeEmpty())
+	g.Expect(tx.TxID).To(Equal("tx1"))
+	g.Expect(tx.Index).To(Equal(1))
+	g.Expect(tx.CreatorMSPID).To(Equal("Org1MSP"))
+	g.Expect(tx.Type).To(Equal(cb.HeaderType_MESSAGE.String()))
+}
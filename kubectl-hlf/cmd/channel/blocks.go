@@ -0,0 +1,89 @@
+package channel
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type channelBlocksCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	from        uint64
+	to          int64
+}
+
+func (c *channelBlocksCmd) validate() error {
+	if c.to >= 0 && uint64(c.to) < c.from {
+		return errors.Errorf("--to %d is lower than --from %d", c.to, c.from)
+	}
+	return nil
+}
+
+func (c *channelBlocksCmd) run(out io.Writer) error {
+	sdk, ledgerClient, peerName, err := newLedgerClient(c.configPath, c.peer, c.channelName, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	target := ledger.WithTargetEndpoints(peerName)
+	info, err := ledgerClient.QueryInfo(target)
+	if err != nil {
+		return err
+	}
+	height := info.BCI.Height
+	to := height - 1
+	if c.to >= 0 {
+		to = uint64(c.to)
+	}
+	if to >= height {
+		return errors.Errorf("--to %d is beyond the last block %d of the channel", to, height-1)
+	}
+	encoder := json.NewEncoder(out)
+	for number := c.from; number <= to; number++ {
+		block, err := ledgerClient.QueryBlock(number, target)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query block %d", number)
+		}
+		err = encoder.Encode(decodeBlock(block))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newChannelBlocksCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &channelBlocksCmd{}
+	cmd := &cobra.Command{
+		Use:   "blocks",
+		Short: "Print the blocks of a channel as JSON lines",
+		Long: `Print the blocks of a channel as JSON lines, one block per line with the headers, validation codes, creators,
+chaincode invocations and read/write sets of its transactions. The transactions that can't be decoded are printed
+with an error and the fields decoded until the error. Arguments and values are printed as UTF-8 text when they are
+valid UTF-8 and as base64 otherwise.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "p", "", "Peer to query the blocks")
+	persistentFlags.StringVarP(&c.userName, "user", "u", "", "User")
+	persistentFlags.StringVarP(&c.channelName, "channel", "c", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.Uint64VarP(&c.from, "from", "", 0, "Number of the first block")
+	persistentFlags.Int64VarP(&c.to, "to", "", -1, "Number of the last block, the last block of the channel by default")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	cmd.MarkPersistentFlagRequired("config")
+	return cmd
+}
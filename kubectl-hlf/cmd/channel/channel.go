@@ -18,5 +18,15 @@ func NewChannelCmd(stdOut io.Writer, stdErr io.Writer) *cobra.Command {
 	consortiumCmd.AddCommand(newInspectChannelCMD(stdOut, stdErr))
 	consortiumCmd.AddCommand(newTopChannelCMD(stdOut, stdErr))
 	consortiumCmd.AddCommand(newAddOrgToChannelCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newDelOrgFromChannelCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newUpdateChannelCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newSignUpdateCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newSubmitUpdateCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newPolicyCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newACLCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newChannelSettingsCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newChannelBlocksCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newChannelTxCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(newConfigHistoryCMD(stdOut, stdErr))
 	return consortiumCmd
 }
@@ -0,0 +1,297 @@
+package channel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// configVersion is a config of the channel and the config update that produced it
+type configVersion struct {
+	Sequence    uint64     `json:"sequence"`
+	BlockNumber uint64     `json:"blockNumber"`
+	Timestamp   *time.Time `json:"timestamp,omitempty"`
+	Signers     []string   `json:"signers"`
+	Sections    []string   `json:"sections"`
+
+	config *common.Config
+}
+
+var configHistoryHeader = []string{"Sequence", "Block", "Timestamp", "Signers", "Sections"}
+
+func (v *configVersion) row() []string {
+	timestamp := ""
+	if v.Timestamp != nil {
+		timestamp = v.Timestamp.Format(time.RFC3339)
+	}
+	return []string{
+		fmt.Sprintf("%d", v.Sequence),
+		fmt.Sprintf("%d", v.BlockNumber),
+		timestamp,
+		strings.Join(v.Signers, ","),
+		strings.Join(v.Sections, ","),
+	}
+}
+
+type configHistoryCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	output      string
+	diff        []uint
+}
+
+func (c *configHistoryCmd) validate() error {
+	if len(c.diff) != 0 && len(c.diff) != 2 {
+		return errors.Errorf("--diff expects two config sequences, e.g. --diff=3,5")
+	}
+	return helpers.ValidateOutputFormat(c.output)
+}
+
+func (c *configHistoryCmd) run(out io.Writer) error {
+	sdk, ledgerClient, peerName, err := newLedgerClient(c.configPath, c.peer, c.channelName, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	var oldestSequence uint64
+	if len(c.diff) == 2 {
+		oldestSequence = uint64(c.diff[0])
+		if uint64(c.diff[1]) < oldestSequence {
+			oldestSequence = uint64(c.diff[1])
+		}
+	}
+	versions, err := configHistory(ledgerClient, ledger.WithTargetEndpoints(peerName), oldestSequence)
+	if err != nil {
+		return err
+	}
+	if len(c.diff) == 2 {
+		return writeConfigVersionsDiff(out, versions, uint64(c.diff[0]), uint64(c.diff[1]))
+	}
+	var rows [][]string
+	for _, version := range versions {
+		rows = append(rows, version.row())
+	}
+	return helpers.PrintOutput(out, c.output, versions, configHistoryHeader, rows)
+}
+
+// configHistory walks the last config pointers from the last block of the channel back to the config block with
+// the oldest sequence, the versions are returned from the newest to the oldest
+func configHistory(ledgerClient *ledger.Client, target ledger.RequestOption, oldestSequence uint64) ([]*configVersion, error) {
+	info, err := ledgerClient.QueryInfo(target)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ledgerClient.QueryBlock(info.BCI.Height-1, target)
+	if err != nil {
+		return nil, err
+	}
+	var versions []*configVersion
+	for {
+		configIndex, err := protoutil.GetLastConfigIndexFromBlock(block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the last config of block %d", block.Header.Number)
+		}
+		configBlock, err := ledgerClient.QueryBlock(configIndex, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query config block %d", configIndex)
+		}
+		version, err := decodeConfigVersion(configBlock)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode config block %d", configIndex)
+		}
+		versions = append(versions, version)
+		if configIndex == 0 || version.Sequence <= oldestSequence {
+			return versions, nil
+		}
+		// the last config of the previous block is the previous config block
+		block, err = ledgerClient.QueryBlock(configIndex-1, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// decodeConfigVersion decodes the config of a config block, the signers and sections of the config update are empty
+// for the genesis block
+func decodeConfigVersion(block *common.Block) (*configVersion, error) {
+	envelope, err := protoutil.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+	configEnvelope := &common.ConfigEnvelope{}
+	channelHeader, err := protoutil.UnmarshalEnvelopeOfType(envelope, common.HeaderType_CONFIG, configEnvelope)
+	if err != nil {
+		return nil, err
+	}
+	if configEnvelope.Config == nil {
+		return nil, errors.New("config envelope has no config")
+	}
+	version := &configVersion{
+		Sequence:    configEnvelope.Config.Sequence,
+		BlockNumber: block.Header.Number,
+		Signers:     []string{},
+		Sections:    []string{},
+		config:      configEnvelope.Config,
+	}
+	if channelHeader.Timestamp != nil {
+		timestamp := time.Unix(channelHeader.Timestamp.Seconds, int64(channelHeader.Timestamp.Nanos)).UTC()
+		version.Timestamp = &timestamp
+	}
+	if configEnvelope.LastUpdate == nil {
+		return version, nil
+	}
+	payload, err := protoutil.UnmarshalPayload(configEnvelope.LastUpdate.Payload)
+	if err != nil {
+		return nil, err
+	}
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	err = proto.Unmarshal(payload.Data, configUpdateEnvelope)
+	if err != nil {
+		return nil, err
+	}
+	signers, err := signerMSPIDs(configUpdateEnvelope)
+	if err != nil {
+		return nil, err
+	}
+	version.Signers = append(version.Signers, signers...)
+	configUpdate := &common.ConfigUpdate{}
+	err = proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate)
+	if err != nil {
+		return nil, err
+	}
+	version.Sections = append(version.Sections, changedSections(configUpdate)...)
+	return version, nil
+}
+
+// channelGroupKey is the name of the channel group in the sections of a config version
+const channelGroupKey = "Channel"
+
+// changedSections returns the groups the config update changes, the channel group is reported on its own when its
+// values or policies change instead of as a change of the application and orderer sections
+func changedSections(configUpdate *common.ConfigUpdate) []string {
+	readSet, writeSet := configUpdate.ReadSet, configUpdate.WriteSet
+	if writeSet == nil {
+		return nil
+	}
+	var sections []string
+	if groupElementsChanged(readSet, writeSet) {
+		sections = append(sections, channelGroupKey)
+	}
+	for _, section := range []string{channelconfig.ApplicationGroupKey, channelconfig.OrdererGroupKey} {
+		writeGroup, ok := writeSet.Groups[section]
+		if !ok {
+			continue
+		}
+		var readGroup *common.ConfigGroup
+		if readSet != nil {
+			readGroup = readSet.Groups[section]
+		}
+		if groupChanged(readGroup, writeGroup) {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// groupElementsChanged returns true when the write set of the group has a version, a value or a policy that isn't in
+// its read set
+func groupElementsChanged(readGroup *common.ConfigGroup, writeGroup *common.ConfigGroup) bool {
+	if readGroup == nil || readGroup.Version != writeGroup.Version {
+		return true
+	}
+	for key, value := range writeGroup.Values {
+		readValue, ok := readGroup.Values[key]
+		if !ok || readValue.Version != value.Version {
+			return true
+		}
+	}
+	for key, policy := range writeGroup.Policies {
+		readPolicy, ok := readGroup.Policies[key]
+		if !ok || readPolicy.Version != policy.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// groupChanged returns true when the write set changes the group or any of its subgroups
+func groupChanged(readGroup *common.ConfigGroup, writeGroup *common.ConfigGroup) bool {
+	if groupElementsChanged(readGroup, writeGroup) {
+		return true
+	}
+	for key, group := range writeGroup.Groups {
+		if groupChanged(readGroup.Groups[key], group) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeConfigVersionsDiff writes the diff between the configs with the sequences, decoded as in channel inspect
+func writeConfigVersionsDiff(out io.Writer, versions []*configVersion, fromSequence uint64, toSequence uint64) error {
+	findConfig := func(sequence uint64) (*common.Config, error) {
+		for _, version := range versions {
+			if version.Sequence == sequence {
+				return version.config, nil
+			}
+		}
+		return nil, errors.Errorf("config sequence %d not found in the channel", sequence)
+	}
+	fromConfig, err := findConfig(fromSequence)
+	if err != nil {
+		return err
+	}
+	toConfig, err := findConfig(toSequence)
+	if err != nil {
+		return err
+	}
+	return writeConfigDiff(
+		out,
+		fromConfig,
+		toConfig,
+		fmt.Sprintf("sequence %d", fromSequence),
+		fmt.Sprintf("sequence %d", toSequence),
+	)
+}
+
+func newConfigHistoryCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &configHistoryCmd{}
+	cmd := &cobra.Command{
+		Use:   "config-history",
+		Short: "List the config versions of a channel and diff them",
+		Long: `List the config versions of a channel with the block, timestamp, signers and sections of the update that
+produced each of them, or print the diff between two config versions with --diff=FROM,TO. The sections are the
+Application and Orderer groups the update changes, and Channel when it changes the values or policies of the
+channel group itself.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "p", "", "Peer to query the config blocks")
+	persistentFlags.StringVarP(&c.userName, "user", "u", "", "User")
+	persistentFlags.StringVarP(&c.channelName, "channel", "c", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.output, "output", "o", helpers.OutputTable, "output format, table, json or yaml")
+	persistentFlags.UintSliceVarP(&c.diff, "diff", "", nil, "Sequences of the two config versions to diff, e.g. 3,5")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	cmd.MarkPersistentFlagRequired("config")
+	return cmd
+}
@@ -0,0 +1,85 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	. "github.com/onsi/gomega"
+)
+
+func TestChangedSections(t *testing.T) {
+	group := func(version uint64, values map[string]uint64, groups map[string]*common.ConfigGroup) *common.ConfigGroup {
+		configGroup := &common.ConfigGroup{
+			Version: version,
+			Values:  map[string]*common.ConfigValue{},
+			Groups:  groups,
+		}
+		for key, valueVersion := range values {
+			configGroup.Values[key] = &common.ConfigValue{Version: valueVersion}
+		}
+		return configGroup
+	}
+	readSet := func() *common.ConfigGroup {
+		return group(0, nil, map[string]*common.ConfigGroup{
+			"Application": group(1, nil, map[string]*common.ConfigGroup{"Org1MSP": group(0, nil, nil)}),
+			"Orderer":     group(0, map[string]uint64{"BatchSize": 0}, nil),
+		})
+	}
+	tests := []struct {
+		name     string
+		readSet  *common.ConfigGroup
+		writeSet *common.ConfigGroup
+		want     []string
+	}{
+		{
+			name:    "value of the orderer group",
+			readSet: readSet(),
+			writeSet: group(0, nil, map[string]*common.ConfigGroup{
+				"Application": group(1, nil, map[string]*common.ConfigGroup{"Org1MSP": group(0, nil, nil)}),
+				"Orderer":     group(0, map[string]uint64{"BatchSize": 1}, nil),
+			}),
+			want: []string{"Orderer"},
+		},
+		{
+			name:    "organization added to the application group",
+			readSet: readSet(),
+			writeSet: group(0, nil, map[string]*common.ConfigGroup{
+				"Application": group(2, nil, map[string]*common.ConfigGroup{
+					"Org1MSP": group(0, nil, nil),
+					"Org2MSP": group(0, nil, nil),
+				}),
+			}),
+			want: []string{"Application"},
+		},
+		{
+			name:    "capabilities of the channel group",
+			readSet: group(0, map[string]uint64{"OrdererAddresses": 0}, readSet().Groups),
+			writeSet: group(0, map[string]uint64{"OrdererAddresses": 0, "Capabilities": 0}, map[string]*common.ConfigGroup{
+				"Application": group(1, nil, map[string]*common.ConfigGroup{"Org1MSP": group(0, nil, nil)}),
+				"Orderer":     group(0, map[string]uint64{"BatchSize": 0}, nil),
+			}),
+			want: []string{"Channel"},
+		},
+		{
+			name:    "channel group and orderer group",
+			readSet: readSet(),
+			writeSet: group(1, nil, map[string]*common.ConfigGroup{
+				"Application": group(1, nil, map[string]*common.ConfigGroup{"Org1MSP": group(0, nil, nil)}),
+				"Orderer":     group(0, map[string]uint64{"BatchSize": 1}, nil),
+			}),
+			want: []string{"Channel", "Orderer"},
+		},
+		{
+			name:     "no read set",
+			writeSet: group(0, nil, map[string]*common.ConfigGroup{"Orderer": group(0, nil, nil)}),
+			want:     []string{"Channel", "Orderer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			sections := changedSections(&common.ConfigUpdate{ReadSet: tt.readSet, WriteSet: tt.writeSet})
+			g.Expect(sections).To(Equal(tt.want))
+		})
+	}
+}
@@ -67,38 +67,38 @@ func (c *createChannelCmd) run() error {
 		if !utils.Contains(c.organizations, peer.Name) {
 			continue
 		}
-		certAuth, err := helpers.GetCertAuthByURL(
-			oclient,
-			peer.Spec.Secret.Enrollment.Component.Cahost,
-			peer.Spec.Secret.Enrollment.Component.Caport,
-		)
+		mspCerts, err := helpers.GetPeerMSPCerts(oclient, peer, helpers.GetCertAuthByComponent)
 		if err != nil {
 			return err
 		}
 		var nodes []testutils.PeerNode
 		peerOrgs = append(peerOrgs, testutils.PeerOrganization{
-			RootCert:    certAuth.Status.CACert,
-			TLSRootCert: certAuth.Status.TLSCACert,
-			MspID:       peer.Spec.MspID,
-			Peers:       nodes,
+			RootCert:             mspCerts.CACert,
+			TLSRootCert:          mspCerts.TLSCACert,
+			IntermediateCerts:    mspCerts.CAIntermediateCerts,
+			TLSIntermediateCerts: mspCerts.TLSCAIntermediateCerts,
+			MspID:                peer.Spec.MspID,
+			Peers:                nodes,
 		})
 	}
 	if len(peerOrgs) == 0 {
 		return errors.Errorf("No peer orgs specified")
 	}
-	certAuth, err := helpers.GetCertAuthByURL(
+	certAuth, err := helpers.GetCertAuthByComponent(
 		oclient,
-		ordService.Spec.Enrollment.Component.Cahost,
-		ordService.Spec.Enrollment.Component.Caport,
+		ordService.Spec.Enrollment.Component,
+		ordService.Object.Namespace,
 	)
 	if err != nil {
 		return err
 	}
 	ordOrganization := testutils.OrdererOrganization{
-		Nodes:        []testutils.OrdererNode{},
-		RootTLSCert:  certAuth.Status.TLSCACert,
-		RootSignCert: certAuth.Status.CACert,
-		MspID:        c.ordererOrg,
+		Nodes:                 []testutils.OrdererNode{},
+		RootTLSCert:           certAuth.Status.TLSCACert,
+		RootSignCert:          certAuth.Status.CACert,
+		IntermediateTLSCerts:  certAuth.Status.TLSCAIntermediateCerts,
+		IntermediateSignCerts: certAuth.Status.CAIntermediateCerts,
+		MspID:                 c.ordererOrg,
 	}
 
 	profileConfig, err := testutils.GetChannelProfileConfig(
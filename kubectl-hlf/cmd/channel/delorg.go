@@ -0,0 +1,264 @@
+package channel
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type delOrgCmd struct {
+	configPath        string
+	peer              string
+	channelName       string
+	userName          string
+	mspID             string
+	dryRun            bool
+	signaturePolicies bool
+}
+
+func (c *delOrgCmd) validate() error {
+	return nil
+}
+
+func (c *delOrgCmd) run(out io.Writer) error {
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return err
+	}
+	peer, err := helpers.GetPeerByFullName(oclient, c.peer)
+	if err != nil {
+		return err
+	}
+	mspID := peer.Spec.MspID
+	configBackend := config.FromFile(c.configPath)
+	sdk, err := fabsdk.New(configBackend)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	resClient, err := resmgmt.New(sdk.Context(
+		fabsdk.WithUser(c.userName),
+		fabsdk.WithOrg(mspID),
+	))
+	if err != nil {
+		return err
+	}
+	channelID := c.channelName
+	channelConfig, err := GetCurrentConfigFromPeer(resClient, channelID)
+	if err != nil {
+		return err
+	}
+	modifiedConfig := proto.Clone(channelConfig).(*common.Config)
+	application, ok := modifiedConfig.ChannelGroup.Groups[channelconfig.ApplicationGroupKey]
+	if !ok {
+		return errors.Errorf("channel %s doesn't have an application section", channelID)
+	}
+	if _, ok := application.Groups[c.mspID]; !ok {
+		return errors.Errorf("msp ID %s not found in channel %s", c.mspID, channelID)
+	}
+	if len(application.Groups) == 1 {
+		return errors.Errorf("%s is the last organization of channel %s", c.mspID, channelID)
+	}
+	delete(application.Groups, c.mspID)
+	err = updateIdemixEndorsementPolicies(channelConfig.ChannelGroup.Groups[channelconfig.ApplicationGroupKey], application, c.signaturePolicies)
+	if err != nil {
+		return err
+	}
+
+	chaincodes, err := resClient.LifecycleQueryCommittedCC(
+		channelID,
+		resmgmt.LifecycleQueryCommittedCCRequest{},
+		resmgmt.WithTargetEndpoints(c.peer),
+	)
+	for _, warning := range orgRemovalWarnings(application, c.mspID, chaincodes, err) {
+		log.Warn(warning)
+	}
+
+	confUpdate, err := resmgmt.CalculateConfigUpdate(channelID, channelConfig, modifiedConfig)
+	if err != nil {
+		return err
+	}
+	if c.dryRun {
+		return protolator.DeepMarshalJSON(out, confUpdate)
+	}
+	configEnvelopeBytes, err := GetConfigEnvelopeBytes(confUpdate)
+	if err != nil {
+		return err
+	}
+	txID, err := resClient.SaveChannel(resmgmt.SaveChannelRequest{
+		ChannelID:     channelID,
+		ChannelConfig: bytes.NewReader(configEnvelopeBytes),
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Organization %s removed from channel %s, txID=%s", c.mspID, channelID, string(txID.TransactionID))
+	return nil
+}
+
+// orgRemovalWarnings returns the lifecycle and endorsement policies of the application group and of the committed
+// chaincodes that can't be satisfied once the organization is removed from it. The policies of the chaincodes aren't
+// checked when chaincodesErr is set, the query of the committed chaincodes failed
+func orgRemovalWarnings(application *common.ConfigGroup, mspID string, chaincodes []resmgmt.LifecycleChaincodeDefinition, chaincodesErr error) []string {
+	var warnings []string
+	for _, policyName := range []string{"LifecycleEndorsement", "Endorsement"} {
+		if !configPolicySatisfiable(application, policyName, mspID) {
+			warnings = append(warnings, fmt.Sprintf("The %s policy of the channel can't be satisfied without %s", policyName, mspID))
+		}
+	}
+	if chaincodesErr != nil {
+		return append(warnings, fmt.Sprintf("The policies of the committed chaincodes aren't checked, failed to query them: %v", chaincodesErr))
+	}
+	for _, chaincode := range chaincodes {
+		if chaincode.SignaturePolicy != nil && !signaturePolicySatisfiable(chaincode.SignaturePolicy, mspID) {
+			warnings = append(warnings, fmt.Sprintf("The endorsement policy of chaincode %s can't be satisfied without %s", chaincode.Name, mspID))
+		}
+		if chaincode.ChannelConfigPolicy != "" {
+			policyName := strings.TrimPrefix(chaincode.ChannelConfigPolicy, fmt.Sprintf("/Channel/%s/", channelconfig.ApplicationGroupKey))
+			if !configPolicySatisfiable(application, policyName, mspID) {
+				warnings = append(warnings, fmt.Sprintf("The endorsement policy %s of chaincode %s can't be satisfied without %s", chaincode.ChannelConfigPolicy, chaincode.Name, mspID))
+			}
+		}
+		for _, collection := range chaincode.CollectionConfig {
+			staticCollection := collection.GetStaticCollectionConfig()
+			memberOrgsPolicy := staticCollection.GetMemberOrgsPolicy().GetSignaturePolicy()
+			if memberOrgsPolicy != nil && !signaturePolicySatisfiable(memberOrgsPolicy, mspID) {
+				warnings = append(warnings, fmt.Sprintf("The member policy of collection %s of chaincode %s can't be satisfied without %s", staticCollection.Name, chaincode.Name, mspID))
+			}
+		}
+	}
+	return warnings
+}
+
+// configPolicySatisfiable returns false when the policy of the group can't be satisfied without the organization,
+// the organization is expected to be removed from the group already
+func configPolicySatisfiable(group *common.ConfigGroup, policyName string, mspID string) bool {
+	configPolicy, ok := group.Policies[policyName]
+	if !ok || configPolicy.Policy == nil {
+		return true
+	}
+	switch common.Policy_PolicyType(configPolicy.Policy.Type) {
+	case common.Policy_SIGNATURE:
+		signaturePolicy := &common.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, signaturePolicy); err != nil {
+			return true
+		}
+		return signaturePolicySatisfiable(signaturePolicy, mspID)
+	case common.Policy_IMPLICIT_META:
+		implicitMetaPolicy := &common.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, implicitMetaPolicy); err != nil {
+			return true
+		}
+		// as Fabric, a sub-group without the sub-policy rejects
+		satisfied := 0
+		for _, org := range group.Groups {
+			if _, ok := org.Policies[implicitMetaPolicy.SubPolicy]; ok && configPolicySatisfiable(org, implicitMetaPolicy.SubPolicy, mspID) {
+				satisfied++
+			}
+		}
+		return satisfied >= implicitMetaThreshold(implicitMetaPolicy.Rule, len(group.Groups))
+	}
+	return true
+}
+
+// implicitMetaThreshold returns the number of sub-policies required by the rule of an implicit meta policy
+func implicitMetaThreshold(rule common.ImplicitMetaPolicy_Rule, subPolicies int) int {
+	switch rule {
+	case common.ImplicitMetaPolicy_ANY:
+		return 1
+	case common.ImplicitMetaPolicy_ALL:
+		return subPolicies
+	default:
+		return subPolicies/2 + 1
+	}
+}
+
+// signaturePolicySatisfiable returns false when the signature policy can't be satisfied without the principals of
+// the organization
+func signaturePolicySatisfiable(signaturePolicy *common.SignaturePolicyEnvelope, mspID string) bool {
+	available := make([]bool, len(signaturePolicy.Identities))
+	for i, principal := range signaturePolicy.Identities {
+		available[i] = principalMSPID(principal) != mspID
+	}
+	return signaturePolicyRuleSatisfiable(signaturePolicy.Rule, available)
+}
+
+func signaturePolicyRuleSatisfiable(rule *common.SignaturePolicy, available []bool) bool {
+	switch t := rule.GetType().(type) {
+	case *common.SignaturePolicy_SignedBy:
+		return int(t.SignedBy) < len(available) && available[t.SignedBy]
+	case *common.SignaturePolicy_NOutOf_:
+		satisfied := int32(0)
+		for _, subRule := range t.NOutOf.Rules {
+			if signaturePolicyRuleSatisfiable(subRule, available) {
+				satisfied++
+			}
+		}
+		return satisfied >= t.NOutOf.N
+	}
+	return true
+}
+
+// principalMSPID returns the MSP ID of the principal, or an empty string when it doesn't belong to a single MSP
+func principalMSPID(principal *mspproto.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case mspproto.MSPPrincipal_ROLE:
+		role := &mspproto.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return role.MspIdentifier
+		}
+	case mspproto.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mspproto.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err == nil {
+			return ou.MspIdentifier
+		}
+	case mspproto.MSPPrincipal_IDENTITY:
+		identity := &mspproto.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, identity); err == nil {
+			return identity.Mspid
+		}
+	}
+	return ""
+}
+
+func newDelOrgFromChannelCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &delOrgCmd{}
+	cmd := &cobra.Command{
+		Use:   "delorg",
+		Short: "Remove an organization from the application section of a channel",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "", "", "Admin org to invoke the updates")
+	persistentFlags.StringVarP(&c.channelName, "name", "", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
+	persistentFlags.StringVarP(&c.mspID, "msp-id", "", "", "MSP ID of the organization to remove")
+	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Output configuration as JSON and not update")
+	persistentFlags.BoolVarP(&c.signaturePolicies, endorsementSignaturePoliciesFlag, "", false, "Replace the endorsement policies of a channel with Idemix organizations, which count them, by signature policies over the organizations able to endorse")
+	cmd.MarkPersistentFlagRequired("name")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("peer")
+	cmd.MarkPersistentFlagRequired("msp-id")
+	cmd.MarkPersistentFlagRequired("user")
+	return cmd
+}
@@ -0,0 +1,139 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/policydsl"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestSignaturePolicySatisfiable(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   bool
+	}{
+		{policy: "OR('Org1MSP.member','Org2MSP.member')", want: true},
+		{policy: "AND('Org1MSP.member','Org2MSP.member')", want: false},
+		{policy: "OutOf(2,'Org1MSP.peer','Org2MSP.peer','Org3MSP.peer')", want: true},
+		{policy: "OutOf(2,'Org1MSP.peer','Org2MSP.peer')", want: false},
+		{policy: "OR(AND('Org1MSP.admin','Org2MSP.admin'),'Org3MSP.admin')", want: true},
+		{policy: "AND('Org1MSP.member',OR('Org2MSP.member','Org3MSP.member'))", want: true},
+		{policy: "OR('Org2MSP.member')", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			g := NewWithT(t)
+			signaturePolicy, err := policydsl.FromString(tt.policy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(signaturePolicySatisfiable(signaturePolicy, "Org2MSP")).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestConfigPolicySatisfiable(t *testing.T) {
+	signaturePolicy := func(g *WithT, rule string) *common.ConfigPolicy {
+		envelope, err := policydsl.FromString(rule)
+		g.Expect(err).NotTo(HaveOccurred())
+		value, err := proto.Marshal(envelope)
+		g.Expect(err).NotTo(HaveOccurred())
+		return &common.ConfigPolicy{Policy: &common.Policy{Type: int32(common.Policy_SIGNATURE), Value: value}}
+	}
+	implicitMetaPolicy := func(g *WithT, rule common.ImplicitMetaPolicy_Rule) *common.ConfigPolicy {
+		value, err := proto.Marshal(&common.ImplicitMetaPolicy{Rule: rule, SubPolicy: "Endorsement"})
+		g.Expect(err).NotTo(HaveOccurred())
+		return &common.ConfigPolicy{Policy: &common.Policy{Type: int32(common.Policy_IMPLICIT_META), Value: value}}
+	}
+	// orgs maps the remaining orgs to the rule of their Endorsement policy, empty for an org without it as the Idemix orgs
+	tests := []struct {
+		name string
+		rule common.ImplicitMetaPolicy_Rule
+		orgs map[string]string
+		want bool
+	}{
+		{
+			name: "MAJORITY of the remaining orgs",
+			rule: common.ImplicitMetaPolicy_MAJORITY,
+			orgs: map[string]string{"Org1MSP": "OR('Org1MSP.peer')", "Org3MSP": "OR('Org3MSP.peer')"},
+			want: true,
+		},
+		{
+			name: "MAJORITY with an org without Endorsement policy",
+			rule: common.ImplicitMetaPolicy_MAJORITY,
+			orgs: map[string]string{"Org1MSP": "OR('Org1MSP.peer')", "IdemixMSP": ""},
+			want: false,
+		},
+		{
+			name: "ANY with an org without Endorsement policy",
+			rule: common.ImplicitMetaPolicy_ANY,
+			orgs: map[string]string{"Org1MSP": "OR('Org1MSP.peer')", "IdemixMSP": ""},
+			want: true,
+		},
+		{
+			name: "ALL with an org without Endorsement policy",
+			rule: common.ImplicitMetaPolicy_ALL,
+			orgs: map[string]string{"Org1MSP": "OR('Org1MSP.peer')", "IdemixMSP": ""},
+			want: false,
+		},
+		{
+			name: "ANY with sub-policies requiring the removed org",
+			rule: common.ImplicitMetaPolicy_ANY,
+			orgs: map[string]string{"Org1MSP": "AND('Org1MSP.peer','Org2MSP.peer')", "Org3MSP": "OR('Org2MSP.peer')"},
+			want: false,
+		},
+		{
+			name: "MAJORITY with a sub-policy requiring the removed org",
+			rule: common.ImplicitMetaPolicy_MAJORITY,
+			orgs: map[string]string{"Org1MSP": "OR('Org1MSP.peer')", "Org3MSP": "OR('Org3MSP.peer')", "Org4MSP": "AND('Org4MSP.peer','Org2MSP.peer')"},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			application := &common.ConfigGroup{
+				Groups:   map[string]*common.ConfigGroup{},
+				Policies: map[string]*common.ConfigPolicy{"Endorsement": implicitMetaPolicy(g, tt.rule)},
+			}
+			for mspID, rule := range tt.orgs {
+				org := &common.ConfigGroup{Policies: map[string]*common.ConfigPolicy{}}
+				if rule != "" {
+					org.Policies["Endorsement"] = signaturePolicy(g, rule)
+				}
+				application.Groups[mspID] = org
+			}
+			g.Expect(configPolicySatisfiable(application, "Endorsement", "Org2MSP")).To(Equal(tt.want))
+		})
+	}
+
+	g := NewWithT(t)
+	application := &common.ConfigGroup{
+		Policies: map[string]*common.ConfigPolicy{
+			"LifecycleEndorsement": signaturePolicy(g, "OutOf(2,'Org1MSP.peer','Org2MSP.peer','Org3MSP.peer')"),
+			"Endorsement":          signaturePolicy(g, "AND('Org1MSP.peer','Org2MSP.peer')"),
+		},
+	}
+	g.Expect(configPolicySatisfiable(application, "LifecycleEndorsement", "Org2MSP")).To(BeTrue())
+	g.Expect(configPolicySatisfiable(application, "Endorsement", "Org2MSP")).To(BeFalse())
+	// a missing policy has nothing to satisfy
+	g.Expect(configPolicySatisfiable(application, "Readers", "Org2MSP")).To(BeTrue())
+}
+
+func TestOrgRemovalWarnings(t *testing.T) {
+	g := NewWithT(t)
+	endorsementPolicy, err := policydsl.FromString("AND('Org1MSP.peer','Org2MSP.peer')")
+	g.Expect(err).NotTo(HaveOccurred())
+	application := &common.ConfigGroup{Policies: map[string]*common.ConfigPolicy{}}
+	chaincodes := []resmgmt.LifecycleChaincodeDefinition{{Name: "asset", SignaturePolicy: endorsementPolicy}}
+
+	g.Expect(orgRemovalWarnings(application, "Org2MSP", chaincodes, nil)).To(ConsistOf(
+		"The endorsement policy of chaincode asset can't be satisfied without Org2MSP",
+	))
+	// the chaincodes aren't reported as satisfiable when their query failed
+	g.Expect(orgRemovalWarnings(application, "Org2MSP", nil, errors.New("access denied"))).To(ConsistOf(
+		"The policies of the committed chaincodes aren't checked, failed to query them: access denied",
+	))
+}
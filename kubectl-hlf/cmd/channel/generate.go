@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/golang/protobuf/proto"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
 	"github.com/kfsoftware/hlf-operator/controllers/testutils"
 	"github.com/kfsoftware/hlf-operator/controllers/utils"
 	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
@@ -12,33 +13,64 @@ import (
 	"github.com/spf13/cobra"
 	"io"
 	"io/ioutil"
-	"strings"
 )
 
 type generateChannelCmd struct {
 	channelName          string
 	organizations        []string
 	ordererOrganizations []string
+	idemixOrganizations  []string
+	signaturePolicies    bool
 	output               string
+	configtxPath         string
+	profileName          string
+	profileFlags         genesisProfileFlags
 }
 
 func (c generateChannelCmd) validate() error {
 	if c.channelName == "" {
 		return errors.Errorf("--channelName is required")
 	}
-	if len(c.ordererOrganizations) == 0 {
+	if c.configtxPath != "" && c.profileName == "" {
+		return errors.Errorf("--profile is required with --configtx")
+	}
+	if len(c.ordererOrganizations) == 0 && c.configtxPath == "" {
 		return errors.Errorf("--ordererOrganizations is required")
 	}
-	if len(c.organizations) == 0 {
+	if len(c.organizations) == 0 && c.configtxPath == "" {
 		return errors.Errorf("--organizations is required")
 	}
 	if c.output == "" {
 		return errors.Errorf("--output is required")
 	}
+	for _, idemixOrg := range c.idemixOrganizations {
+		if _, _, err := parseIdemixOrganization(idemixOrg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (c generateChannelCmd) run() error {
+	profile := &testutils.ChannelProfile{}
+	if c.configtxPath != "" {
+		var err error
+		profile, err = testutils.LoadConfigtxProfile(c.configtxPath, c.profileName)
+		if err != nil {
+			return err
+		}
+		// the organizations of the profile are used unless they are set explicitly
+		if len(c.organizations) == 0 {
+			c.organizations = profile.ApplicationOrganizations
+		}
+		if len(c.ordererOrganizations) == 0 {
+			c.ordererOrganizations = profile.OrdererOrganizations
+		}
+	}
+	err := c.profileFlags.apply(profile)
+	if err != nil {
+		return err
+	}
 	oclient, err := helpers.GetKubeOperatorClient()
 	if err != nil {
 		return err
@@ -84,19 +116,23 @@ func (c generateChannelCmd) run() error {
 	var ordererOrgs []testutils.OrdererOrg
 	for mspID, orderers := range ordererMap {
 		orderer := orderers[0]
-		certAuth, err := helpers.GetCertAuthByURL(
-			oclient,
-			orderer.Spec.Secret.Enrollment.Component.Cahost,
-			orderer.Spec.Secret.Enrollment.Component.Caport,
-		)
+		mspCerts, err := helpers.GetOrdererNodeMSPCerts(oclient, orderer)
+		if err != nil {
+			return err
+		}
+		tlsCert, err := utils.ParseX509Certificate([]byte(mspCerts.TLSCACert))
+		if err != nil {
+			return err
+		}
+		signCert, err := utils.ParseX509Certificate([]byte(mspCerts.CACert))
 		if err != nil {
 			return err
 		}
-		tlsCert, err := utils.ParseX509Certificate([]byte(certAuth.Status.TLSCACert))
+		tlsIntermediateCerts, err := certs.ParseCertificates([]byte(mspCerts.TLSCAIntermediateCerts))
 		if err != nil {
 			return err
 		}
-		signCert, err := utils.ParseX509Certificate([]byte(certAuth.Status.CACert))
+		signIntermediateCerts, err := certs.ParseCertificates([]byte(mspCerts.CAIntermediateCerts))
 		if err != nil {
 			return err
 		}
@@ -111,6 +147,8 @@ func (c generateChannelCmd) run() error {
 			mspID,
 			tlsCert,
 			signCert,
+			tlsIntermediateCerts,
+			signIntermediateCerts,
 			ordererUrls,
 		))
 	}
@@ -123,20 +161,23 @@ func (c generateChannelCmd) run() error {
 		if !utils.Contains(c.organizations, peer.Spec.MspID) {
 			continue
 		}
-		caHost := strings.Split(peer.Spec.Secret.Enrollment.Component.Cahost, ".")[0]
-		certAuth, err := helpers.GetCertAuthByURL(
-			oclient,
-			caHost,
-			peer.Spec.Secret.Enrollment.Component.Caport,
-		)
+		mspCerts, err := helpers.GetPeerMSPCerts(oclient, peer, helpers.GetCertAuthByComponent)
+		if err != nil {
+			return err
+		}
+		rootCert, err := utils.ParseX509Certificate([]byte(mspCerts.CACert))
+		if err != nil {
+			return err
+		}
+		tlsRootCert, err := utils.ParseX509Certificate([]byte(mspCerts.TLSCACert))
 		if err != nil {
 			return err
 		}
-		rootCert, err := utils.ParseX509Certificate([]byte(certAuth.Status.CACert))
+		tlsIntermediateCerts, err := certs.ParseCertificates([]byte(mspCerts.TLSCAIntermediateCerts))
 		if err != nil {
 			return err
 		}
-		tlsRootCert, err := utils.ParseX509Certificate([]byte(certAuth.Status.TLSCACert))
+		intermediateCerts, err := certs.ParseCertificates([]byte(mspCerts.CAIntermediateCerts))
 		if err != nil {
 			return err
 		}
@@ -144,18 +185,39 @@ func (c generateChannelCmd) run() error {
 			peer.Spec.MspID,
 			tlsRootCert,
 			rootCert,
+			tlsIntermediateCerts,
+			intermediateCerts,
 		))
 	}
+	var idemixOrgs []testutils.IdemixOrg
+	for _, idemixOrg := range c.idemixOrganizations {
+		mspID, caName, err := parseIdemixOrganization(idemixOrg)
+		if err != nil {
+			return err
+		}
+		ipk, revocationPk, err := getIdemixIssuerKeys(oclient, caName)
+		if err != nil {
+			return err
+		}
+		idemixOrgs = append(idemixOrgs, testutils.CreateIdemixOrg(mspID, ipk, revocationPk))
+	}
 	log.Infof("Peer organizations=%v", peerOrgs)
 	log.Infof("Orderer organizations=%v", ordererOrgs)
 
-	block, err := chStore.GetApplicationChannelBlock(
-		ctx,
+	options := []testutils.ChannelOption{
 		testutils.WithName(c.channelName),
 		testutils.WithOrdererOrgs(ordererOrgs...),
 		testutils.WithPeerOrgs(peerOrgs...),
 		testutils.WithConsenters(consenters...),
-	)
+		testutils.WithIdemixOrgs(idemixOrgs...),
+		testutils.WithProfile(*profile),
+	}
+	if c.signaturePolicies {
+		options = append(options, testutils.WithEndorsementSignaturePolicies())
+	} else if len(idemixOrgs) > 0 {
+		warnIdemixEndorsementPolicies([]string{"Endorsement", "LifecycleEndorsement"}, "endorsementSignaturePolicies")
+	}
+	block, err := chStore.GetApplicationChannelBlock(ctx, options...)
 	if err != nil {
 		return err
 	}
@@ -186,9 +248,12 @@ func newGenerateChannelCMD(io.Writer, io.Writer) *cobra.Command {
 	persistentFlags.StringVarP(&c.output, "output", "o", "", "Output block")
 	persistentFlags.StringSliceVarP(&c.organizations, "organizations", "p", nil, "Organizations belonging to the channel")
 	persistentFlags.StringSliceVarP(&c.ordererOrganizations, "ordererOrganizations", "", nil, "Orderer organizations belonging to the channel")
+	persistentFlags.StringSliceVarP(&c.idemixOrganizations, "idemixOrganizations", "", nil, "Idemix organizations belonging to the channel, in the format MSP_ID=CA_NAME.CA_NAMESPACE")
+	persistentFlags.BoolVarP(&c.signaturePolicies, "endorsementSignaturePolicies", "", false, "Replace the implicit meta Endorsement and LifecycleEndorsement policies, which count the Idemix organizations, by signature policies over the organizations able to endorse")
+	persistentFlags.StringVarP(&c.configtxPath, "configtx", "", "", "configtx.yaml with the profile of the channel, its organizations are used when --organizations and --ordererOrganizations are empty")
+	persistentFlags.StringVarP(&c.profileName, "profile", "", "", "Profile of the configtx.yaml")
+	c.profileFlags.addFlags(persistentFlags)
 	cmd.MarkPersistentFlagRequired("name")
-	cmd.MarkPersistentFlagRequired("organizations")
-	cmd.MarkPersistentFlagRequired("ordererOrganizations")
 	cmd.MarkPersistentFlagRequired("output")
 	return cmd
 }
@@ -0,0 +1,63 @@
+package channel
+
+import (
+	"encoding/base64"
+	"strings"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	operatorv1 "github.com/kfsoftware/hlf-operator/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// endorsementSignaturePoliciesFlag is the flag of addorg and delorg replacing the endorsement policies of a channel
+// with Idemix orgs by signature policies
+const endorsementSignaturePoliciesFlag = "endorsement-signature-policies"
+
+// parseIdemixOrganization parses an Idemix organization in the format MSP_ID=CA_NAME.CA_NAMESPACE
+func parseIdemixOrganization(idemixOrg string) (string, string, error) {
+	parts := strings.SplitN(idemixOrg, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid Idemix organization %s, expected MSP_ID=CA_NAME.CA_NAMESPACE", idemixOrg)
+	}
+	return parts[0], parts[1], nil
+}
+
+// getIdemixIssuerKeys returns the Idemix issuer public key and revocation public key of a FabricCA
+func getIdemixIssuerKeys(oclient *operatorv1.Clientset, caName string) ([]byte, []byte, error) {
+	certAuth, err := helpers.GetCertAuthByFullName(oclient, caName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if certAuth.Status.IdemixIssuerPublicKey == "" {
+		return nil, nil, errors.Errorf("CA %s doesn't have an Idemix issuer, set spec.idemix", caName)
+	}
+	ipk, err := base64.StdEncoding.DecodeString(certAuth.Status.IdemixIssuerPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ipk, []byte(certAuth.Status.IdemixRevocationPublicKey), nil
+}
+
+// warnIdemixEndorsementPolicies warns that the endorsement policies don't account for the Idemix orgs of the channel
+func warnIdemixEndorsementPolicies(policyNames []string, flag string) {
+	for _, policyName := range policyNames {
+		log.Warnf(
+			"The %s policy of the channel counts the Idemix organizations, which can't endorse, use --%s to replace it by a signature policy over the organizations able to endorse",
+			policyName,
+			flag,
+		)
+	}
+}
+
+// updateIdemixEndorsementPolicies replaces the endorsement policies of the application group that count its Idemix
+// orgs by signature policies when signaturePolicies is set, it only warns about them otherwise
+func updateIdemixEndorsementPolicies(previous *cb.ConfigGroup, application *cb.ConfigGroup, signaturePolicies bool) error {
+	if signaturePolicies {
+		return utils.UpdateIdemixEndorsementPolicies(previous, application)
+	}
+	warnIdemixEndorsementPolicies(utils.IdemixEndorsementPolicies(previous, application), endorsementSignaturePoliciesFlag)
+	return nil
+}
@@ -0,0 +1,177 @@
+package channel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-config/protolator/protoext/commonext"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/policies"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/policydsl"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type setPolicyCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	path        string
+	rule        string
+	output      string
+	dryRun      bool
+}
+
+func (c *setPolicyCmd) validate() error {
+	if _, _, err := splitPolicyPath(c.path); err != nil {
+		return err
+	}
+	if _, err := parsePolicyRule(c.rule); err != nil {
+		return err
+	}
+	if c.dryRun && c.output != "" {
+		return errors.Errorf("--dry-run and --output can't be used together")
+	}
+	return nil
+}
+
+func (c *setPolicyCmd) run(out io.Writer) error {
+	sdk, resClient, err := newAdminResClient(c.configPath, c.peer, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	channelID := c.channelName
+	channelConfig, err := GetCurrentConfigFromPeer(resClient, channelID)
+	if err != nil {
+		return err
+	}
+	modifiedConfig := proto.Clone(channelConfig).(*common.Config)
+	groupPath, policyName, err := splitPolicyPath(c.path)
+	if err != nil {
+		return err
+	}
+	group := modifiedConfig.ChannelGroup
+	for _, groupName := range groupPath {
+		var ok bool
+		group, ok = group.Groups[groupName]
+		if !ok {
+			return errors.Errorf("group %s of %s not found in channel %s", groupName, c.path, channelID)
+		}
+	}
+	policy, err := parsePolicyRule(c.rule)
+	if err != nil {
+		return err
+	}
+	configPolicy, ok := group.Policies[policyName]
+	if !ok {
+		configPolicy = &common.ConfigPolicy{ModPolicy: channelconfig.AdminsPolicyKey}
+		group.Policies[policyName] = configPolicy
+	}
+	_, err = fmt.Fprintf(out, "Before:\n")
+	if err != nil {
+		return err
+	}
+	err = writePolicyTree(out, configPolicy.Policy)
+	if err != nil {
+		return err
+	}
+	configPolicy.Policy = policy
+	_, err = fmt.Fprintf(out, "After:\n")
+	if err != nil {
+		return err
+	}
+	err = writePolicyTree(out, configPolicy.Policy)
+	if err != nil {
+		return err
+	}
+	return applyConfigUpdate(out, resClient, channelID, channelConfig, modifiedConfig, c.dryRun, c.output)
+}
+
+// splitPolicyPath splits a policy path like /Channel/Application/Writers into the groups below the channel group
+// and the name of the policy
+func splitPolicyPath(path string) ([]string, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "Channel" {
+		return nil, "", errors.Errorf("invalid policy path %s, expected /Channel/[GROUP/...]POLICY", path)
+	}
+	return parts[1 : len(parts)-1], parts[len(parts)-1], nil
+}
+
+// parsePolicyRule parses an implicit meta rule like "MAJORITY Admins" or a signature policy like "OR('Org1MSP.member')"
+func parsePolicyRule(rule string) (*common.Policy, error) {
+	if implicitMetaPolicy, err := policies.ImplicitMetaFromString(rule); err == nil {
+		value, err := proto.Marshal(implicitMetaPolicy)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Policy{
+			Type:  int32(common.Policy_IMPLICIT_META),
+			Value: value,
+		}, nil
+	}
+	signaturePolicy, err := policydsl.FromString(rule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy rule %s", rule)
+	}
+	value, err := proto.Marshal(signaturePolicy)
+	if err != nil {
+		return nil, err
+	}
+	return &common.Policy{
+		Type:  int32(common.Policy_SIGNATURE),
+		Value: value,
+	}, nil
+}
+
+func writePolicyTree(out io.Writer, policy *common.Policy) error {
+	if policy == nil {
+		_, err := fmt.Fprintln(out, "null")
+		return err
+	}
+	return protolator.DeepMarshalJSON(out, &commonext.Policy{Policy: policy})
+}
+
+func newPolicyCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage the policies of a channel",
+	}
+	cmd.AddCommand(newSetPolicyCMD(out, errOut))
+	return cmd
+}
+
+func newSetPolicyCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &setPolicyCmd{}
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set a policy of the channel config",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "", "", "Admin org to invoke the updates")
+	persistentFlags.StringVarP(&c.channelName, "channel", "", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
+	persistentFlags.StringVarP(&c.path, "path", "", "", "Path of the policy, e.g. /Channel/Application/Writers")
+	persistentFlags.StringVarP(&c.rule, "rule", "", "", "Signature policy, e.g. OR('Org1MSP.member'), or implicit meta policy, e.g. MAJORITY Admins")
+	persistentFlags.StringVarP(&c.output, "output", "o", "", "Save the config update envelope in this file instead of submitting it")
+	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Print the diff of the config and not update")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	cmd.MarkPersistentFlagRequired("path")
+	cmd.MarkPersistentFlagRequired("rule")
+	return cmd
+}
@@ -0,0 +1,172 @@
+package channel
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/policies"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/policydsl"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// genesisProfileFlags are the flags of channel generate overriding the defaults and the configtx.yaml profile
+type genesisProfileFlags struct {
+	batchTimeout            time.Duration
+	maxMessageCount         uint32
+	absoluteMaxBytes        string
+	preferredMaxBytes       string
+	tickInterval            string
+	electionTick            uint32
+	heartbeatTick           uint32
+	maxInflightBlocks       uint32
+	snapshotIntervalSize    string
+	ordererCapabilities     []string
+	applicationCapabilities []string
+	channelCapabilities     []string
+	policies                []string
+	acls                    []string
+}
+
+func (f *genesisProfileFlags) addFlags(flags *pflag.FlagSet) {
+	flags.DurationVarP(&f.batchTimeout, "batch-timeout", "", 0, "Time to wait before creating a batch")
+	flags.Uint32VarP(&f.maxMessageCount, "max-message-count", "", 0, "Maximum number of messages in a batch")
+	flags.StringVarP(&f.absoluteMaxBytes, "absolute-max-bytes", "", "", "Absolute maximum size of the messages in a batch, e.g. 10 MB")
+	flags.StringVarP(&f.preferredMaxBytes, "preferred-max-bytes", "", "", "Preferred maximum size of the messages in a batch, e.g. 512 KB")
+	flags.StringVarP(&f.tickInterval, "tick-interval", "", "", "Raft tick interval, e.g. 500ms")
+	flags.Uint32VarP(&f.electionTick, "election-tick", "", 0, "Raft election tick")
+	flags.Uint32VarP(&f.heartbeatTick, "heartbeat-tick", "", 0, "Raft heartbeat tick")
+	flags.Uint32VarP(&f.maxInflightBlocks, "max-inflight-blocks", "", 0, "Raft maximum number of inflight blocks")
+	flags.StringVarP(&f.snapshotIntervalSize, "snapshot-interval-size", "", "", "Raft snapshot interval size, e.g. 16 MB")
+	flags.StringSliceVarP(&f.ordererCapabilities, "orderer-capabilities", "", nil, "Capabilities of the orderer section, e.g. V2_0")
+	flags.StringSliceVarP(&f.applicationCapabilities, "application-capabilities", "", nil, "Capabilities of the application section, e.g. V2_0")
+	flags.StringSliceVarP(&f.channelCapabilities, "channel-capabilities", "", nil, "Capabilities of the channel, e.g. V2_0")
+	flags.StringArrayVarP(&f.policies, "policy", "", nil, "Policy in the format PATH=RULE, e.g. /Channel/Application/Org1MSP/Endorsement=OR('Org1MSP.peer') or /Channel/Application/Admins=ANY Admins")
+	flags.StringArrayVarP(&f.acls, "acl", "", nil, "ACL in the format RESOURCE=POLICY, e.g. qscc/GetChainInfo=/Channel/Application/Writers")
+}
+
+// apply sets the flags in the profile, the flags that aren't set keep the values of the profile
+func (f *genesisProfileFlags) apply(profile *testutils.ChannelProfile) error {
+	var err error
+	if f.batchTimeout != 0 {
+		profile.BatchTimeout = f.batchTimeout
+	}
+	if f.maxMessageCount != 0 {
+		profile.BatchSize.MaxMessageCount = f.maxMessageCount
+	}
+	if f.absoluteMaxBytes != "" {
+		profile.BatchSize.AbsoluteMaxBytes, err = testutils.ParseByteSize(f.absoluteMaxBytes)
+		if err != nil {
+			return err
+		}
+	}
+	if f.preferredMaxBytes != "" {
+		profile.BatchSize.PreferredMaxBytes, err = testutils.ParseByteSize(f.preferredMaxBytes)
+		if err != nil {
+			return err
+		}
+	}
+	if f.tickInterval != "" {
+		if _, err := time.ParseDuration(f.tickInterval); err != nil {
+			return errors.Wrapf(err, "invalid --tick-interval %s", f.tickInterval)
+		}
+		profile.EtcdRaftOptions.TickInterval = f.tickInterval
+	}
+	if f.electionTick != 0 {
+		profile.EtcdRaftOptions.ElectionTick = f.electionTick
+	}
+	if f.heartbeatTick != 0 {
+		profile.EtcdRaftOptions.HeartbeatTick = f.heartbeatTick
+	}
+	if f.maxInflightBlocks != 0 {
+		profile.EtcdRaftOptions.MaxInflightBlocks = f.maxInflightBlocks
+	}
+	if f.snapshotIntervalSize != "" {
+		profile.EtcdRaftOptions.SnapshotIntervalSize, err = testutils.ParseByteSize(f.snapshotIntervalSize)
+		if err != nil {
+			return err
+		}
+	}
+	if f.ordererCapabilities != nil {
+		profile.OrdererCapabilities = f.ordererCapabilities
+	}
+	if f.applicationCapabilities != nil {
+		profile.ApplicationCapabilities = f.applicationCapabilities
+	}
+	if f.channelCapabilities != nil {
+		profile.ChannelCapabilities = f.channelCapabilities
+	}
+	for _, policyFlag := range f.policies {
+		err = setProfilePolicy(profile, policyFlag)
+		if err != nil {
+			return err
+		}
+	}
+	for _, aclFlag := range f.acls {
+		parts := strings.SplitN(aclFlag, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errors.Errorf("invalid ACL %s, expected RESOURCE=POLICY", aclFlag)
+		}
+		if profile.ACLs == nil {
+			profile.ACLs = map[string]string{}
+		}
+		profile.ACLs[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+// setProfilePolicy sets a policy in the format PATH=RULE in the section of the profile of the path
+func setProfilePolicy(profile *testutils.ChannelProfile, policyFlag string) error {
+	parts := strings.SplitN(policyFlag, "=", 2)
+	if len(parts) != 2 {
+		return errors.Errorf("invalid policy %s, expected PATH=RULE", policyFlag)
+	}
+	groupPath, policyName, err := splitPolicyPath(parts[0])
+	if err != nil {
+		return err
+	}
+	policy, err := configtxPolicy(parts[1])
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(groupPath) == 0:
+		profile.ChannelPolicies = withPolicy(profile.ChannelPolicies, policyName, policy)
+	case len(groupPath) == 1 && groupPath[0] == channelconfig.ApplicationGroupKey:
+		profile.ApplicationPolicies = withPolicy(profile.ApplicationPolicies, policyName, policy)
+	case len(groupPath) == 1 && groupPath[0] == channelconfig.OrdererGroupKey:
+		profile.OrdererPolicies = withPolicy(profile.OrdererPolicies, policyName, policy)
+	case len(groupPath) == 2 && (groupPath[0] == channelconfig.ApplicationGroupKey || groupPath[0] == channelconfig.OrdererGroupKey):
+		if profile.OrganizationPolicies == nil {
+			profile.OrganizationPolicies = map[string]map[string]configtx.Policy{}
+		}
+		mspID := groupPath[1]
+		profile.OrganizationPolicies[mspID] = withPolicy(profile.OrganizationPolicies[mspID], policyName, policy)
+	default:
+		return errors.Errorf("invalid policy path %s", parts[0])
+	}
+	return nil
+}
+
+func withPolicy(policyMap map[string]configtx.Policy, name string, policy configtx.Policy) map[string]configtx.Policy {
+	if policyMap == nil {
+		policyMap = map[string]configtx.Policy{}
+	}
+	policyMap[name] = policy
+	return policyMap
+}
+
+// configtxPolicy returns the implicit meta policy of rules like "MAJORITY Admins" or the signature policy of rules
+// like "OR('Org1MSP.member')"
+func configtxPolicy(rule string) (configtx.Policy, error) {
+	if _, err := policies.ImplicitMetaFromString(rule); err == nil {
+		return configtx.Policy{Type: configtx.ImplicitMetaPolicyType, Rule: rule}, nil
+	}
+	if _, err := policydsl.FromString(rule); err != nil {
+		return configtx.Policy{}, errors.Wrapf(err, "invalid policy rule %s", rule)
+	}
+	return configtx.Policy{Type: configtx.SignaturePolicyType, Rule: rule}, nil
+}
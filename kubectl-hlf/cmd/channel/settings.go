@@ -0,0 +1,247 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	operatorv1 "github.com/kfsoftware/hlf-operator/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+type channelSettingsCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	output      string
+	dryRun      bool
+
+	batchTimeout            time.Duration
+	maxMessageCount         uint32
+	absoluteMaxBytes        uint32
+	preferredMaxBytes       uint32
+	tickInterval            string
+	electionTick            uint32
+	heartbeatTick           uint32
+	maxInflightBlocks       uint32
+	snapshotIntervalSize    uint32
+	ordererCapabilities     []string
+	applicationCapabilities []string
+	channelCapabilities     []string
+}
+
+func (c *channelSettingsCmd) validate() error {
+	if c.dryRun && c.output != "" {
+		return errors.Errorf("--dry-run and --output can't be used together")
+	}
+	if c.tickInterval != "" {
+		if _, err := time.ParseDuration(c.tickInterval); err != nil {
+			return errors.Wrapf(err, "invalid --tick-interval %s", c.tickInterval)
+		}
+	}
+	return nil
+}
+
+func (c *channelSettingsCmd) settings(cmd *cobra.Command) testutils.ChannelSettings {
+	settings := testutils.ChannelSettings{
+		BatchTimeout:         c.batchTimeout,
+		MaxMessageCount:      c.maxMessageCount,
+		AbsoluteMaxBytes:     c.absoluteMaxBytes,
+		PreferredMaxBytes:    c.preferredMaxBytes,
+		TickInterval:         c.tickInterval,
+		ElectionTick:         c.electionTick,
+		HeartbeatTick:        c.heartbeatTick,
+		MaxInflightBlocks:    c.maxInflightBlocks,
+		SnapshotIntervalSize: c.snapshotIntervalSize,
+	}
+	// an empty list of capabilities is only applied when the flag is set
+	flags := cmd.Flags()
+	if flags.Changed("orderer-capabilities") {
+		settings.OrdererCapabilities = append([]string{}, c.ordererCapabilities...)
+	}
+	if flags.Changed("application-capabilities") {
+		settings.ApplicationCapabilities = append([]string{}, c.applicationCapabilities...)
+	}
+	if flags.Changed("channel-capabilities") {
+		settings.ChannelCapabilities = append([]string{}, c.channelCapabilities...)
+	}
+	return settings
+}
+
+func (c *channelSettingsCmd) run(out io.Writer, settings testutils.ChannelSettings) error {
+	sdk, resClient, err := newAdminResClient(c.configPath, c.peer, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	channelID := c.channelName
+	channelConfig, err := GetCurrentConfigFromPeer(resClient, channelID)
+	if err != nil {
+		return err
+	}
+	modifiedConfig := proto.Clone(channelConfig).(*common.Config)
+	err = testutils.ApplyChannelSettings(modifiedConfig, settings)
+	if err != nil {
+		return err
+	}
+	err = checkNodeVersions(channelConfig, settings)
+	if err != nil {
+		return err
+	}
+	return applyConfigUpdate(out, resClient, channelID, channelConfig, modifiedConfig, c.dryRun, c.output)
+}
+
+// checkNodeVersions checks that the orderers and peers in the channel report a Fabric version supporting the
+// capabilities enabled by the settings, the channel capabilities apply to both
+func checkNodeVersions(channelConfig *common.Config, settings testutils.ChannelSettings) error {
+	channelGroup := channelConfig.ChannelGroup
+	ordererGroup := channelGroup.Groups[channelconfig.OrdererGroupKey]
+	applicationGroup := channelGroup.Groups[channelconfig.ApplicationGroupKey]
+	channelCapabilities, err := testutils.AddedCapabilities(channelGroup, settings.ChannelCapabilities)
+	if err != nil {
+		return err
+	}
+	ordererCapabilities, err := testutils.AddedCapabilities(ordererGroup, settings.OrdererCapabilities)
+	if err != nil {
+		return err
+	}
+	ordererCapabilities = append(ordererCapabilities, channelCapabilities...)
+	var peerCapabilities []string
+	if applicationGroup != nil {
+		peerCapabilities, err = testutils.AddedCapabilities(applicationGroup, settings.ApplicationCapabilities)
+		if err != nil {
+			return err
+		}
+		peerCapabilities = append(peerCapabilities, channelCapabilities...)
+	}
+	if len(ordererCapabilities) == 0 && len(peerCapabilities) == 0 {
+		return nil
+	}
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return err
+	}
+	clientSet, err := helpers.GetKubeClient()
+	if err != nil {
+		return err
+	}
+	if len(ordererCapabilities) > 0 {
+		err = checkOrdererVersions(oclient, clientSet, ordererGroup, ordererCapabilities)
+		if err != nil {
+			return err
+		}
+	}
+	if len(peerCapabilities) > 0 {
+		err = checkPeerVersions(oclient, clientSet, applicationGroup, peerCapabilities)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseName returns the name of the release of a node of the cluster from its full name
+func releaseName(fullName string, ns string) string {
+	return strings.TrimSuffix(fullName, "."+ns)
+}
+
+func checkOrdererVersions(oclient *operatorv1.Clientset, clientSet kubernetes.Interface, ordererGroup *common.ConfigGroup, capabilities []string) error {
+	ctx := context.Background()
+	ordererNodes, err := helpers.GetClusterOrdererNodes(oclient, "")
+	if err != nil {
+		return err
+	}
+	var nodes []testutils.NodeVersion
+	for _, ordererNode := range ordererNodes {
+		if _, ok := ordererGroup.Groups[ordererNode.Spec.MspID]; !ok {
+			continue
+		}
+		version, err := utils.GetNodeVersion(ctx, clientSet, ordererNode.Namespace, releaseName(ordererNode.Name, ordererNode.Namespace))
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, testutils.NodeVersion{
+			Name:    fmt.Sprintf("orderer %s", ordererNode.Name),
+			MSPID:   ordererNode.Spec.MspID,
+			Version: version,
+		})
+	}
+	return testutils.CheckNodeVersions(ordererGroup, capabilities, nodes)
+}
+
+func checkPeerVersions(oclient *operatorv1.Clientset, clientSet kubernetes.Interface, applicationGroup *common.ConfigGroup, capabilities []string) error {
+	ctx := context.Background()
+	_, peers, err := helpers.GetClusterPeers(oclient, "")
+	if err != nil {
+		return err
+	}
+	var nodes []testutils.NodeVersion
+	for _, peer := range peers {
+		if _, ok := applicationGroup.Groups[peer.Spec.MspID]; !ok {
+			continue
+		}
+		version, err := utils.GetNodeVersion(ctx, clientSet, peer.Namespace, releaseName(peer.Name, peer.Namespace))
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, testutils.NodeVersion{
+			Name:    fmt.Sprintf("peer %s", peer.Name),
+			MSPID:   peer.Spec.MspID,
+			Version: version,
+		})
+	}
+	return testutils.CheckNodeVersions(applicationGroup, capabilities, nodes)
+}
+
+func newChannelSettingsCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &channelSettingsCmd{}
+	cmd := &cobra.Command{
+		Use:   "settings",
+		Short: "Update the batch, raft and capability settings of a channel",
+		Long: `Update the batch, raft and capability settings of a channel, the settings that aren't set keep their value.
+New capabilities are only enabled when every orderer and peer in the channel reports, through the /version endpoint
+of its operations service, a Fabric version supporting them. Organizations of the channel without nodes in the
+cluster can't be checked and block new capabilities.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out, c.settings(cmd))
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "", "", "Admin org to invoke the updates")
+	persistentFlags.StringVarP(&c.channelName, "channel", "", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
+	persistentFlags.StringVarP(&c.output, "output", "o", "", "Save the config update envelope in this file instead of submitting it")
+	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Print the diff of the config and not update")
+	persistentFlags.DurationVarP(&c.batchTimeout, "batch-timeout", "", 0, "Time to wait before creating a batch")
+	persistentFlags.Uint32VarP(&c.maxMessageCount, "max-message-count", "", 0, "Maximum number of messages in a batch")
+	persistentFlags.Uint32VarP(&c.absoluteMaxBytes, "absolute-max-bytes", "", 0, "Absolute maximum number of bytes of the messages in a batch")
+	persistentFlags.Uint32VarP(&c.preferredMaxBytes, "preferred-max-bytes", "", 0, "Preferred maximum number of bytes of the messages in a batch")
+	persistentFlags.StringVarP(&c.tickInterval, "tick-interval", "", "", "Raft tick interval, e.g. 500ms")
+	persistentFlags.Uint32VarP(&c.electionTick, "election-tick", "", 0, "Raft election tick")
+	persistentFlags.Uint32VarP(&c.heartbeatTick, "heartbeat-tick", "", 0, "Raft heartbeat tick")
+	persistentFlags.Uint32VarP(&c.maxInflightBlocks, "max-inflight-blocks", "", 0, "Raft maximum number of inflight blocks")
+	persistentFlags.Uint32VarP(&c.snapshotIntervalSize, "snapshot-interval-size", "", 0, "Raft snapshot interval size in bytes")
+	persistentFlags.StringSliceVarP(&c.ordererCapabilities, "orderer-capabilities", "", nil, "Capabilities of the orderer section, e.g. V2_0")
+	persistentFlags.StringSliceVarP(&c.applicationCapabilities, "application-capabilities", "", nil, "Capabilities of the application section, e.g. V2_0")
+	persistentFlags.StringSliceVarP(&c.channelCapabilities, "channel-capabilities", "", nil, "Capabilities of the channel, e.g. V2_0")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	return cmd
+}
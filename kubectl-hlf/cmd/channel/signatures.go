@@ -0,0 +1,219 @@
+package channel
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	fabricchannelconfig "github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	bccsputils "github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/pkg/errors"
+)
+
+// identity is the format of the users written by kubectl hlf ca enroll
+type identity struct {
+	Cert pem `json:"cert"`
+	Key  pem `json:"key"`
+}
+type pem struct {
+	Pem string
+}
+
+// readConfigUpdateEnvelope reads a config update envelope written by channel update --output
+func readConfigUpdateEnvelope(path string) (*common.ConfigUpdateEnvelope, *common.ConfigUpdate, error) {
+	envelopeBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope := &common.Envelope{}
+	err = proto.Unmarshal(envelopeBytes, envelope)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s is not a config update envelope", path)
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s is not a config update envelope", path)
+	}
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	err = proto.Unmarshal(payload.Data, configUpdateEnvelope)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s is not a config update envelope", path)
+	}
+	configUpdate := &common.ConfigUpdate{}
+	err = proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s doesn't have a valid config update", path)
+	}
+	return configUpdateEnvelope, configUpdate, nil
+}
+
+// writeConfigUpdateEnvelope writes the config update envelope in the format of channel update --output
+func writeConfigUpdateEnvelope(path string, configUpdateEnvelope *common.ConfigUpdateEnvelope) error {
+	configUpdateEnvelopeBytes, err := proto.Marshal(configUpdateEnvelope)
+	if err != nil {
+		return err
+	}
+	payloadBytes, err := proto.Marshal(&common.Payload{
+		Data: configUpdateEnvelopeBytes,
+	})
+	if err != nil {
+		return err
+	}
+	envelopeBytes, err := proto.Marshal(&common.Envelope{
+		Payload: payloadBytes,
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, envelopeBytes, 0644)
+}
+
+// signConfigUpdate returns the signature of the config update by the identity, as the peer CLI signs config updates
+func signConfigUpdate(configUpdate []byte, mspID string, id identity) (*common.ConfigSignature, error) {
+	cert, err := utils.ParseX509Certificate([]byte(id.Cert.Pem))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid certificate of the identity")
+	}
+	key, err := utils.ParseECDSAPrivateKey([]byte(id.Key.Pem))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid private key of the identity")
+	}
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: utils.EncodeX509Certificate(cert),
+	})
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 24)
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{
+		Creator: creator,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(append(append([]byte{}, signatureHeader...), configUpdate...))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	// fabric only accepts signatures with a low S
+	s, err = bccsputils.ToLowS(&key.PublicKey, s)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := bccsputils.MarshalECDSASignature(r, s)
+	if err != nil {
+		return nil, err
+	}
+	return &common.ConfigSignature{
+		SignatureHeader: signatureHeader,
+		Signature:       signature,
+	}, nil
+}
+
+// signerMSPIDs returns the MSP IDs of the signers of the config update
+func signerMSPIDs(configUpdateEnvelope *common.ConfigUpdateEnvelope) ([]string, error) {
+	var mspIDs []string
+	for _, configSignature := range configUpdateEnvelope.Signatures {
+		signatureHeader, err := protoutil.UnmarshalSignatureHeader(configSignature.SignatureHeader)
+		if err != nil {
+			return nil, err
+		}
+		serializedIdentity := &msp.SerializedIdentity{}
+		err = proto.Unmarshal(signatureHeader.Creator, serializedIdentity)
+		if err != nil {
+			return nil, err
+		}
+		mspIDs = append(mspIDs, serializedIdentity.Mspid)
+	}
+	return mspIDs, nil
+}
+
+// verifyConfigUpdate validates the config update against the policies of the channel config as the orderer does,
+// when the signatures don't satisfy them the error lists the organizations whose admins haven't signed it yet
+func verifyConfigUpdate(channelConfig *common.Config, channelID string, configUpdateEnvelope *common.ConfigUpdateEnvelope, configUpdate *common.ConfigUpdate) error {
+	bundle, err := fabricchannelconfig.NewBundle(channelID, channelConfig, factory.GetDefault())
+	if err != nil {
+		return errors.Wrap(err, "failed to load the channel config")
+	}
+	envelope, err := protoutil.CreateSignedEnvelope(common.HeaderType_CONFIG_UPDATE, channelID, nil, configUpdateEnvelope, 0, 0)
+	if err != nil {
+		return err
+	}
+	_, validationErr := bundle.ConfigtxValidator().ProposeConfigUpdate(envelope)
+	if validationErr == nil {
+		return nil
+	}
+	signedData, err := protoutil.ConfigUpdateEnvelopeAsSignedData(configUpdateEnvelope)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, section := range updatedSections(configUpdate) {
+		group, ok := channelConfig.ChannelGroup.Groups[section]
+		if !ok {
+			continue
+		}
+		var orgs []string
+		for org := range group.Groups {
+			orgs = append(orgs, org)
+		}
+		sort.Strings(orgs)
+		for _, org := range orgs {
+			policyPath := fmt.Sprintf("/%s/%s/%s/%s", fabricchannelconfig.ChannelGroupKey, section, org, channelconfig.AdminsPolicyKey)
+			policy, ok := bundle.PolicyManager().GetPolicy(policyPath)
+			if !ok {
+				continue
+			}
+			if policy.EvaluateSignedData(signedData) != nil {
+				missing = append(missing, fmt.Sprintf("%s (%s)", org, section))
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return errors.Wrap(validationErr, "the config update is not valid")
+	}
+	return errors.Wrapf(
+		validationErr,
+		"the signatures don't satisfy the channel policies, admins of %s haven't signed the update",
+		strings.Join(missing, ", "),
+	)
+}
+
+// updatedSections returns the sections of the channel the config update modifies, the changes of the channel
+// group itself involve both the application and the orderer organizations
+func updatedSections(configUpdate *common.ConfigUpdate) []string {
+	allSections := []string{channelconfig.ApplicationGroupKey, channelconfig.OrdererGroupKey}
+	writeSet := configUpdate.WriteSet
+	if writeSet == nil {
+		return allSections
+	}
+	readSet := configUpdate.ReadSet
+	if readSet == nil || readSet.Version != writeSet.Version || len(writeSet.Values) > 0 || len(writeSet.Policies) > 0 {
+		return allSections
+	}
+	var sections []string
+	for _, section := range allSections {
+		if _, ok := writeSet.Groups[section]; ok {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
@@ -0,0 +1,88 @@
+package channel
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+type signUpdateCmd struct {
+	updatePath string
+	identity   string
+	mspID      string
+	output     string
+}
+
+func (c *signUpdateCmd) validate() error {
+	if c.identity == "" {
+		return errors.Errorf("--identity is required")
+	}
+	if c.mspID == "" {
+		return errors.Errorf("--mspid is required")
+	}
+	return nil
+}
+
+func (c *signUpdateCmd) run(out io.Writer) error {
+	configUpdateEnvelope, configUpdate, err := readConfigUpdateEnvelope(c.updatePath)
+	if err != nil {
+		return err
+	}
+	identityBytes, err := ioutil.ReadFile(c.identity)
+	if err != nil {
+		return err
+	}
+	id := identity{}
+	err = yaml.Unmarshal(identityBytes, &id)
+	if err != nil {
+		return err
+	}
+	signature, err := signConfigUpdate(configUpdateEnvelope.ConfigUpdate, c.mspID, id)
+	if err != nil {
+		return err
+	}
+	configUpdateEnvelope.Signatures = append(configUpdateEnvelope.Signatures, signature)
+	output := c.output
+	if output == "" {
+		output = c.updatePath
+	}
+	err = writeConfigUpdateEnvelope(output, configUpdateEnvelope)
+	if err != nil {
+		return err
+	}
+	signers, err := signerMSPIDs(configUpdateEnvelope)
+	if err != nil {
+		return err
+	}
+	log.Infof("Config update of channel %s signed by %s, saved in %s", configUpdate.ChannelId, c.mspID, output)
+	_, err = fmt.Fprintf(out, "Signed by: %v\n", signers)
+	return err
+}
+
+func newSignUpdateCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &signUpdateCmd{}
+	cmd := &cobra.Command{
+		Use:   "signupdate <update.pb>",
+		Short: "Append the signature of an identity to a config update saved by channel update --output",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.updatePath = args[0]
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.identity, "identity", "", "", "Identity file of the signer, as written by kubectl hlf ca enroll")
+	persistentFlags.StringVarP(&c.mspID, "mspid", "", "", "MSP ID of the signer")
+	persistentFlags.StringVarP(&c.output, "output", "o", "", "File to save the signed config update, the input file is overwritten when empty")
+	cmd.MarkPersistentFlagRequired("identity")
+	cmd.MarkPersistentFlagRequired("mspid")
+	return cmd
+}
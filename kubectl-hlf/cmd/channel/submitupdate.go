@@ -0,0 +1,113 @@
+package channel
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type submitUpdateCmd struct {
+	updatePath  string
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+}
+
+func (c *submitUpdateCmd) validate() error {
+	return nil
+}
+
+func (c *submitUpdateCmd) run() error {
+	configUpdateEnvelope, configUpdate, err := readConfigUpdateEnvelope(c.updatePath)
+	if err != nil {
+		return err
+	}
+	channelID := c.channelName
+	if configUpdate.ChannelId != channelID {
+		return errors.Errorf("the config update is for channel %s, not %s", configUpdate.ChannelId, channelID)
+	}
+	if len(configUpdateEnvelope.Signatures) == 0 {
+		return errors.Errorf("the config update isn't signed, sign it with kubectl hlf channel signupdate")
+	}
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return err
+	}
+	peer, err := helpers.GetPeerByFullName(oclient, c.peer)
+	if err != nil {
+		return err
+	}
+	sdk, err := fabsdk.New(config.FromFile(c.configPath))
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	resClient, err := resmgmt.New(sdk.Context(
+		fabsdk.WithUser(c.userName),
+		fabsdk.WithOrg(peer.Spec.MspID),
+	))
+	if err != nil {
+		return err
+	}
+	channelConfig, err := GetCurrentConfigFromPeer(resClient, channelID)
+	if err != nil {
+		return err
+	}
+	err = verifyConfigUpdate(channelConfig, channelID, configUpdateEnvelope, configUpdate)
+	if err != nil {
+		return err
+	}
+	// the envelope is sent as saved, the signatures are computed over its marshaled config update
+	configEnvelopeBytes, err := ioutil.ReadFile(c.updatePath)
+	if err != nil {
+		return err
+	}
+	// the signatures collected with signupdate are sent instead of the one of the submitter
+	txID, err := resClient.SaveChannel(
+		resmgmt.SaveChannelRequest{
+			ChannelID:     channelID,
+			ChannelConfig: bytes.NewReader(configEnvelopeBytes),
+		},
+		resmgmt.WithConfigSignatures(configUpdateEnvelope.Signatures...),
+	)
+	if err != nil {
+		return err
+	}
+	log.Infof("Channel updated, txID=%s", string(txID.TransactionID))
+	return nil
+}
+
+func newSubmitUpdateCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &submitUpdateCmd{}
+	cmd := &cobra.Command{
+		Use:   "submitupdate <update.pb>",
+		Short: "Verify the signatures of a config update against the channel policies and submit it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.updatePath = args[0]
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "", "", "Peer of the organization submitting the update")
+	persistentFlags.StringVarP(&c.channelName, "channel", "", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	return cmd
+}
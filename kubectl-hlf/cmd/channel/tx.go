@@ -0,0 +1,93 @@
+package channel
+
+import (
+	"encoding/json"
+	"io"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type channelTxCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	txID        string
+}
+
+func (c *channelTxCmd) validate() error {
+	if c.txID == "" {
+		return errors.Errorf("transaction ID is required")
+	}
+	return nil
+}
+
+func (c *channelTxCmd) run(out io.Writer) error {
+	sdk, ledgerClient, peerName, err := newLedgerClient(c.configPath, c.peer, c.channelName, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	block, err := ledgerClient.QueryBlockByTxID(fab.TransactionID(c.txID), ledger.WithTargetEndpoints(peerName))
+	if err != nil {
+		return err
+	}
+	index, err := txIndexInBlock(block, c.txID)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(out).Encode(decodeTransaction(block, index))
+}
+
+// txIndexInBlock returns the index of the transaction in the data of the block
+func txIndexInBlock(block *cb.Block, txID string) (int, error) {
+	for i := range block.Data.Data {
+		envelope, err := protoutil.ExtractEnvelope(block, i)
+		if err != nil {
+			return 0, err
+		}
+		channelHeader, err := protoutil.ChannelHeader(envelope)
+		if err != nil {
+			return 0, err
+		}
+		if channelHeader.TxId == txID {
+			return i, nil
+		}
+	}
+	return 0, errors.Errorf("transaction %s not found in block %d", txID, block.Header.Number)
+}
+
+func newChannelTxCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &channelTxCmd{}
+	cmd := &cobra.Command{
+		Use:   "tx <txid>",
+		Short: "Print a transaction of a channel as JSON",
+		Long: `Print a transaction of a channel as a JSON line with its block number, header, validation code, creator,
+chaincode invocation and read/write sets. A transaction that can't be decoded is printed with an error and the
+fields decoded until the error. Arguments and values are printed as UTF-8 text when they are valid UTF-8 and as
+base64 otherwise.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.txID = args[0]
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "p", "", "Peer to query the transaction")
+	persistentFlags.StringVarP(&c.userName, "user", "u", "", "User")
+	persistentFlags.StringVarP(&c.channelName, "channel", "c", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	cmd.MarkPersistentFlagRequired("config")
+	return cmd
+}
@@ -0,0 +1,375 @@
+package channel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxlator/update"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type updateChannelCmd struct {
+	configPath  string
+	peer        string
+	channelName string
+	userName    string
+	patchPath   string
+	sets        []string
+	output      string
+	dryRun      bool
+}
+
+func (c *updateChannelCmd) validate() error {
+	if c.patchPath == "" && len(c.sets) == 0 {
+		return errors.Errorf("--patch or --set is required")
+	}
+	for _, set := range c.sets {
+		if _, _, err := parseSetExpression(set); err != nil {
+			return err
+		}
+	}
+	if c.dryRun && c.output != "" {
+		return errors.Errorf("--dry-run and --output can't be used together")
+	}
+	return nil
+}
+
+func (c *updateChannelCmd) run(out io.Writer) error {
+	sdk, resClient, err := newAdminResClient(c.configPath, c.peer, c.userName)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	channelID := c.channelName
+	channelConfig, err := GetCurrentConfigFromPeer(resClient, channelID)
+	if err != nil {
+		return err
+	}
+	modifiedConfig, err := c.applyChanges(channelConfig)
+	if err != nil {
+		return err
+	}
+	return applyConfigUpdate(out, resClient, channelID, channelConfig, modifiedConfig, c.dryRun, c.output)
+}
+
+// newAdminResClient returns the resource management client of the user of the organization of the peer
+func newAdminResClient(configPath string, peerName string, userName string) (*fabsdk.FabricSDK, *resmgmt.Client, error) {
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	peer, err := helpers.GetPeerByFullName(oclient, peerName)
+	if err != nil {
+		return nil, nil, err
+	}
+	sdk, err := fabsdk.New(config.FromFile(configPath))
+	if err != nil {
+		return nil, nil, err
+	}
+	resClient, err := resmgmt.New(sdk.Context(
+		fabsdk.WithUser(userName),
+		fabsdk.WithOrg(peer.Spec.MspID),
+	))
+	if err != nil {
+		sdk.Close()
+		return nil, nil, err
+	}
+	return sdk, resClient, nil
+}
+
+// applyConfigUpdate prints the diff of the configs and submits the config update signed by the user of the client,
+// or saves it in the output file to collect the signatures with signupdate
+func applyConfigUpdate(out io.Writer, resClient *resmgmt.Client, channelID string, channelConfig *common.Config, modifiedConfig *common.Config, dryRun bool, output string) error {
+	err := writeConfigDiff(out, channelConfig, modifiedConfig, "current", "updated")
+	if err != nil {
+		return err
+	}
+	configUpdate, err := update.Compute(channelConfig, modifiedConfig)
+	if err != nil {
+		return err
+	}
+	configUpdate.ChannelId = channelID
+	if dryRun {
+		return nil
+	}
+	configEnvelopeBytes, err := GetConfigEnvelopeBytes(configUpdate)
+	if err != nil {
+		return err
+	}
+	if output != "" {
+		err = ioutil.WriteFile(output, configEnvelopeBytes, 0644)
+		if err != nil {
+			return err
+		}
+		log.Infof("Config update saved in %s", output)
+		return nil
+	}
+	txID, err := resClient.SaveChannel(resmgmt.SaveChannelRequest{
+		ChannelID:     channelID,
+		ChannelConfig: bytes.NewReader(configEnvelopeBytes),
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Channel updated, txID=%s", string(txID.TransactionID))
+	return nil
+}
+
+// applyChanges applies the JSON patch and the set expressions to the JSON representation of the config
+func (c *updateChannelCmd) applyChanges(channelConfig *common.Config) (*common.Config, error) {
+	var buf bytes.Buffer
+	err := protolator.DeepMarshalJSON(&buf, channelConfig)
+	if err != nil {
+		return nil, err
+	}
+	configJSON := buf.Bytes()
+	if c.patchPath != "" {
+		patchBytes, err := ioutil.ReadFile(c.patchPath)
+		if err != nil {
+			return nil, err
+		}
+		patch, err := jsonpatch.DecodePatch(patchBytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON patch %s", c.patchPath)
+		}
+		configJSON, err = patch.Apply(configJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply JSON patch %s", c.patchPath)
+		}
+	}
+	if len(c.sets) > 0 {
+		var doc interface{}
+		err = json.Unmarshal(configJSON, &doc)
+		if err != nil {
+			return nil, err
+		}
+		for _, set := range c.sets {
+			path, value, err := parseSetExpression(set)
+			if err != nil {
+				return nil, err
+			}
+			doc, err = setJSONPath(doc, path, value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to set %s", set)
+			}
+		}
+		configJSON, err = json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	modifiedConfig := &common.Config{}
+	err = protolator.DeepUnmarshalJSON(bytes.NewReader(configJSON), modifiedConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "the modified config is not a valid channel config")
+	}
+	var originalDoc, modifiedDoc map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &originalDoc)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(configJSON, &modifiedDoc)
+	if err != nil {
+		return nil, err
+	}
+	keepUnchangedGroup(
+		channelConfig.ChannelGroup,
+		modifiedConfig.ChannelGroup,
+		jsonObject(originalDoc, "channel_group"),
+		jsonObject(modifiedDoc, "channel_group"),
+	)
+	return modifiedConfig, nil
+}
+
+// keepUnchangedGroup restores the marshaled values and policies that are the same in both configs, maps aren't
+// marshaled in a deterministic order so the JSON round trip changes their bytes and they would be part of the update
+func keepUnchangedGroup(original *common.ConfigGroup, modified *common.ConfigGroup, originalJSON map[string]interface{}, modifiedJSON map[string]interface{}) {
+	if original == nil || modified == nil {
+		return
+	}
+	for key, value := range modified.Values {
+		originalValue, ok := original.Values[key]
+		if ok && reflect.DeepEqual(
+			jsonObject(jsonObject(originalJSON, "values"), key),
+			jsonObject(jsonObject(modifiedJSON, "values"), key),
+		) {
+			value.Value = originalValue.Value
+		}
+	}
+	for key, policy := range modified.Policies {
+		originalPolicy, ok := original.Policies[key]
+		if ok && policy.Policy != nil && originalPolicy.Policy != nil && reflect.DeepEqual(
+			jsonObject(jsonObject(originalJSON, "policies"), key),
+			jsonObject(jsonObject(modifiedJSON, "policies"), key),
+		) {
+			policy.Policy.Value = originalPolicy.Policy.Value
+		}
+	}
+	for key, group := range modified.Groups {
+		keepUnchangedGroup(
+			original.Groups[key],
+			group,
+			jsonObject(jsonObject(originalJSON, "groups"), key),
+			jsonObject(jsonObject(modifiedJSON, "groups"), key),
+		)
+	}
+}
+
+func jsonObject(doc map[string]interface{}, key string) map[string]interface{} {
+	obj, _ := doc[key].(map[string]interface{})
+	return obj
+}
+
+// parseSetExpression parses a PATH=VALUE expression, the value is parsed as JSON or used as a string when it isn't
+func parseSetExpression(set string) ([]string, interface{}, error) {
+	parts := strings.SplitN(set, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, nil, errors.Errorf("invalid set expression %s, expected PATH=VALUE", set)
+	}
+	path, err := parseJSONPath(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(parts[1]), &value); err != nil {
+		value = parts[1]
+	}
+	return path, value, nil
+}
+
+var jsonPathSegmentRegexp = regexp.MustCompile(`^([^\[\]]*)((?:\[[0-9]+\])*)$`)
+
+// parseJSONPath splits a JSONPath like $.channel_group.groups.Orderer.values.BatchSize.value.max_message_count or
+// $.channel_group.groups.Orderer.values.ConsensusType.value.metadata.consenters[0].port into its segments
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, errors.Errorf("empty path")
+	}
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		match := jsonPathSegmentRegexp.FindStringSubmatch(part)
+		if match == nil || (match[1] == "" && match[2] == "") {
+			return nil, errors.Errorf("invalid path %s", path)
+		}
+		if match[1] != "" {
+			segments = append(segments, match[1])
+		}
+		for _, index := range strings.Split(strings.Trim(match[2], "[]"), "][") {
+			if index != "" {
+				segments = append(segments, "["+index+"]")
+			}
+		}
+	}
+	return segments, nil
+}
+
+// setJSONPath sets the value in the path of the document, the objects in the path are created when missing
+func setJSONPath(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	segment := path[0]
+	if strings.HasPrefix(segment, "[") {
+		list, ok := doc.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s is not a list", segment)
+		}
+		index, err := strconv.Atoi(strings.Trim(segment, "[]"))
+		if err != nil {
+			return nil, err
+		}
+		if index >= len(list) {
+			return nil, errors.Errorf("index %d out of range, the list has %d elements", index, len(list))
+		}
+		list[index], err = setJSONPath(list[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s is not an object", segment)
+	}
+	var err error
+	obj[segment], err = setJSONPath(obj[segment], path[1:], value)
+	if err != nil {
+		return nil, errors.Wrap(err, segment)
+	}
+	return obj, nil
+}
+
+// writeConfigDiff writes a unified diff of the JSON representation of both configs, the names label each side
+func writeConfigDiff(out io.Writer, original *common.Config, modified *common.Config, originalName string, modifiedName string) error {
+	var originalJSON bytes.Buffer
+	err := protolator.DeepMarshalJSON(&originalJSON, original)
+	if err != nil {
+		return err
+	}
+	var modifiedJSON bytes.Buffer
+	err = protolator.DeepMarshalJSON(&modifiedJSON, modified)
+	if err != nil {
+		return err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(originalJSON.String()),
+		B:        difflib.SplitLines(modifiedJSON.String()),
+		FromFile: originalName,
+		ToFile:   modifiedName,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(out, diff)
+	return err
+}
+
+func newUpdateChannelCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &updateChannelCmd{}
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the config of a channel with a JSON patch or JSONPath set expressions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.peer, "peer", "", "", "Admin org to invoke the updates")
+	persistentFlags.StringVarP(&c.channelName, "channel", "", "", "Channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.userName, "user", "", "", "User name for the transaction")
+	persistentFlags.StringVarP(&c.patchPath, "patch", "", "", "JSON patch (RFC 6902) applied to the JSON config of the channel")
+	persistentFlags.StringArrayVarP(&c.sets, "set", "", nil, "PATH=VALUE expression setting a value of the JSON config, e.g. $.channel_group.groups.Orderer.values.BatchSize.value.max_message_count=100")
+	persistentFlags.StringVarP(&c.output, "output", "o", "", "Save the config update envelope in this file instead of submitting it")
+	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Print the diff of the config and not update")
+	cmd.MarkPersistentFlagRequired("channel")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("user")
+	cmd.MarkPersistentFlagRequired("peer")
+	return cmd
+}
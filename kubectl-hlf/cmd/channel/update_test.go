@@ -0,0 +1,177 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "$.channel_group.groups.Orderer.values.BatchSize.value.max_message_count", want: []string{"channel_group", "groups", "Orderer", "values", "BatchSize", "value", "max_message_count"}},
+		{path: "channel_group.mod_policy", want: []string{"channel_group", "mod_policy"}},
+		{path: "$.consenters[0].port", want: []string{"consenters", "[0]", "port"}},
+		{path: "$.matrix[1][12]", want: []string{"matrix", "[1]", "[12]"}},
+		{path: "$", wantErr: true},
+		{path: "", wantErr: true},
+		{path: "$.channel_group..groups", wantErr: true},
+		{path: "$.consenters[a]", wantErr: true},
+		{path: "$.consenters[0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			g := NewWithT(t)
+			segments, err := parseJSONPath(tt.path)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(segments).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestParseSetExpression(t *testing.T) {
+	g := NewWithT(t)
+	path, value, err := parseSetExpression("$.batch_size.max_message_count=50")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(path).To(Equal([]string{"batch_size", "max_message_count"}))
+	g.Expect(value).To(Equal(float64(50)))
+
+	_, value, err = parseSetExpression(`$.batch_timeout={"timeout":"2s"}`)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(value).To(Equal(map[string]interface{}{"timeout": "2s"}))
+
+	// values that aren't JSON are strings
+	_, value, err = parseSetExpression("$.batch_timeout.timeout=2s")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(value).To(Equal("2s"))
+
+	_, _, err = parseSetExpression("$.batch_timeout.timeout")
+	g.Expect(err).To(MatchError(ContainSubstring("expected PATH=VALUE")))
+	_, _, err = parseSetExpression("=2s")
+	g.Expect(err).To(MatchError(ContainSubstring("expected PATH=VALUE")))
+}
+
+func TestSetJSONPath(t *testing.T) {
+	newDoc := func() interface{} {
+		return map[string]interface{}{
+			"batch_size": map[string]interface{}{"max_message_count": float64(10)},
+			"consenters": []interface{}{
+				map[string]interface{}{"host": "orderer0", "port": float64(7050)},
+				map[string]interface{}{"host": "orderer1", "port": float64(7050)},
+			},
+			"state": "STATE_NORMAL",
+		}
+	}
+	tests := []struct {
+		name    string
+		path    []string
+		value   interface{}
+		check   func(g *WithT, doc map[string]interface{})
+		wantErr string
+	}{
+		{
+			name:  "existing field",
+			path:  []string{"batch_size", "max_message_count"},
+			value: float64(50),
+			check: func(g *WithT, doc map[string]interface{}) {
+				g.Expect(doc["batch_size"]).To(Equal(map[string]interface{}{"max_message_count": float64(50)}))
+			},
+		},
+		{
+			name:  "missing objects are created",
+			path:  []string{"batch_timeout", "timeout"},
+			value: "2s",
+			check: func(g *WithT, doc map[string]interface{}) {
+				g.Expect(doc["batch_timeout"]).To(Equal(map[string]interface{}{"timeout": "2s"}))
+			},
+		},
+		{
+			name:  "list element",
+			path:  []string{"consenters", "[1]", "port"},
+			value: float64(7051),
+			check: func(g *WithT, doc map[string]interface{}) {
+				consenters := doc["consenters"].([]interface{})
+				g.Expect(consenters[0]).To(HaveKeyWithValue("port", float64(7050)))
+				g.Expect(consenters[1]).To(HaveKeyWithValue("port", float64(7051)))
+			},
+		},
+		{name: "index out of range", path: []string{"consenters", "[2]", "port"}, value: float64(7051), wantErr: "index 2 out of range"},
+		{name: "index of an object", path: []string{"batch_size", "[0]"}, value: float64(1), wantErr: "is not a list"},
+		{name: "field of a string", path: []string{"state", "value"}, value: "x", wantErr: "is not an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			doc, err := setJSONPath(newDoc(), tt.path, tt.value)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			tt.check(g, doc.(map[string]interface{}))
+		})
+	}
+}
+
+func TestKeepUnchangedGroup(t *testing.T) {
+	newGroup := func(valueBytes string, policyBytes string) *common.ConfigGroup {
+		return &common.ConfigGroup{
+			Values: map[string]*common.ConfigValue{
+				"BatchSize":    {Value: []byte(valueBytes)},
+				"BatchTimeout": {Value: []byte(valueBytes)},
+			},
+			Policies: map[string]*common.ConfigPolicy{
+				"Admins":  {Policy: &common.Policy{Value: []byte(policyBytes)}},
+				"Writers": {Policy: &common.Policy{Value: []byte(policyBytes)}},
+			},
+			Groups: map[string]*common.ConfigGroup{},
+		}
+	}
+	original := newGroup("original", "original")
+	original.Groups["Orderer"] = newGroup("original", "original")
+	// the JSON round trip marshals the maps in another order
+	modified := newGroup("remarshaled", "remarshaled")
+	modified.Groups["Orderer"] = newGroup("remarshaled", "remarshaled")
+	modified.Groups["Application"] = newGroup("new", "new")
+
+	groupJSON := func(batchTimeout string, writersRule string) map[string]interface{} {
+		return map[string]interface{}{
+			"values": map[string]interface{}{
+				"BatchSize":    map[string]interface{}{"value": map[string]interface{}{"max_message_count": float64(10)}},
+				"BatchTimeout": map[string]interface{}{"value": map[string]interface{}{"timeout": batchTimeout}},
+			},
+			"policies": map[string]interface{}{
+				"Admins":  map[string]interface{}{"policy": map[string]interface{}{"rule": "MAJORITY"}},
+				"Writers": map[string]interface{}{"policy": map[string]interface{}{"rule": writersRule}},
+			},
+		}
+	}
+	originalJSON := groupJSON("2s", "ANY")
+	originalJSON["groups"] = map[string]interface{}{"Orderer": groupJSON("2s", "ANY")}
+	modifiedJSON := groupJSON("2s", "ANY")
+	modifiedJSON["groups"] = map[string]interface{}{
+		"Orderer":     groupJSON("5s", "MAJORITY"),
+		"Application": groupJSON("2s", "ANY"),
+	}
+
+	keepUnchangedGroup(original, modified, originalJSON, modifiedJSON)
+	g := NewWithT(t)
+	g.Expect(string(modified.Values["BatchSize"].Value)).To(Equal("original"))
+	g.Expect(string(modified.Values["BatchTimeout"].Value)).To(Equal("original"))
+	g.Expect(string(modified.Policies["Writers"].Policy.Value)).To(Equal("original"))
+	orderer := modified.Groups["Orderer"]
+	g.Expect(string(orderer.Values["BatchSize"].Value)).To(Equal("original"))
+	g.Expect(string(orderer.Values["BatchTimeout"].Value)).To(Equal("remarshaled"))
+	g.Expect(string(orderer.Policies["Admins"].Policy.Value)).To(Equal("original"))
+	g.Expect(string(orderer.Policies["Writers"].Policy.Value)).To(Equal("remarshaled"))
+	// a new group has nothing to keep
+	g.Expect(string(modified.Groups["Application"].Values["BatchSize"].Value)).To(Equal("new"))
+}
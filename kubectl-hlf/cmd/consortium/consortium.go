@@ -11,5 +11,6 @@ func NewConsortiumCmd(stdOut io.Writer, stdErr io.Writer) *cobra.Command {
 	}
 
 	consortiumCmd.AddCommand(NewCreateConsortiumCMD(stdOut, stdErr))
+	consortiumCmd.AddCommand(NewDelOrgConsortiumCMD(stdOut, stdErr))
 	return consortiumCmd
 }
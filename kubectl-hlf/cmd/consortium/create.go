@@ -70,20 +70,18 @@ func (c *createConsortiumCmd) run() error {
 		if !utils.Contains(c.peers, peer.Name) {
 			continue
 		}
-		certAuth, err := helpers.GetCertAuthByURL(
-			oclient,
-			peer.Spec.Secret.Enrollment.Component.Cahost,
-			peer.Spec.Secret.Enrollment.Component.Caport,
-		)
+		mspCerts, err := helpers.GetPeerMSPCerts(oclient, peer, helpers.GetCertAuthByComponent)
 		if err != nil {
 			return err
 		}
 		var nodes []testutils.PeerNode
 		peerOrgs = append(peerOrgs, testutils.PeerOrganization{
-			RootCert:    certAuth.Status.CACert,
-			TLSRootCert: certAuth.Status.TLSCACert,
-			MspID:       peer.Spec.MspID,
-			Peers:       nodes,
+			RootCert:             mspCerts.CACert,
+			TLSRootCert:          mspCerts.TLSCACert,
+			IntermediateCerts:    mspCerts.CAIntermediateCerts,
+			TLSIntermediateCerts: mspCerts.TLSCAIntermediateCerts,
+			MspID:                peer.Spec.MspID,
+			Peers:                nodes,
 		})
 	}
 	if len(peerOrgs) == 0 {
@@ -0,0 +1,117 @@
+package consortium
+
+import (
+	"bytes"
+	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"io"
+)
+
+type delOrgConsortiumCmd struct {
+	configPath      string
+	consortiumName  string
+	mspID           string
+	ordererOrg      string
+	systemChannelID string
+	user            string
+	dryRun          bool
+}
+
+func (c *delOrgConsortiumCmd) validate() error {
+	return nil
+}
+
+func (c *delOrgConsortiumCmd) run(out io.Writer) error {
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return err
+	}
+	configBackend := config.FromFile(c.configPath)
+	sdk, err := fabsdk.New(configBackend)
+	if err != nil {
+		return err
+	}
+	defer sdk.Close()
+	ordService, err := helpers.GetOrderingServiceByFullName(oclient, c.ordererOrg)
+	if err != nil {
+		return err
+	}
+	ordClient, err := resmgmt.New(sdk.Context(
+		fabsdk.WithUser(c.user),
+		fabsdk.WithOrg(ordService.Spec.MspID),
+	))
+	if err != nil {
+		return err
+	}
+	block, err := ordClient.QueryConfigBlockFromOrderer(c.systemChannelID)
+	if err != nil {
+		return err
+	}
+	systemChannelConfig, err := resource.ExtractConfigFromBlock(block)
+	if err != nil {
+		return err
+	}
+	modifiedConfig, err := testutils.RemoveOrgFromConsortium(systemChannelConfig, c.consortiumName, c.mspID)
+	if err != nil {
+		return err
+	}
+	confUpdate, err := resmgmt.CalculateConfigUpdate(
+		c.systemChannelID,
+		systemChannelConfig,
+		modifiedConfig,
+	)
+	if err != nil {
+		return err
+	}
+	if c.dryRun {
+		return protolator.DeepMarshalJSON(out, confUpdate)
+	}
+	configEnvelopeBytes, err := testutils.GetConfigEnvelopeBytes(confUpdate)
+	if err != nil {
+		return err
+	}
+	saveResponse, err := ordClient.SaveChannel(resmgmt.SaveChannelRequest{
+		ChannelID:     c.systemChannelID,
+		ChannelConfig: bytes.NewReader(configEnvelopeBytes),
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Organization %s removed from consortium %s, txID=%s", c.mspID, c.consortiumName, saveResponse.TransactionID)
+	return nil
+}
+func NewDelOrgConsortiumCMD(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := &delOrgConsortiumCmd{}
+	cmd := &cobra.Command{
+		Use:   "delorg",
+		Short: "Remove an organization from a consortium, the channels it belongs to aren't modified",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(out)
+		},
+	}
+	persistentFlags := cmd.PersistentFlags()
+	persistentFlags.StringVarP(&c.consortiumName, "name", "n", "", "Name of the consortium")
+	persistentFlags.StringVarP(&c.mspID, "msp-id", "", "", "MSP ID of the organization to remove")
+	persistentFlags.StringVarP(&c.ordererOrg, "orderer-org", "", "", "Ordering service name")
+	persistentFlags.StringVarP(&c.systemChannelID, "system-channel-id", "", "", "System channel name")
+	persistentFlags.StringVarP(&c.configPath, "config", "", "", "Configuration file for the SDK")
+	persistentFlags.StringVarP(&c.user, "user", "", "", "User used to issue the transaction")
+	persistentFlags.BoolVarP(&c.dryRun, "dry-run", "", false, "Output configuration as JSON and not update")
+	cmd.MarkPersistentFlagRequired("name")
+	cmd.MarkPersistentFlagRequired("msp-id")
+	cmd.MarkPersistentFlagRequired("orderer-org")
+	cmd.MarkPersistentFlagRequired("system-channel-id")
+	cmd.MarkPersistentFlagRequired("config")
+	cmd.MarkPersistentFlagRequired("user")
+	return cmd
+}
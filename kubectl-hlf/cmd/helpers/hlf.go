@@ -6,6 +6,8 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
 	operatorv1 "github.com/kfsoftware/hlf-operator/pkg/client/clientset/versioned"
 	"github.com/pkg/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -47,13 +49,15 @@ type ClusterOrderingService struct {
 }
 
 type ClusterOrdererNode struct {
-	Name   string
-	Spec   hlfv1alpha1.FabricOrdererNodeSpec
-	Status hlfv1alpha1.FabricOrdererNodeStatus
+	Name      string
+	Namespace string
+	Spec      hlfv1alpha1.FabricOrdererNodeSpec
+	Status    hlfv1alpha1.FabricOrdererNodeStatus
 }
 
 type ClusterPeer struct {
 	Name      string
+	Namespace string
 	Spec      hlfv1alpha1.FabricPeerSpec
 	Status    hlfv1alpha1.FabricPeerStatus
 	TLSCACert string
@@ -181,9 +185,10 @@ func GetClusterOrdererNodes(
 		ordererNodes = append(
 			ordererNodes,
 			&ClusterOrdererNode{
-				Name:   ordNode.FullName(),
-				Spec:   ordNode.Spec,
-				Status: ordNode.Status,
+				Name:      ordNode.FullName(),
+				Namespace: ordNode.Namespace,
+				Spec:      ordNode.Spec,
+				Status:    ordNode.Status,
 			},
 		)
 	}
@@ -196,8 +201,7 @@ func GetCertAuthByURL(oclient *operatorv1.Clientset, host string, port int) (*Cl
 		return nil, err
 	}
 	for _, certAuth := range certAuths {
-		if // if host and port is specified by kubernetes DNS
-		certAuth.Item.Name == host || (certAuth.Status.NodePort != 7054 && certAuth.Status.NodePort == port) {
+		if certAuthMatchesURL(certAuth, host, port) {
 			return certAuth, nil
 		}
 
@@ -205,6 +209,126 @@ func GetCertAuthByURL(oclient *operatorv1.Clientset, host string, port int) (*Cl
 	return nil, errors.Errorf("CA with host=%s port=%d not found", host, port)
 }
 
+// certAuthMatchesURL returns whether host and port address the CA, either through one of the DNS names of its service
+// or through its node port
+func certAuthMatchesURL(certAuth *ClusterCA, host string, port int) bool {
+	if utils.Contains(certs.ServiceHosts(certAuth.Item.Name, certAuth.Item.Namespace), host) {
+		return true
+	}
+	return certAuth.Status.NodePort != 7054 && certAuth.Status.NodePort == port
+}
+
+// GetCertAuthByComponent returns the CA the component enrolls against, using caRef when set
+func GetCertAuthByComponent(oclient *operatorv1.Clientset, component hlfv1alpha1.Component, ns string) (*ClusterCA, error) {
+	if component.CARef != nil {
+		caNamespace := component.CARef.Namespace
+		if caNamespace == "" {
+			caNamespace = ns
+		}
+		return GetCertAuthByName(oclient, component.CARef.Name, caNamespace)
+	}
+	return GetCertAuthByURL(oclient, component.Cahost, component.Caport)
+}
+
+// GetPeerTLSRootCert returns the TLS root certificate of the peer, the issuer CA when cert-manager issues its TLS certificate
+func GetPeerTLSRootCert(peer *ClusterPeer, certAuth *ClusterCA) string {
+	if peer.Spec.Secret.Enrollment.TLS.CertManager != nil && peer.Status.TlsCACert != "" {
+		return peer.Status.TlsCACert
+	}
+	return certAuth.Status.TLSCACert
+}
+
+// GetOrdererNodeTLSRootCert returns the TLS root certificate of the orderer node, the issuer CA when cert-manager issues its TLS certificate
+func GetOrdererNodeTLSRootCert(node *ClusterOrdererNode, certAuth *ClusterCA) string {
+	if node.Spec.Secret != nil && node.Spec.Secret.Enrollment.TLS.CertManager != nil && node.Status.TlsCACert != "" {
+		return node.Status.TlsCACert
+	}
+	return certAuth.Status.TLSCACert
+}
+
+// GetPeerTLSIntermediateCerts returns the TLS intermediate certificates of the peer, empty when cert-manager issues its TLS certificate
+func GetPeerTLSIntermediateCerts(peer *ClusterPeer, certAuth *ClusterCA) string {
+	if peer.Spec.Secret.Enrollment.TLS.CertManager != nil && peer.Status.TlsCACert != "" {
+		return ""
+	}
+	return certAuth.Status.TLSCAIntermediateCerts
+}
+
+// GetOrdererNodeTLSIntermediateCerts returns the TLS intermediate certificates of the orderer node, empty when cert-manager issues its TLS certificate
+func GetOrdererNodeTLSIntermediateCerts(node *ClusterOrdererNode, certAuth *ClusterCA) string {
+	if node.Spec.Secret != nil && node.Spec.Secret.Enrollment.TLS.CertManager != nil && node.Status.TlsCACert != "" {
+		return ""
+	}
+	return certAuth.Status.TLSCAIntermediateCerts
+}
+
+// MSPCerts are the CA certificates that verify the identities of an organization, PEM encoded
+type MSPCerts struct {
+	CACert                 string
+	TLSCACert              string
+	CAIntermediateCerts    string
+	TLSCAIntermediateCerts string
+}
+
+// CertAuthLookup finds the FabricCA a component enrolls against
+type CertAuthLookup func(oclient *operatorv1.Clientset, component hlfv1alpha1.Component, ns string) (*ClusterCA, error)
+
+// GetPeerMSPCerts returns the CA certificates of the MSP of the peer, read from its crypto Secrets when the peer
+// doesn't enroll against a FabricCA
+func GetPeerMSPCerts(oclient *operatorv1.Clientset, peer *ClusterPeer, lookup CertAuthLookup) (*MSPCerts, error) {
+	if !peer.Spec.Secret.EnrollsAgainstCA() {
+		return getNodeMSPCerts(peer.Namespace, peer.Spec.MspID, &peer.Spec.Secret)
+	}
+	certAuth, err := lookup(oclient, peer.Spec.Secret.Enrollment.Component, peer.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &MSPCerts{
+		CACert:                 certAuth.Status.CACert,
+		TLSCACert:              GetPeerTLSRootCert(peer, certAuth),
+		CAIntermediateCerts:    certAuth.Status.CAIntermediateCerts,
+		TLSCAIntermediateCerts: GetPeerTLSIntermediateCerts(peer, certAuth),
+	}, nil
+}
+
+// GetOrdererNodeMSPCerts returns the CA certificates of the MSP of the orderer node, read from its crypto Secrets
+// when the node doesn't enroll against a FabricCA
+func GetOrdererNodeMSPCerts(oclient *operatorv1.Clientset, node *ClusterOrdererNode) (*MSPCerts, error) {
+	if node.Spec.Secret == nil {
+		return nil, errors.Errorf("orderer node %s has no secret", node.Name)
+	}
+	if !node.Spec.Secret.EnrollsAgainstCA() {
+		return getNodeMSPCerts(node.Namespace, node.Spec.MspID, node.Spec.Secret)
+	}
+	certAuth, err := GetCertAuthByComponent(oclient, node.Spec.Secret.Enrollment.Component, node.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &MSPCerts{
+		CACert:                 certAuth.Status.CACert,
+		TLSCACert:              GetOrdererNodeTLSRootCert(node, certAuth),
+		CAIntermediateCerts:    certAuth.Status.CAIntermediateCerts,
+		TLSCAIntermediateCerts: GetOrdererNodeTLSIntermediateCerts(node, certAuth),
+	}, nil
+}
+
+func getNodeMSPCerts(namespace string, mspID string, secret *hlfv1alpha1.Secret) (*MSPCerts, error) {
+	clientSet, err := GetKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	signCrypto, tlsCrypto, err := certs.GetNodeCACerts(clientSet, namespace, mspID, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &MSPCerts{
+		CACert:                 string(utils.EncodeX509Certificate(signCrypto.RootCert)),
+		TLSCACert:              string(utils.EncodeX509Certificate(tlsCrypto.RootCert)),
+		CAIntermediateCerts:    signCrypto.EncodedIntermediateCerts(),
+		TLSCAIntermediateCerts: tlsCrypto.EncodedIntermediateCerts(),
+	}, nil
+}
+
 func GetCertAuthByName(oclient *operatorv1.Clientset, name string, ns string) (*ClusterCA, error) {
 	certAuths, err := GetClusterCAs(oclient, "")
 	if err != nil {
@@ -284,10 +408,11 @@ func GetClusterPeers(oclient *operatorv1.Clientset, ns string) ([]*Organization,
 		peers = append(
 			peers,
 			&ClusterPeer{
-				Name:     peer.FullName(),
-				Spec:     peer.Spec,
-				Status:   peer.Status,
-				Identity: Identity{},
+				Name:      peer.FullName(),
+				Namespace: peer.Namespace,
+				Spec:      peer.Spec,
+				Status:    peer.Status,
+				Identity:  Identity{},
 			},
 		)
 	}
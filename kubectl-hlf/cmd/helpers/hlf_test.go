@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"testing"
+
+	hlfv1alpha1 "github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertAuthMatchesURL(t *testing.T) {
+	certAuth := &ClusterCA{
+		Item:   hlfv1alpha1.FabricCA{ObjectMeta: v1.ObjectMeta{Name: "org1-ca", Namespace: "default"}},
+		Status: hlfv1alpha1.FabricCAStatus{NodePort: 30954},
+	}
+	tests := []struct {
+		host    string
+		port    int
+		matches bool
+	}{
+		{host: "org1-ca", port: 7054, matches: true},
+		{host: "org1-ca.default", port: 7054, matches: true},
+		{host: "org1-ca.default.svc.cluster.local", port: 7054, matches: true},
+		{host: "172.18.0.2", port: 30954, matches: true},
+		{host: "org1-ca.other", port: 7054, matches: false},
+		{host: "org1-ca-tls.default", port: 7054, matches: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(certAuthMatchesURL(certAuth, tt.host, tt.port)).To(Equal(tt.matches))
+		})
+	}
+}
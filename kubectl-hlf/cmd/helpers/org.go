@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	operatorv1 "github.com/kfsoftware/hlf-operator/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+)
+
+// GetClusterPeerOrganization returns the MSP definition of a peer organization from the FabricCA and the FabricPeers
+// of the MSP ID, the peers are its anchor peers. The CA of the peers is used when caName is empty
+func GetClusterPeerOrganization(oclient *operatorv1.Clientset, mspID string, caName string) (*testutils.PeerOrganization, error) {
+	_, clusterPeers, err := GetClusterPeers(oclient, "")
+	if err != nil {
+		return nil, err
+	}
+	var peers []*ClusterPeer
+	for _, peer := range clusterPeers {
+		if peer.Spec.MspID == mspID {
+			peers = append(peers, peer)
+		}
+	}
+	var mspCerts *MSPCerts
+	if caName != "" {
+		certAuth, err := GetCertAuthByFullName(oclient, caName)
+		if err != nil {
+			return nil, err
+		}
+		mspCerts = &MSPCerts{
+			CACert:                 certAuth.Status.CACert,
+			TLSCACert:              certAuth.Status.TLSCACert,
+			CAIntermediateCerts:    certAuth.Status.CAIntermediateCerts,
+			TLSCAIntermediateCerts: certAuth.Status.TLSCAIntermediateCerts,
+		}
+		if len(peers) > 0 {
+			mspCerts.TLSCACert = GetPeerTLSRootCert(peers[0], certAuth)
+			mspCerts.TLSCAIntermediateCerts = GetPeerTLSIntermediateCerts(peers[0], certAuth)
+		}
+	} else if len(peers) > 0 {
+		mspCerts, err = GetPeerMSPCerts(oclient, peers[0], GetCertAuthByComponent)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, errors.Errorf("no peers found for msp ID %s", mspID)
+	}
+	peerOrg := &testutils.PeerOrganization{
+		RootCert:             mspCerts.CACert,
+		TLSRootCert:          mspCerts.TLSCACert,
+		IntermediateCerts:    mspCerts.CAIntermediateCerts,
+		TLSIntermediateCerts: mspCerts.TLSCAIntermediateCerts,
+		MspID:                mspID,
+	}
+	if len(peers) == 0 {
+		return peerOrg, nil
+	}
+	clientSet, err := GetKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	k8sIP, err := utils.GetPublicIPKubernetes(clientSet)
+	if err != nil {
+		return nil, err
+	}
+	for _, peer := range peers {
+		peerOrg.Peers = append(peerOrg.Peers, testutils.PeerNode{
+			Host: k8sIP,
+			Port: peer.Status.NodePort,
+		})
+	}
+	return peerOrg, nil
+}
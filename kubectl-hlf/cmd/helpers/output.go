@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// ValidateOutputFormat checks the value of an --output flag
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputTable, OutputJSON, OutputYAML:
+		return nil
+	default:
+		return errors.Errorf("invalid output %s, supported outputs are %s, %s and %s", format, OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+// PrintOutput writes data as JSON or YAML, or the rows under the header as a table
+func PrintOutput(out io.Writer, format string, data interface{}, header []string, rows [][]string) error {
+	switch format {
+	case OutputJSON:
+		dataBytes, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(dataBytes))
+		return err
+	case OutputYAML:
+		dataBytes, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, string(dataBytes))
+		return err
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
@@ -2,7 +2,6 @@ package ordnode
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"github.com/kfsoftware/hlf-operator/api/hlf.kungfusoftware.es/v1alpha1"
 	"github.com/kfsoftware/hlf-operator/controllers/utils"
@@ -137,21 +136,17 @@ func (c *createCmd) run(args []string) error {
 			Secret: &v1alpha1.Secret{
 				Enrollment: v1alpha1.Enrollment{
 					Component: v1alpha1.Component{
-						Cahost: k8sIP,
-						Caname: certAuth.Spec.CA.Name,
-						Caport: certAuth.Status.NodePort,
-						Catls: v1alpha1.Catls{
-							Cacert: base64.StdEncoding.EncodeToString([]byte(certAuth.Status.TlsCert)),
+						CARef: &v1alpha1.CARef{
+							Name:      certAuth.Object.Name,
+							Namespace: certAuth.Object.Namespace,
 						},
 						Enrollid:     c.ordererOpts.EnrollID,
 						Enrollsecret: c.ordererOpts.EnrollPW,
 					},
 					TLS: v1alpha1.TLS{
-						Cahost: k8sIP,
-						Caname: certAuth.Spec.TLSCA.Name,
-						Caport: certAuth.Status.NodePort,
-						Catls: v1alpha1.Catls{
-							Cacert: base64.StdEncoding.EncodeToString([]byte(certAuth.Status.TlsCert)),
+						CARef: &v1alpha1.CARef{
+							Name:      certAuth.Object.Name,
+							Namespace: certAuth.Object.Namespace,
 						},
 						Csr: v1alpha1.Csr{
 							Hosts: []string{
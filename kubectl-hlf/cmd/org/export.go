@@ -0,0 +1,150 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/hyperledger/fabric-config/protolator"
+	"github.com/hyperledger/fabric-config/protolator/protoext/peerext"
+	"github.com/kfsoftware/hlf-operator/controllers/testutils"
+	"github.com/kfsoftware/hlf-operator/internal/github.com/hyperledger/fabric/sdkinternal/configtxgen/genesisconfig"
+	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	exportFormatYAML = "yaml"
+	exportFormatJSON = "json"
+)
+
+type ExportOptions struct {
+	MSPID      string
+	CAName     string
+	Format     string
+	OutputPath string
+}
+
+func (o ExportOptions) Validate() error {
+	if o.MSPID == "" {
+		return errors.Errorf("--msp-id is required")
+	}
+	if o.Format != exportFormatYAML && o.Format != exportFormatJSON {
+		return errors.Errorf("invalid format %s, expected %s or %s", o.Format, exportFormatYAML, exportFormatJSON)
+	}
+	return nil
+}
+
+// configtxOrganization is the organization in a configtx.yaml, without the orderer fields
+type configtxOrganization struct {
+	Name        string                           `yaml:"Name"`
+	ID          string                           `yaml:"ID"`
+	MSPDir      string                           `yaml:"MSPDir"`
+	MSPType     string                           `yaml:"MSPType"`
+	Policies    map[string]*genesisconfig.Policy `yaml:"Policies"`
+	AnchorPeers []*genesisconfig.AnchorPeer      `yaml:"AnchorPeers,omitempty"`
+}
+
+type configtxOrganizations struct {
+	Organizations []configtxOrganization `yaml:"Organizations"`
+}
+
+type exportCmd struct {
+	out     io.Writer
+	errOut  io.Writer
+	orgOpts ExportOptions
+}
+
+func (c *exportCmd) validate() error {
+	return c.orgOpts.Validate()
+}
+
+func (c *exportCmd) run() error {
+	oclient, err := helpers.GetKubeOperatorClient()
+	if err != nil {
+		return err
+	}
+	peerOrg, err := helpers.GetClusterPeerOrganization(oclient, c.orgOpts.MSPID, c.orgOpts.CAName)
+	if err != nil {
+		return err
+	}
+	if c.orgOpts.Format == exportFormatJSON {
+		orgGroup, err := testutils.NewApplicationOrgGroup(*peerOrg)
+		if err != nil {
+			return err
+		}
+		return protolator.DeepMarshalJSON(c.out, &peerext.DynamicApplicationOrgGroup{ConfigGroup: orgGroup})
+	}
+	return c.writeConfigtx(peerOrg)
+}
+
+// writeConfigtx writes the MSP folder of the organization and a configtx.yaml referencing it
+func (c *exportCmd) writeConfigtx(peerOrg *testutils.PeerOrganization) error {
+	orgPath := path.Join(c.orgOpts.OutputPath, peerOrg.MspID)
+	err := writeMSPFolder(
+		path.Join(orgPath, "msp"),
+		peerOrg.RootCert,
+		peerOrg.TLSRootCert,
+		peerOrg.IntermediateCerts,
+		peerOrg.TLSIntermediateCerts,
+	)
+	if err != nil {
+		return err
+	}
+	var anchorPeers []*genesisconfig.AnchorPeer
+	for _, peer := range peerOrg.Peers {
+		anchorPeers = append(anchorPeers, &genesisconfig.AnchorPeer{
+			Host: peer.Host,
+			Port: peer.Port,
+		})
+	}
+	configtx, err := yaml.Marshal(configtxOrganizations{
+		Organizations: []configtxOrganization{
+			{
+				Name:        peerOrg.MspID,
+				ID:          peerOrg.MspID,
+				MSPDir:      "msp",
+				MSPType:     "bccsp",
+				Policies:    testutils.DefaultPeerOrgPolicies(peerOrg.MspID),
+				AnchorPeers: anchorPeers,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	configtxPath := path.Join(orgPath, "configtx.yaml")
+	err = ioutil.WriteFile(configtxPath, configtx, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.out, "Organization %s exported to %s\n", peerOrg.MspID, configtxPath)
+	return err
+}
+
+func newOrgExportCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	c := exportCmd{out: out, errOut: errOut}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports the channel definition of an organization of the cluster",
+		Long: `Exports the channel definition of an organization from its FabricCA and FabricPeers, the peers are its anchor peers.
+The yaml format writes a configtx.yaml and the MSP folder it references, the json format prints the configtxlator
+JSON of the application org group. Both can be used as the --org-config of kubectl hlf channel addorg.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run()
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&c.orgOpts.MSPID, "msp-id", "", "", "MSP ID of the organization")
+	f.StringVarP(&c.orgOpts.CAName, "ca-name", "", "", "FabricCA of the organization, in the format CA_NAME.CA_NAMESPACE, the CA of its peers when empty")
+	f.StringVarP(&c.orgOpts.Format, "format", "", exportFormatYAML, "Output format, yaml (configtxgen) or json (configtxlator)")
+	f.StringVarP(&c.orgOpts.OutputPath, "output-path", "", ".", "Output path of the yaml format")
+	return cmd
+}
@@ -1,6 +1,9 @@
 package org
 
 import (
+	"fmt"
+	"github.com/kfsoftware/hlf-operator/controllers/certs"
+	"github.com/kfsoftware/hlf-operator/controllers/utils"
 	"github.com/kfsoftware/hlf-operator/kubectl-hlf/cmd/helpers"
 	"github.com/spf13/cobra"
 	"io"
@@ -39,38 +42,56 @@ func (c *inspectCmd) run(args []string) error {
 	}
 	for _, peerOrg := range peerOrgs {
 		firstPeer := peerOrg.Peers[0]
-		certAuth, err := helpers.GetCertAuthByURL(
-			oclient,
-			firstPeer.Spec.Secret.Enrollment.Component.Cahost,
-			firstPeer.Spec.Secret.Enrollment.Component.Caport,
-		)
+		mspCerts, err := helpers.GetPeerMSPCerts(oclient, firstPeer, helpers.GetCertAuthByComponent)
 		if err != nil {
 			return err
 		}
 		orgPath := path.Join(baseOutputPath, "peerOrganizations", peerOrg.MspID)
-		mspPath := path.Join(orgPath, "msp")
-		mspCaCerts := path.Join(mspPath, "cacerts")
-		mspTLSCaCerts := path.Join(mspPath, "tlscacerts")
-
-		err = os.MkdirAll(mspCaCerts, os.ModePerm)
-		if err != nil {
-			return err
-		}
-		err = os.MkdirAll(mspTLSCaCerts, os.ModePerm)
-		if err != nil {
-			return err
-		}
-		mspCACertPath := path.Join(mspCaCerts, "ca.pem")
-		err = ioutil.WriteFile(mspCACertPath, []byte(certAuth.Status.CACert), os.ModePerm)
-		if err != nil {
-			return err
-		}
-		mspTLSCACertPath := path.Join(mspTLSCaCerts, "tlsca.pem")
-		err = ioutil.WriteFile(mspTLSCACertPath, []byte(certAuth.Status.TLSCACert), os.ModePerm)
+		err = writeMSPFolder(
+			path.Join(orgPath, "msp"),
+			mspCerts.CACert,
+			mspCerts.TLSCACert,
+			mspCerts.CAIntermediateCerts,
+			mspCerts.TLSCAIntermediateCerts,
+		)
 		if err != nil {
 			return err
 		}
-		nodeOusContent := `
+	}
+	return nil
+}
+
+// writeMSPFolder writes the verifying MSP folder of an organization whose identities are classified with NodeOUs
+func writeMSPFolder(mspPath string, caCert string, tlsCACert string, intermediateCerts string, tlsIntermediateCerts string) error {
+	mspCaCerts := path.Join(mspPath, "cacerts")
+	mspTLSCaCerts := path.Join(mspPath, "tlscacerts")
+	err := os.MkdirAll(mspCaCerts, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(mspTLSCaCerts, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	mspCACertPath := path.Join(mspCaCerts, "ca.pem")
+	err = ioutil.WriteFile(mspCACertPath, []byte(caCert), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	mspTLSCACertPath := path.Join(mspTLSCaCerts, "tlsca.pem")
+	err = ioutil.WriteFile(mspTLSCACertPath, []byte(tlsCACert), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	err = writeIntermediateCerts(path.Join(mspPath, "intermediatecerts"), intermediateCerts)
+	if err != nil {
+		return err
+	}
+	err = writeIntermediateCerts(path.Join(mspPath, "tlsintermediatecerts"), tlsIntermediateCerts)
+	if err != nil {
+		return err
+	}
+	nodeOusContent := `
 NodeOUs:
   Enable: true
   ClientOUIdentifier:
@@ -86,14 +107,29 @@ NodeOUs:
     Certificate: cacerts/ca.pem
     OrganizationalUnitIdentifier: orderer
 `
-		nodeOusPath := path.Join(mspPath, "config.yaml")
-		err = ioutil.WriteFile(nodeOusPath, []byte(nodeOusContent), os.ModePerm)
+	nodeOusPath := path.Join(mspPath, "config.yaml")
+	return ioutil.WriteFile(nodeOusPath, []byte(nodeOusContent), os.ModePerm)
+}
+
+// writeIntermediateCerts writes each intermediate certificate of the PEM bundle to its own file of the MSP folder
+func writeIntermediateCerts(folderPath string, intermediateCerts string) error {
+	crts, err := certs.ParseCertificates([]byte(intermediateCerts))
+	if err != nil {
+		return err
+	}
+	for i, crt := range crts {
+		err = os.MkdirAll(folderPath, os.ModePerm)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path.Join(folderPath, fmt.Sprintf("cert-%d.pem", i)), utils.EncodeX509Certificate(crt), os.ModePerm)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
 func newOrgInspectCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	c := inspectCmd{out: out, errOut: errOut}
 	cmd := &cobra.Command{
@@ -10,5 +10,6 @@ func NewOrgCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 		Use: "org",
 	}
 	cmd.AddCommand(newOrgInspectCmd(out, errOut))
+	cmd.AddCommand(newOrgExportCmd(out, errOut))
 	return cmd
 }
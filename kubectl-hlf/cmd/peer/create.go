@@ -2,7 +2,6 @@ package peer
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"github.com/pkg/errors"
 	"io"
@@ -143,21 +142,17 @@ func (c *createCmd) run() error {
 			Secret: v1alpha1.Secret{
 				Enrollment: v1alpha1.Enrollment{
 					Component: v1alpha1.Component{
-						Cahost: fmt.Sprintf("%s.%s", certAuth.Object.Name, certAuth.Object.Namespace),
-						Caname: certAuth.Spec.CA.Name,
-						Caport: 7054,
-						Catls: v1alpha1.Catls{
-							Cacert: base64.StdEncoding.EncodeToString([]byte(certAuth.Status.TlsCert)),
+						CARef: &v1alpha1.CARef{
+							Name:      certAuth.Object.Name,
+							Namespace: certAuth.Object.Namespace,
 						},
 						Enrollid:     c.peerOpts.EnrollID,
 						Enrollsecret: c.peerOpts.EnrollPW,
 					},
 					TLS: v1alpha1.TLS{
-						Cahost: fmt.Sprintf("%s.%s", certAuth.Object.Name, certAuth.Object.Namespace),
-						Caname: certAuth.Spec.TLSCA.Name,
-						Caport: 7054,
-						Catls: v1alpha1.Catls{
-							Cacert: base64.StdEncoding.EncodeToString([]byte(certAuth.Status.TlsCert)),
+						CARef: &v1alpha1.CARef{
+							Name:      certAuth.Object.Name,
+							Namespace: certAuth.Object.Namespace,
 						},
 						Csr: v1alpha1.Csr{
 							Hosts: csrHosts,
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"github.com/kfsoftware/hlf-operator/controllers/ordnode"
 	"github.com/kfsoftware/hlf-operator/controllers/utils"
+	"github.com/kfsoftware/hlf-operator/controllers/webhook"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/rest"
 	"os"
@@ -56,10 +57,13 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var enableWebhooks bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8090", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the admission webhooks, the serving certificates must be mounted in the webhook server cert dir.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
@@ -153,6 +157,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		mgr.GetWebhookServer().Register(webhook.DeprecationPath, &webhook.DeprecationHandler{})
+	}
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {